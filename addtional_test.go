@@ -2,9 +2,17 @@ package flagsfiller_test
 
 import (
 	"flag"
+	"fmt"
+	"io"
 	"log/slog"
+	"math/big"
 	"net"
 	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -38,6 +46,43 @@ func TestTime(t *testing.T) {
 	assert.Equal(t, expeted, config.T)
 }
 
+func TestTimeRelativeDefaults(t *testing.T) {
+	type Config struct {
+		Now        time.Time `default:"now"`
+		Since      time.Time `default:"now-24h"`
+		Until      time.Time `default:"now+1h30m"`
+		StartOfDay time.Time `default:"startofday"`
+	}
+
+	var config Config
+
+	before := time.Now()
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	require.NoError(t, err)
+	after := time.Now()
+
+	assert.WithinRange(t, config.Now, before, after)
+	assert.WithinRange(t, config.Since, before.Add(-24*time.Hour), after.Add(-24*time.Hour))
+	assert.WithinRange(t, config.Until, before.Add(90*time.Minute), after.Add(90*time.Minute))
+	assert.Equal(t, time.Date(before.Year(), before.Month(), before.Day(), 0, 0, 0, 0, time.Local), config.StartOfDay)
+
+	err = flagset.Parse([]string{"--since", "startofday-1h"})
+	require.NoError(t, err)
+	assert.Equal(t, config.StartOfDay.Add(-time.Hour), config.Since)
+}
+
+func TestTimeRelativeInvalidOffset(t *testing.T) {
+	type Config struct {
+		Since time.Time `default:"now-1day"`
+	}
+
+	var config Config
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	assert.Error(t, err)
+}
+
 func TestNetIP(t *testing.T) {
 	type Config struct {
 		Addr net.IP
@@ -95,6 +140,292 @@ func TestIPNet(t *testing.T) {
 	assert.Equal(t, *expected, config.Prefix)
 }
 
+func TestURL(t *testing.T) {
+	type Config struct {
+		Endpoint url.URL  `default:"https://example.com/api" schemes:"http,https"`
+		Proxy    *url.URL `schemes:"socks5"`
+		Docs     url.URL
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/api", config.Endpoint.String())
+	assert.Equal(t, "https://example.com/api", flagset.Lookup("endpoint").DefValue)
+	assert.Equal(t, "", flagset.Lookup("docs").DefValue)
+
+	err = flagset.Parse([]string{"-endpoint", "HTTP://localhost:8080/v1", "-proxy", "socks5://proxy:1080"})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:8080", config.Endpoint.Host)
+	assert.Equal(t, "/v1", config.Endpoint.Path)
+	require.NotNil(t, config.Proxy)
+	assert.Equal(t, "socks5://proxy:1080", config.Proxy.String())
+
+	flagset.Init("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err = flagset.Parse([]string{"-endpoint", "ftp://example.com"})
+	assert.ErrorContains(t, err, "ftp://example.com does not have one of the schemes http,https")
+}
+
+func TestURLInvalidDefault(t *testing.T) {
+	type Config struct {
+		Endpoint url.URL `default:"file:///tmp" schemes:"http,https"`
+	}
+
+	var config Config
+	err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	assert.ErrorContains(t, err, "failed to parse default into url.URL")
+}
+
+func TestBigNumbers(t *testing.T) {
+	type Config struct {
+		Amount    big.Int   `default:"1000000000000000000000"`
+		Mask      *big.Int  `default:"0xffffffffffffffffffff"`
+		Tolerance big.Float `default:"1e-30"`
+		Scale     *big.Float
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "1000000000000000000000", config.Amount.String())
+	assert.Equal(t, "ffffffffffffffffffff", config.Mask.Text(16))
+	assert.Equal(t, "1e-30", config.Tolerance.Text('g', -1))
+	assert.Equal(t, "1000000000000000000000", flagset.Lookup("amount").DefValue)
+	assert.Equal(t, "", flagset.Lookup("scale").DefValue)
+
+	err = flagset.Parse([]string{"-amount", "0x10", "-scale", "0x1p-2", "-tolerance", "2.5"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(16), config.Amount.Int64())
+	require.NotNil(t, config.Scale)
+	assert.Equal(t, "0.25", config.Scale.Text('g', -1))
+	assert.Equal(t, "2.5", config.Tolerance.Text('g', -1))
+
+	flagset.Init("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err = flagset.Parse([]string{"-amount", "1.5"})
+	assert.ErrorContains(t, err, "1.5 is not a valid integer")
+}
+
+func TestFileMode(t *testing.T) {
+	type Config struct {
+		Mode    os.FileMode `default:"0644"`
+		DirMode os.FileMode `default:"0o750"`
+		Umask   os.FileMode
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), config.Mode)
+	assert.Equal(t, os.FileMode(0750), config.DirMode)
+	assert.Equal(t, "0644", flagset.Lookup("mode").DefValue)
+	assert.Equal(t, "", flagset.Lookup("umask").DefValue)
+
+	err = flagset.Parse([]string{"-mode", "600", "-umask", "022"})
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), config.Mode)
+	assert.Equal(t, os.FileMode(022), config.Umask)
+
+	flagset.Init("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err = flagset.Parse([]string{"-mode", "0689"})
+	assert.ErrorContains(t, err, "0689 is not a valid octal file mode")
+
+	err = flagset.Parse([]string{"-mode", "10000"})
+	assert.ErrorContains(t, err, "10000 is not a valid octal file mode")
+}
+
+func TestFileModeSpecialBits(t *testing.T) {
+	type Config struct {
+		Mode    os.FileMode `default:"4755"`
+		DirMode os.FileMode `default:"03770"`
+	}
+
+	var config Config
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, os.ModeSetuid|0755, config.Mode)
+	assert.Equal(t, "urwxr-xr-x", config.Mode.String())
+	assert.Equal(t, os.ModeSetgid|os.ModeSticky|0770, config.DirMode)
+	assert.Equal(t, "04755", flagset.Lookup("mode").DefValue)
+	assert.Equal(t, "03770", flagset.Lookup("dir-mode").DefValue)
+}
+
+func TestBytesEncoding(t *testing.T) {
+	type Config struct {
+		Key  []byte `encoding:"hex" default:"deadbeef"`
+		Seed []byte `encoding:"base64"`
+		Raw  []byte
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, config.Key)
+	assert.Equal(t, "deadbeef", flagset.Lookup("key").DefValue)
+	assert.Equal(t, "", flagset.Lookup("seed").DefValue)
+	// without an encoding tag, []byte fields are ignored as before
+	assert.Nil(t, flagset.Lookup("raw"))
+
+	err = flagset.Parse([]string{"-key", "0102", "-seed", "aGVsbG8="})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, config.Key)
+	assert.Equal(t, []byte("hello"), config.Seed)
+
+	flagset.Init("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err = flagset.Parse([]string{"-seed", "not base64"})
+	assert.ErrorContains(t, err, "invalid base64")
+	err = flagset.Parse([]string{"-key", "xyz"})
+	assert.ErrorContains(t, err, "invalid hex")
+}
+
+func TestBytesEncodingInvalidDefault(t *testing.T) {
+	type Config struct {
+		Key []byte `encoding:"hex" default:"xyz"`
+	}
+
+	var config Config
+	err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	assert.ErrorContains(t, err, "failed to parse default into []byte: invalid hex")
+}
+
+// hostPort implements flag.Value on its pointer
+type hostPort struct {
+	Host string
+	Port int
+}
+
+func (h *hostPort) String() string {
+	if h.Host == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", h.Host, h.Port)
+}
+
+func (h *hostPort) Set(s string) error {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return err
+	}
+	h.Host = host
+	_, err = fmt.Sscan(port, &h.Port)
+	return err
+}
+
+// tagList implements flag.Value on a non-struct type, where each Set appends
+type tagList []string
+
+func (l *tagList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *tagList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func TestFlagValueField(t *testing.T) {
+	type Config struct {
+		Server hostPort `default:"localhost:8080" usage:"server address"`
+		Proxy  *hostPort
+		Tags   tagList
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, hostPort{Host: "localhost", Port: 8080}, config.Server)
+	assert.Equal(t, "localhost:8080", flagset.Lookup("server").DefValue)
+
+	err = flagset.Parse([]string{"-server", "example.com:443", "-proxy", "proxy:3128", "-tags", "a", "-tags", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, hostPort{Host: "example.com", Port: 443}, config.Server)
+	require.NotNil(t, config.Proxy)
+	assert.Equal(t, hostPort{Host: "proxy", Port: 3128}, *config.Proxy)
+	assert.Equal(t, tagList{"a", "b"}, config.Tags)
+	// the field is declared directly as the flag's value
+	assert.Same(t, &config.Server, flagset.Lookup("server").Value)
+}
+
+func TestFlagValueFieldInvalidDefault(t *testing.T) {
+	type Config struct {
+		Server hostPort `default:"localhost"`
+	}
+
+	var config Config
+	err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	assert.ErrorContains(t, err, "failed to parse default value into *flagsfiller_test.hostPort")
+}
+
+// sharedCounter shares its count with its copies, so it is updated in place by counterValue
+type sharedCounter struct {
+	count *int
+}
+
+type counterValue struct {
+	counter *sharedCounter
+}
+
+func (v *counterValue) String() string {
+	if v.counter == nil || v.counter.count == nil {
+		return ""
+	}
+	return fmt.Sprint(*v.counter.count)
+}
+
+func (v *counterValue) Set(s string) error {
+	_, err := fmt.Sscan(s, v.counter.count)
+	return err
+}
+
+func TestRegisterFlagValue(t *testing.T) {
+	flagsfiller.RegisterFlagValue(func(field *sharedCounter) flag.Value {
+		if field.count == nil {
+			field.count = new(int)
+		}
+		return &counterValue{counter: field}
+	})
+
+	type Config struct {
+		Requests sharedCounter `default:"3"`
+		Retries  sharedCounter
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+	assert.Equal(t, "3", flagset.Lookup("requests").DefValue)
+	assert.Equal(t, "0", flagset.Lookup("retries").DefValue)
+
+	copied := config.Requests
+	require.NoError(t, flagset.Parse([]string{"-requests", "5"}))
+	assert.Equal(t, 5, *copied.count)
+}
+
 func TestTextUnmarshalerType(t *testing.T) {
 	type Config struct {
 		Addr netip.Addr `default:"9.9.9.9"`
@@ -119,6 +450,96 @@ func TestTextUnmarshalerType(t *testing.T) {
 	assert.Equal(t, netip.AddrFrom4([4]byte{1, 2, 3, 4}), config.Addr)
 }
 
+func TestTextUnmarshalerTypeMultipleFields(t *testing.T) {
+	type Config struct {
+		Primary   netip.Addr `default:"9.9.9.9"`
+		Secondary netip.Addr
+		Nested    struct {
+			Addr netip.Addr
+		}
+	}
+
+	var first, second Config
+	firstFlags := flag.NewFlagSet("first", flag.ContinueOnError)
+	secondFlags := flag.NewFlagSet("second", flag.ContinueOnError)
+	require.NoError(t, flagsfiller.New().Fill(firstFlags, &first))
+	require.NoError(t, flagsfiller.New().Fill(secondFlags, &second))
+
+	err := firstFlags.Parse([]string{"-secondary", "1.2.3.4", "-nested-addr", "5.6.7.8"})
+	require.NoError(t, err)
+	err = secondFlags.Parse([]string{"-primary", "8.8.8.8"})
+	require.NoError(t, err)
+
+	assert.Equal(t, netip.MustParseAddr("9.9.9.9"), first.Primary)
+	assert.Equal(t, netip.MustParseAddr("1.2.3.4"), first.Secondary)
+	assert.Equal(t, netip.MustParseAddr("5.6.7.8"), first.Nested.Addr)
+	assert.Equal(t, netip.MustParseAddr("8.8.8.8"), second.Primary)
+	assert.False(t, second.Secondary.IsValid())
+	assert.Equal(t, "1.2.3.4", firstFlags.Lookup("secondary").Value.String())
+	// rendered by MarshalText, which is empty for the zero netip.Addr
+	assert.Equal(t, "", secondFlags.Lookup("secondary").Value.String())
+}
+
+// version implements encoding.TextMarshaler, but not fmt.Stringer
+type version struct {
+	Major, Minor int
+}
+
+func (v version) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("v%d.%d", v.Major, v.Minor)), nil
+}
+
+func (v *version) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "v%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+func TestTextUnmarshalerTypeDefaultDisplay(t *testing.T) {
+	type Config struct {
+		Addr    netip.Addr `default:"9.9.9.9"`
+		Level   slog.Level `default:"warn"`
+		Version version    `default:"v1.2"`
+		Unset   netip.Addr
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+	var buf strings.Builder
+	flagset.SetOutput(&buf)
+	flagset.PrintDefaults()
+	assert.Equal(t, `  -addr value
+    	 (default 9.9.9.9)
+  -level value
+    	 (default WARN)
+  -unset value
+    	
+  -version value
+    	 (default v1.2)
+`, buf.String())
+}
+
+func TestTextUnmarshalerTypeConcurrentFill(t *testing.T) {
+	type Config struct {
+		Addr netip.Addr `default:"9.9.9.9"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var config Config
+			flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+			assert.NoError(t, flagsfiller.New().Fill(flagset, &config))
+			assert.NoError(t, flagset.Parse([]string{"-addr", "1.2.3.4"}))
+			assert.Equal(t, netip.MustParseAddr("1.2.3.4"), config.Addr)
+		}()
+	}
+	wg.Wait()
+}
+
 func TestSlogLevels(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -174,3 +595,55 @@ func TestSlogLevelWithDefault(t *testing.T) {
 
 	assert.Equal(t, slog.LevelInfo, args.Level)
 }
+
+type lateRegisteredType string
+
+func TestRegisterSimpleTypeAfterFill(t *testing.T) {
+	type Config struct {
+		Name lateRegisteredType `default:"first"`
+	}
+
+	var before Config
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &before)
+	require.NoError(t, err)
+	assert.Equal(t, lateRegisteredType("first"), before.Name)
+
+	flagsfiller.RegisterSimpleType(func(s string, _ reflect.StructTag) (lateRegisteredType, error) {
+		return lateRegisteredType(strings.ToUpper(s)), nil
+	})
+
+	var after Config
+	flagset = flag.FlagSet{}
+	err = flagsfiller.New().Fill(&flagset, &after)
+	require.NoError(t, err)
+	assert.Equal(t, lateRegisteredType("FIRST"), after.Name)
+}
+
+func BenchmarkFillLargeStruct(b *testing.B) {
+	leaves := make([]reflect.StructField, 0, 40)
+	for i := 0; i < 10; i++ {
+		leaves = append(leaves,
+			reflect.StructField{Name: fmt.Sprintf("Host%d", i), Type: reflect.TypeOf(""), Tag: `default:"localhost"`},
+			reflect.StructField{Name: fmt.Sprintf("Timeout%d", i), Type: reflect.TypeOf(time.Duration(0)), Tag: `default:"5s"`},
+			reflect.StructField{Name: fmt.Sprintf("Addr%d", i), Type: reflect.TypeOf(net.IP{})},
+			reflect.StructField{Name: fmt.Sprintf("Level%d", i), Type: reflect.TypeOf(slog.Level(0))},
+		)
+	}
+	nested := reflect.StructOf(leaves)
+	sections := make([]reflect.StructField, 0, 10)
+	for i := 0; i < 10; i++ {
+		sections = append(sections, reflect.StructField{Name: fmt.Sprintf("Section%d", i), Type: nested})
+	}
+	configType := reflect.StructOf(sections)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		config := reflect.New(configType).Interface()
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, config)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
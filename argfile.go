@@ -0,0 +1,62 @@
+package flagsfiller
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandArgFiles replaces each argument of the form @path with the arguments read from the file at
+// path, one per line, where blank lines and lines starting with # are skipped. Arguments read from
+// a file can refer to other files. The arguments after a "--" terminator are not expanded.
+func expandArgFiles(args []string) ([]string, error) {
+	return expandArgFilesFrom(args, nil)
+}
+
+func expandArgFilesFrom(args []string, reading []string) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(expanded, args[i:]...), nil
+		}
+		if len(arg) < 2 || arg[0] != '@' {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		path := arg[1:]
+		for _, r := range reading {
+			if r == path {
+				return nil, fmt.Errorf("argument file %s includes itself", path)
+			}
+		}
+		fileArgs, err := readArgFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileArgs, err = expandArgFilesFrom(fileArgs, append(reading, path))
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, fileArgs...)
+	}
+	return expanded, nil
+}
+
+// readArgFile reads the arguments of an argument file, one per line
+func readArgFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read argument file: %w", err)
+	}
+
+	var args []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args = append(args, line)
+	}
+	return args, nil
+}
@@ -0,0 +1,66 @@
+package flagsfiller_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgFiles(t *testing.T) {
+	type Config struct {
+		Host    string
+		Include []string
+		Verbose bool
+	}
+
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "nested.txt")
+	require.NoError(t, os.WriteFile(nested, []byte("--include\nc\n"), 0644))
+	args := filepath.Join(dir, "args.txt")
+	require.NoError(t, os.WriteFile(args, []byte(`# generated by the build
+--host
+  example.com
+
+--include
+a b
+@`+nested+`
+`), 0644))
+
+	t.Run("expanded", func(t *testing.T) {
+		var config Config
+		flagset, err := flagsfiller.ParseArgs(&config, []string{"--verbose", "@" + args, "--", "@literal"},
+			flagsfiller.WithArgFiles())
+		require.NoError(t, err)
+
+		assert.Equal(t, "example.com", config.Host)
+		assert.Equal(t, []string{"a b", "c"}, config.Include)
+		assert.True(t, config.Verbose)
+		assert.Equal(t, []string{"@literal"}, flagset.Args())
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		var config Config
+		flagset, err := flagsfiller.ParseArgs(&config, []string{"@" + args})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"@" + args}, flagset.Args())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"@" + filepath.Join(dir, "missing.txt")},
+			flagsfiller.WithArgFiles())
+		assert.ErrorContains(t, err, "failed to read argument file")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		cycle := filepath.Join(dir, "cycle.txt")
+		require.NoError(t, os.WriteFile(cycle, []byte("@"+cycle), 0644))
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"@" + cycle}, flagsfiller.WithArgFiles())
+		assert.EqualError(t, err, "argument file "+cycle+" includes itself")
+	})
+}
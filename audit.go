@@ -0,0 +1,102 @@
+package flagsfiller
+
+import (
+	"flag"
+	"time"
+)
+
+// Source identifies where a flag's value came from
+type Source string
+
+const (
+	// SourceArgs is for values parsed from command-line arguments
+	SourceArgs Source = "args"
+	// SourceEnv is for values set from environment variables
+	SourceEnv Source = "env"
+	// SourceMap is for values set by SetFromMap, such as when reloading configuration
+	SourceMap Source = "map"
+	// SourceFile is for values set from config files, such as given by WithConfigFile
+	SourceFile Source = "file"
+	// SourceRemote is for values set from remote sources given by WithRemoteSources
+	SourceRemote Source = "remote"
+	// SourceZero is for fields that were left at the zero value of their type
+	SourceZero Source = "zero"
+	// SourceDefault is for values declared by a default tag, or a defaults provider
+	SourceDefault Source = "default"
+	// SourceStruct is for values that were assigned to the struct before filling, such as by a
+	// struct literal or a Defaulter
+	SourceStruct Source = "struct"
+)
+
+// explicit reports if the source set the value rather than leaving it at a default
+func (s Source) explicit() bool {
+	return s != SourceZero && s != SourceDefault && s != SourceStruct
+}
+
+// Sources returns where the current values of the fields filled by this FlagSetFiller came
+// from, keyed by field path, such as "Remote.Auth.Username". This is intended for debugging the
+// precedence of sources, where the flags visited in the given flagSet were set by command-line
+// arguments unless they were set by SetFromMap.
+func (f *FlagSetFiller) Sources(flagSet *flag.FlagSet) map[string]Source {
+	sources := make(map[string]Source, len(f.fieldSources))
+	for path, source := range f.fieldSources {
+		sources[path] = source
+	}
+	flagSet.Visit(func(visited *flag.Flag) {
+		if path, exists := f.flagPaths[visited.Name]; exists && sources[path] != SourceMap {
+			sources[path] = SourceArgs
+		}
+	})
+	return sources
+}
+
+// WasSet reports if the field at the given path, such as "Remote.Auth.Timeout", was explicitly
+// set by a command-line argument, environment variable, config file, remote source, or
+// SetFromMap, rather than left at its default. The flags set by arguments are those visited in the
+// flag sets given to Fill.
+func (f *FlagSetFiller) WasSet(fieldPath string) bool {
+	source, exists := f.fieldSources[fieldPath]
+	if !exists {
+		return false
+	}
+	if source.explicit() {
+		return true
+	}
+	for _, flagSet := range f.flagSets {
+		visited := false
+		flagSet.Visit(func(declared *flag.Flag) {
+			if f.flagPaths[declared.Name] == fieldPath {
+				visited = true
+			}
+		})
+		if visited {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditRecord describes a change of a field's value
+type AuditRecord struct {
+	Time   time.Time
+	Source Source
+	// Field is the path of the field, such as "Remote.Auth.Username"
+	Field string
+	// Old and New are the string forms of the values, which are redacted for fields declared
+	// with `sensitive:"true"`
+	Old string
+	New string
+}
+
+func (v *fieldValue) audit(previous string, current string) {
+	if v.sensitive {
+		previous, current = redacted, redacted
+	}
+	v.filler.options.auditor(AuditRecord{
+		Time:   time.Now(),
+		Source: v.filler.source,
+		Field:  v.path,
+		Old:    previous,
+		New:    current,
+	})
+}
@@ -0,0 +1,140 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuditor(t *testing.T) {
+	type Config struct {
+		Host     string `default:"localhost"`
+		Password string `sensitive:"true"`
+		Port     int    `default:"8080"`
+	}
+
+	t.Setenv("AUDIT_PORT", "9090")
+
+	var records []flagsfiller.AuditRecord
+	var config Config
+	filler := flagsfiller.New(
+		flagsfiller.WithEnv("Audit"),
+		flagsfiller.WithAuditor(func(record flagsfiller.AuditRecord) {
+			assert.False(t, record.Time.IsZero())
+			// cleared for comparison below
+			record.Time = time.Time{}
+			records = append(records, record)
+		}),
+	)
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--host", "example.com"})
+	require.NoError(t, err)
+
+	err = filler.SetFromMap(flagset, map[string]string{"password": "secret", "port": "7070"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []flagsfiller.AuditRecord{
+		{Source: flagsfiller.SourceEnv, Field: "Port", Old: "8080", New: "9090"},
+		{Source: flagsfiller.SourceArgs, Field: "Host", Old: "localhost", New: "example.com"},
+		{Source: flagsfiller.SourceMap, Field: "Password", Old: "*****", New: "*****"},
+		{Source: flagsfiller.SourceMap, Field: "Port", Old: "9090", New: "7070"},
+	}, records)
+}
+
+func TestWithAuditLogger(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithAuditLogger(logger))
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, filler.Fill(flagset, &config))
+	require.NoError(t, flagset.Parse([]string{"--host", "example.com"}))
+
+	assert.Equal(t,
+		"level=INFO msg=\"configuration changed\" source=args field=Host old=\"\" new=example.com\n",
+		buf.String())
+}
+
+func TestSources(t *testing.T) {
+	type Config struct {
+		Host    string `default:"localhost"`
+		Port    int    `default:"8080"`
+		Name    string
+		Debug   bool
+		Timeout time.Duration
+		Remote  struct {
+			Token string
+		}
+	}
+
+	t.Setenv("SOURCES_PORT", "9090")
+
+	config := Config{Name: "app"}
+	filler := flagsfiller.New(flagsfiller.WithEnv("Sources"))
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	err = filler.ParseWithSources(flagset, []string{"--debug", "--remote-token", "abc"})
+	require.NoError(t, err)
+
+	err = filler.SetFromMap(flagset, map[string]string{"remote-token": "def"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]flagsfiller.Source{
+		"Host":         flagsfiller.SourceDefault,
+		"Port":         flagsfiller.SourceEnv,
+		"Name":         flagsfiller.SourceStruct,
+		"Debug":        flagsfiller.SourceArgs,
+		"Timeout":      flagsfiller.SourceZero,
+		"Remote.Token": flagsfiller.SourceMap,
+	}, filler.Sources(flagset))
+}
+
+func TestWasSet(t *testing.T) {
+	type Config struct {
+		Grouping struct {
+			Timeout time.Duration `default:"5s"`
+			Retries int
+		}
+		Host string
+	}
+
+	t.Setenv("WAS_SET_HOST", "example.com")
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("WasSet"))
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--grouping-timeout", "5s"})
+	require.NoError(t, err)
+
+	assert.True(t, filler.WasSet("Grouping.Timeout"))
+	assert.False(t, filler.WasSet("Grouping.Retries"))
+	assert.True(t, filler.WasSet("Host"))
+	assert.False(t, filler.WasSet("Unknown"))
+}
@@ -0,0 +1,86 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+func init() {
+	registerHandler(getTypeName(reflect.TypeOf(big.Int{})), processBig(parseBigInt))
+	registerHandler(getTypeName(reflect.TypeOf(big.Float{})), processBig(parseBigFloat))
+}
+
+type bigNumber interface {
+	big.Int | big.Float
+}
+
+// bigVar is the flag.Value of big.Int and big.Float fields, along with pointers to them
+type bigVar[T bigNumber] struct {
+	val   *T
+	parse func(s string) (*T, error)
+}
+
+func (v *bigVar[T]) String() string {
+	if v.val == nil {
+		return ""
+	}
+	return fmt.Sprint(v.val)
+}
+
+func (v *bigVar[T]) Set(s string) error {
+	parsed, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	*v.val = *parsed
+	return nil
+}
+
+// Get implements flag.Getter
+func (v *bigVar[T]) Get() interface{} {
+	return v.val
+}
+
+// parseBigInt parses s as an integer in decimal, or in the base given by a prefix, such as 0x for
+// hexadecimal
+func parseBigInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid integer", s)
+	}
+	return n, nil
+}
+
+// parseBigFloat parses s as a decimal or hexadecimal floating-point number, such as 1.5e-9 or
+// 0x1p-2, with 64 bits of precision
+func parseBigFloat(s string) (*big.Float, error) {
+	n, _, err := big.ParseFloat(s, 0, 0, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid number: %w", s, err)
+	}
+	return n, nil
+}
+
+// processBig returns the handler of the big number type parsed by parse
+func processBig[T bigNumber](parse func(s string) (*T, error)) handlerFunc {
+	return func(_ reflect.StructTag, fieldRef interface{},
+		hasDefaultTag bool, tagDefault string,
+		flagSet *flag.FlagSet, renamed string,
+		usage string) error {
+		value := &bigVar[T]{val: fieldRef.(*T), parse: parse}
+		if hasDefaultTag {
+			err := value.Set(tagDefault)
+			if err != nil {
+				return fmt.Errorf("failed to parse default into %T: %w", *new(T), err)
+			}
+		}
+		flagSet.Var(value, renamed, usage)
+		if value.String() == "0" {
+			// like the other numeric types, zero defaults are omitted by flag.PrintDefaults
+			flagSet.Lookup(renamed).DefValue = ""
+		}
+		return nil
+	}
+}
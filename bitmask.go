@@ -0,0 +1,142 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// bitmaskInteger are the types that can be registered with RegisterBitmask
+type bitmaskInteger interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// RegisterBitmask registers the integer type T as a set of bit flags that are given by name.
+// Fields of that type accept a comma-separated list of the names, such as --features
+// metrics,tracing, where the corresponding bits are OR'd together. The first value given for a
+// flag replaces its default, or the value of a source such as an environment variable, and
+// repeating the flag adds more bits. Unknown names are rejected,
+// the usage lists the names, and values are rendered as the names of the bits that are set.
+// Like RegisterSimpleType, it should be called in init().
+func RegisterBitmask[T bitmaskInteger](bits map[string]T) {
+	names := make([]string, 0, len(bits))
+	for name := range bits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	registerHandler(getTypeName(reflect.TypeOf(*new(T))), func(tag reflect.StructTag, fieldRef interface{},
+		hasDefaultTag bool, tagDefault string,
+		flagSet *flag.FlagSet, renamed string,
+		usage string) error {
+
+		val := &bitmaskValue[T]{
+			ref:   fieldRef.(*T),
+			bits:  bits,
+			names: names,
+		}
+		if hasDefaultTag {
+			mask, err := val.parse(tagDefault)
+			if err != nil {
+				return fmt.Errorf("failed to parse default into %T: %w", *new(T), err)
+			}
+			*val.ref = mask
+		}
+		usage = appendUsage(usage, fmt.Sprintf("(any of %s)", strings.Join(names, ", ")))
+		flagSet.Var(val, renamed, usage)
+		return nil
+	})
+}
+
+type bitmaskValue[T bitmaskInteger] struct {
+	ref   *T
+	bits  map[string]T
+	names []string
+	// set tracks if a value was given, since the first replaces the default
+	set bool
+}
+
+// endSource lets the next value, such as from the arguments, replace the value set by a source
+func (v *bitmaskValue[T]) endSource() {
+	v.set = false
+}
+
+func (v *bitmaskValue[T]) String() string {
+	if v.ref == nil {
+		return ""
+	}
+	var set []string
+	for _, name := range v.names {
+		bit := v.bits[name]
+		if bit != 0 && *v.ref&bit == bit {
+			set = append(set, name)
+		}
+	}
+	return strings.Join(set, ",")
+}
+
+func (v *bitmaskValue[T]) Set(s string) error {
+	mask, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	if !v.set {
+		*v.ref = 0
+		v.set = true
+	}
+	*v.ref |= mask
+	return nil
+}
+
+func (v *bitmaskValue[T]) parse(s string) (T, error) {
+	var mask T
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		bit, exists := v.bits[name]
+		if !exists {
+			return 0, fmt.Errorf("unknown name %s, must be any of %s", name, strings.Join(v.names, ", "))
+		}
+		mask |= bit
+	}
+	return mask, nil
+}
+
+func (v *bitmaskValue[T]) choices() ([]string, bool) {
+	return v.names, true
+}
+
+func (v *bitmaskValue[T]) Get() interface{} {
+	return *v.ref
+}
+
+// sourcedValue is implemented by the flag values that add to the field with each value given,
+// where the value set by a source, such as an environment variable, is replaced by the next value
+type sourcedValue interface {
+	endSource()
+}
+
+// wrappingValue is implemented by the flag values that wrap the value declared for a field
+type wrappingValue interface {
+	unwrap() flag.Value
+}
+
+// endSource is called after a source has set the given value, so that the next source replaces
+// the value rather than adding to it
+func endSource(value flag.Value) {
+	for {
+		switch v := value.(type) {
+		case sourcedValue:
+			v.endSource()
+			return
+		case wrappingValue:
+			value = v.unwrap()
+		default:
+			return
+		}
+	}
+}
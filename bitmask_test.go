@@ -0,0 +1,91 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bitmaskFeature uint8
+
+const (
+	featureMetrics bitmaskFeature = 1 << iota
+	featureTracing
+	featureProfiling
+)
+
+func init() {
+	flagsfiller.RegisterBitmask(map[string]bitmaskFeature{
+		"metrics":   featureMetrics,
+		"tracing":   featureTracing,
+		"profiling": featureProfiling,
+	})
+}
+
+func TestRegisterBitmask(t *testing.T) {
+	type Config struct {
+		Features bitmaskFeature `default:"metrics" usage:"features to enable"`
+		Repeated bitmaskFeature
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New().Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, featureMetrics, config.Features)
+	assert.Equal(t, `
+  -features value
+    	features to enable (any of metrics, profiling, tracing) (default metrics)
+  -repeated value
+    	(any of metrics, profiling, tracing)
+`, grabUsage(*flagset).String())
+
+	err = flagset.Parse([]string{
+		"--features", "tracing, profiling",
+		"--repeated", "metrics", "--repeated", "tracing",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, featureTracing|featureProfiling, config.Features)
+	assert.Equal(t, featureMetrics|featureTracing, config.Repeated)
+	assert.Equal(t, "profiling,tracing", flagset.Lookup("features").Value.String())
+}
+
+func TestRegisterBitmaskArgsReplaceEnv(t *testing.T) {
+	type Config struct {
+		Features bitmaskFeature `default:"metrics"`
+	}
+
+	t.Setenv("BITMASK_FEATURES", "tracing")
+
+	var config Config
+	_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithEnv("Bitmask"))
+	require.NoError(t, err)
+	assert.Equal(t, featureTracing, config.Features)
+
+	_, err = flagsfiller.ParseArgs(&config, []string{"--features", "profiling", "--features", "metrics"},
+		flagsfiller.WithEnv("Bitmask"))
+	require.NoError(t, err)
+	assert.Equal(t, featureProfiling|featureMetrics, config.Features)
+}
+
+func TestRegisterBitmaskUnknownName(t *testing.T) {
+	type Config struct {
+		Features bitmaskFeature
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+	err := flagset.Parse([]string{"--features", "metrics,logging"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown name logging, must be any of metrics, profiling, tracing")
+	assert.Equal(t, bitmaskFeature(0), config.Features)
+}
@@ -0,0 +1,176 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes that is parsed from and rendered in human units, such as "512KiB",
+// "10MB", or "1G". The units ending in "iB", as well as the single letter units, such as "G", are
+// powers of 1024, and the units ending in "B", such as "MB", are powers of 1000. Int64 and uint64
+// fields can instead be declared with `type:"bytes"`.
+type ByteSize uint64
+
+func init() {
+	registerHandler(getTypeName(reflect.TypeOf(ByteSize(0))), processByteSize)
+}
+
+// byteSizeUnits are the multipliers of the units accepted by ParseByteSize, keyed by the
+// lowercase unit
+var byteSizeUnits = map[string]uint64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "ki": 1 << 10, "kib": 1 << 10, "kb": 1e3,
+	"m": 1 << 20, "mi": 1 << 20, "mib": 1 << 20, "mb": 1e6,
+	"g": 1 << 30, "gi": 1 << 30, "gib": 1 << 30, "gb": 1e9,
+	"t": 1 << 40, "ti": 1 << 40, "tib": 1 << 40, "tb": 1e12,
+	"p": 1 << 50, "pi": 1 << 50, "pib": 1 << 50, "pb": 1e15,
+	"e": 1 << 60, "ei": 1 << 60, "eib": 1 << 60, "eb": 1e18,
+}
+
+// byteSizeFormats are the units used by ByteSize.String, from the largest
+var byteSizeFormats = []struct {
+	unit       string
+	multiplier uint64
+}{
+	{"EiB", 1 << 60}, {"EB", 1e18},
+	{"PiB", 1 << 50}, {"PB", 1e15},
+	{"TiB", 1 << 40}, {"TB", 1e12},
+	{"GiB", 1 << 30}, {"GB", 1e9},
+	{"MiB", 1 << 20}, {"MB", 1e6},
+	{"KiB", 1 << 10}, {"KB", 1e3},
+}
+
+// ParseByteSize parses a number of bytes with an optional unit, such as "512KiB", "10MB", "1G",
+// or "1.5GiB", where the unit is case-insensitive
+func ParseByteSize(s string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(s)
+	end := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end < 0 {
+		end = len(trimmed)
+	}
+	number, unit := trimmed[:end], strings.TrimSpace(trimmed[end:])
+	if number == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	multiplier, known := byteSizeUnits[strings.ToLower(unit)]
+	if !known {
+		return 0, fmt.Errorf("unknown unit %q in byte size %q", unit, s)
+	}
+
+	if !strings.Contains(number, ".") {
+		n, err := strconv.ParseUint(number, 10, 64)
+		if err != nil || n > math.MaxUint64/multiplier {
+			return 0, fmt.Errorf("byte size %q is too large", s)
+		}
+		return ByteSize(n * multiplier), nil
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	bytes := math.Round(f * float64(multiplier))
+	if bytes >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q is too large", s)
+	}
+	return ByteSize(bytes), nil
+}
+
+// String renders the size with the unit that exactly represents it with the smallest number,
+// such as "512KiB" or "10MB", or as a plain number of bytes
+func (b ByteSize) String() string {
+	for _, format := range byteSizeFormats {
+		if b != 0 && uint64(b)%format.multiplier == 0 {
+			return strconv.FormatUint(uint64(b)/format.multiplier, 10) + format.unit
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	parsed, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// byteSizeVar is the flag.Value of ByteSize fields and of integer fields declared with
+// `type:"bytes"`
+type byteSizeVar struct {
+	field reflect.Value
+}
+
+func (v *byteSizeVar) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	switch v.field.Kind() {
+	case reflect.Int, reflect.Int64:
+		return ByteSize(v.field.Int()).String()
+	default:
+		return ByteSize(v.field.Uint()).String()
+	}
+}
+
+func (v *byteSizeVar) Set(s string) error {
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	switch v.field.Kind() {
+	case reflect.Int, reflect.Int64:
+		if v.field.OverflowInt(int64(size)) || int64(size) < 0 {
+			return fmt.Errorf("byte size %q is too large", s)
+		}
+		v.field.SetInt(int64(size))
+	default:
+		v.field.SetUint(uint64(size))
+	}
+	return nil
+}
+
+// Get implements flag.Getter
+func (v *byteSizeVar) Get() interface{} {
+	return v.field.Interface()
+}
+
+// isByteSizeKind reports if fields of the given kind can be declared with `type:"bytes"`
+func isByteSizeKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func processByteSize(_ reflect.StructTag, fieldRef interface{},
+	hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string,
+	usage string) error {
+	value := &byteSizeVar{field: reflect.ValueOf(fieldRef).Elem()}
+	if hasDefaultTag {
+		err := value.Set(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into byte size: %w", err)
+		}
+	}
+	flagSet.Var(value, renamed, usage)
+	if value.field.IsZero() {
+		// like the numeric types, zero defaults are omitted by flag.PrintDefaults
+		flagSet.Lookup(renamed).DefValue = ""
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected flagsfiller.ByteSize
+	}{
+		{"100", 100},
+		{"100B", 100},
+		{"512KiB", 512 << 10},
+		{"512k", 512 << 10},
+		{"10MB", 10_000_000},
+		{"10mb", 10_000_000},
+		{"1G", 1 << 30},
+		{"1.5GiB", 3 << 29},
+		{" 2 TB ", 2_000_000_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			size, err := flagsfiller.ParseByteSize(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+
+	_, err := flagsfiller.ParseByteSize("lots")
+	assert.EqualError(t, err, `invalid byte size "lots"`)
+	_, err = flagsfiller.ParseByteSize("10XB")
+	assert.EqualError(t, err, `unknown unit "XB" in byte size "10XB"`)
+	_, err = flagsfiller.ParseByteSize("16EiB")
+	assert.EqualError(t, err, `byte size "16EiB" is too large`)
+}
+
+func TestByteSizeString(t *testing.T) {
+	assert.Equal(t, "0", flagsfiller.ByteSize(0).String())
+	assert.Equal(t, "100", flagsfiller.ByteSize(100).String())
+	assert.Equal(t, "512KiB", flagsfiller.ByteSize(512<<10).String())
+	assert.Equal(t, "10MB", flagsfiller.ByteSize(10_000_000).String())
+	assert.Equal(t, "1536MiB", flagsfiller.ByteSize(3<<29).String())
+}
+
+func TestByteSizeFields(t *testing.T) {
+	type Config struct {
+		Buffer   flagsfiller.ByteSize `default:"64KiB" usage:"buffer size"`
+		Cache    int64                `type:"bytes" default:"1G"`
+		Limit    uint64               `type:"bytes"`
+		Transfer flagsfiller.ByteSize
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, flagsfiller.ByteSize(64<<10), config.Buffer)
+	assert.Equal(t, int64(1<<30), config.Cache)
+
+	var buf bytes.Buffer
+	flagset.SetOutput(&buf)
+	flagset.PrintDefaults()
+	assert.Equal(t, `  -buffer value
+    	buffer size (default 64KiB)
+  -cache value
+    	 (default 1GiB)
+  -limit value
+    	
+  -transfer value
+    	
+`, buf.String())
+
+	err = flagset.Parse([]string{"--limit", "10MB", "--transfer", "1.5k", "--cache", "2GB"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10_000_000), config.Limit)
+	assert.Equal(t, flagsfiller.ByteSize(1536), config.Transfer)
+	assert.Equal(t, int64(2_000_000_000), config.Cache)
+
+	err = flagset.Parse([]string{"--cache", "9EiB"})
+	assert.Error(t, err)
+}
+
+func TestByteSizeTypeInvalid(t *testing.T) {
+	type Config struct {
+		Name string `type:"bytes"`
+	}
+
+	var config Config
+	err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	assert.ErrorContains(t, err, "bytes type of field Name only applies to int, int64, uint, and uint64 fields")
+}
@@ -0,0 +1,92 @@
+package flagsfiller
+
+import (
+	"reflect"
+)
+
+// Clone returns a deep copy of the given config struct, such as one that was filled, so that a
+// snapshot can be taken before a reload or mutation and later compared or rolled back.
+// Nested structs, pointers, slices, maps, and interface values are copied recursively, while
+// registered types, such as time.Time and net.IP, and unexported fields are copied as values.
+func Clone[T any](from *T) *T {
+	if from == nil {
+		return nil
+	}
+	to := new(T)
+	c := cloner{visited: make(map[uintptr]reflect.Value)}
+	c.copyValue(reflect.ValueOf(to).Elem(), reflect.ValueOf(from).Elem())
+	return to
+}
+
+type cloner struct {
+	// visited retains pointers that were already cloned to preserve sharing and avoid cycles
+	visited map[uintptr]reflect.Value
+}
+
+func (c *cloner) copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		if existing, ok := c.visited[src.Pointer()]; ok {
+			dst.Set(existing)
+			return
+		}
+		ptr := reflect.New(src.Type().Elem())
+		c.visited[src.Pointer()] = ptr
+		c.copyValue(ptr.Elem(), src.Elem())
+		dst.Set(ptr)
+
+	case reflect.Struct:
+		// start with a copy by value to retain unexported fields
+		dst.Set(src)
+		if isLeafStruct(src.Type()) {
+			return
+		}
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				c.copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		slice := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copyValue(slice.Index(i), src.Index(i))
+		}
+		dst.Set(slice)
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copyValue(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			c.copyValue(value, iter.Value())
+			m.SetMapIndex(iter.Key(), value)
+		}
+		dst.Set(m)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		value := reflect.New(src.Elem().Type()).Elem()
+		c.copyValue(value, src.Elem())
+		dst.Set(value)
+
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,74 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	type Nested struct {
+		Host string
+	}
+	type Config struct {
+		Name    string
+		Timeout time.Duration
+		Start   time.Time
+		Addr    net.IP
+		Tags    []string
+		Labels  map[string]string
+		Nested  *Nested
+		Storage StorageBackend
+		hidden  string
+	}
+
+	config := Config{hidden: "hidden"}
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{
+		"--name", "n1",
+		"--timeout", "5s",
+		"--start", "2024-01-02 03:04:05",
+		"--addr", "1.2.3.4",
+		"--tags", "one,two",
+		"--labels", "env=dev",
+		"--nested-host", "h1",
+		"--storage", "local",
+		"--storage-local-path", "/data",
+	})
+	require.NoError(t, err)
+
+	clone := flagsfiller.Clone(&config)
+	assert.Equal(t, config, *clone)
+
+	// mutate the original
+	config.Tags[0] = "changed"
+	config.Labels["env"] = "prod"
+	config.Nested.Host = "h2"
+	config.Addr[0] = 9
+	config.Storage.(*localStorage).Path = "/changed"
+
+	assert.Equal(t, []string{"one", "two"}, clone.Tags)
+	assert.Equal(t, map[string]string{"env": "dev"}, clone.Labels)
+	assert.Equal(t, "h1", clone.Nested.Host)
+	assert.Equal(t, net.ParseIP("1.2.3.4"), clone.Addr)
+	assert.Equal(t, "file:///data", clone.Storage.Location())
+	assert.Equal(t, "hidden", clone.hidden)
+}
+
+func TestCloneNil(t *testing.T) {
+	type Config struct {
+		Name string
+	}
+
+	var config *Config
+	assert.Nil(t, flagsfiller.Clone(config))
+}
@@ -0,0 +1,629 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+)
+
+// Options declares what to generate
+type Options struct {
+	// Dir is the directory of the package containing the type
+	Dir string
+	// TypeName is the name of the struct type to generate a fill function for
+	TypeName string
+	// EnvPrefix enables environment variables like flagsfiller.WithEnv, when not empty
+	EnvPrefix string
+	// NoEnv disables environment variables like flagsfiller.NoSetFromEnv
+	NoEnv bool
+}
+
+type generator struct {
+	options Options
+	// structs are the struct types declared in the package, by name
+	structs map[string]*ast.StructType
+	body    bytes.Buffer
+	// validations are the statements of the validate function, which append to errs
+	validations bytes.Buffer
+	// required are the conditions of the required fields being set, by flag name
+	required []requiredFlag
+	imports  map[string]bool
+	// walking are the structs being walked, to reject recursive pointer fields
+	walking map[*ast.StructType]bool
+}
+
+type requiredFlag struct {
+	name      string
+	condition string
+}
+
+// Generate returns the formatted source of a file declaring a Fill<TypeName> function and a
+// Validate<TypeName> function
+func Generate(options Options) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgName, files, err := parsePackage(fset, options.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		options: options,
+		structs: make(map[string]*ast.StructType),
+		imports: map[string]bool{"flag": true},
+		walking: make(map[*ast.StructType]bool),
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if spec, ok := node.(*ast.TypeSpec); ok {
+				if structType, ok := spec.Type.(*ast.StructType); ok {
+					g.structs[spec.Name.Name] = structType
+				}
+			}
+			return true
+		})
+	}
+
+	structType, exists := g.structs[options.TypeName]
+	if !exists {
+		return nil, fmt.Errorf("struct type %s not found in %s", options.TypeName, options.Dir)
+	}
+	err = g.walkFields(structType, "", "", "c.", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return g.render(pkgName)
+}
+
+func parsePackage(fset *token.FileSet, dir string) (string, []*ast.File, error) {
+	pkgs, err := parser.ParseDir(fset, dir, func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected one package in %s, but found %d", dir, len(pkgs))
+	}
+	for name, pkg := range pkgs {
+		var files []*ast.File
+		filenames := make([]string, 0, len(pkg.Files))
+		for filename := range pkg.Files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			files = append(files, pkg.Files[filename])
+		}
+		return name, files, nil
+	}
+	return "", nil, nil
+}
+
+func (g *generator) render(pkgName string) ([]byte, error) {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by flagsfiller-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkgName)
+
+	if g.validations.Len() > 0 || len(g.required) > 0 {
+		g.imports["errors"] = true
+		g.imports["fmt"] = true
+	}
+	if len(g.required) > 0 {
+		g.imports["strings"] = true
+	}
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	out.WriteString("import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+
+	fmt.Fprintf(&out, "// Fill%s declares a flag in flagSet for each field of c\n", g.options.TypeName)
+	fmt.Fprintf(&out, "func Fill%s(flagSet *flag.FlagSet, c *%s) error {\n",
+		g.options.TypeName, g.options.TypeName)
+	out.Write(g.body.Bytes())
+	out.WriteString("return nil\n}\n\n")
+
+	fmt.Fprintf(&out, "// Validate%s checks the constraints declared by the tags of the fields of c after parsing,\n",
+		g.options.TypeName)
+	out.WriteString("// like flagsfiller.FlagSetFiller.Validate and the min, max, and choices tags do\n")
+	fmt.Fprintf(&out, "func Validate%s(flagSet *flag.FlagSet, c *%s) error {\n",
+		g.options.TypeName, g.options.TypeName)
+	if g.validations.Len() == 0 && len(g.required) == 0 {
+		out.WriteString("return nil\n}\n")
+	} else {
+		out.WriteString("set := make(map[string]bool)\n")
+		out.WriteString("flagSet.Visit(func(f *flag.Flag) {\nset[f.Name] = true\n})\n")
+		out.WriteString("var errs []error\n")
+		if len(g.required) > 0 {
+			out.WriteString("var missing []string\n")
+			for _, required := range g.required {
+				condition := required.condition
+				if strings.Contains(condition, "||") {
+					condition = "(" + condition + ")"
+				}
+				fmt.Fprintf(&out, "if !%s {\nmissing = append(missing, %q)\n}\n", condition, "-"+required.name)
+			}
+			out.WriteString("if len(missing) > 0 {\n")
+			out.WriteString("errs = append(errs, fmt.Errorf(\"required flags are not set: %s\", strings.Join(missing, \", \")))\n}\n")
+		}
+		out.Write(g.validations.Bytes())
+		out.WriteString("return errors.Join(errs...)\n}\n")
+	}
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// walkFields emits the declarations of the fields of the given struct where prefix and envBase
+// are the hyphen-separated field names, as used by flagsfiller, and ref is the Go expression
+// of the struct, ending with a dot
+func (g *generator) walkFields(structType *ast.StructType, prefix string, envBase string, ref string, path string) error {
+	if prefix != "" {
+		prefix += "-"
+	}
+	if envBase != "" {
+		envBase += "-"
+	}
+	if path != "" {
+		path += "."
+	}
+	g.walking[structType] = true
+	defer delete(g.walking, structType)
+
+	for _, field := range structType.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return fmt.Errorf("invalid tag on %s: %w", field.Names, err)
+			}
+			tag = reflect.StructTag(unquoted)
+		}
+		if flagTag, ok := tag.Lookup("flag"); ok && flagTag == "" {
+			continue
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			// like reflect, an embedded field is named by its type
+			names = []*ast.Ident{ast.NewIdent(embeddedName(field.Type))}
+		}
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+			name := ident.Name
+			if unsupported := unsupportedTag(tag); unsupported != "" {
+				return fmt.Errorf("failed to process %s: %s tag is unsupported by flagsfiller-gen",
+					path+name, unsupported)
+			}
+			nestedEnvBase := envBase + name
+			if override, exists := tag.Lookup("envPrefix"); exists {
+				nestedEnvBase = envBase + override
+			}
+
+			nested := nestedStruct(field.Type, g.structs)
+			if nested != nil {
+				err := g.walkFields(nested, prefix+name, nestedEnvBase, ref+name+".", path+name)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if pointer, ok := field.Type.(*ast.StarExpr); ok {
+				if elemName, ok := pointer.X.(*ast.Ident); ok && g.structs[elemName.Name] != nil {
+					nested = g.structs[elemName.Name]
+					if g.walking[nested] {
+						return fmt.Errorf("failed to process %s: recursive struct type %s", path+name, elemName.Name)
+					}
+					// like flagsfiller, a nil pointer is set to a new struct and the flags of the
+					// struct's fields are prefixed by the pointer field's name alone
+					g.separate()
+					fmt.Fprintf(&g.body, "// %s\nif %s == nil {\n%s = new(%s)\n}\n",
+						path+name, ref+name, ref+name, elemName.Name)
+					err := g.walkFields(nested, name, nestedEnvBase, ref+name+".", path+name)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+			}
+
+			err := g.processField(field.Type, tag, prefix+name, envBase+name, ref+name, path+name)
+			if err != nil {
+				return fmt.Errorf("failed to process %s: %w", path+name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// unsupportedTags are the tags processed by flagsfiller that the generated code doesn't
+// implement, which would otherwise make it behave differently than Fill
+var unsupportedTags = []string{
+	"advanced", "command", "complete", "convert", "converter", "default-if", "deprecated",
+	"deprecated-aliases", "enables", "encoding", "envfile", "keep-empty", "layout", "max-occurs",
+	"requires", "schemes", "sensitive", "trim", "type", "validate",
+}
+
+// unsupportedTag returns the first of the unsupportedTags declared by tag, if any
+func unsupportedTag(tag reflect.StructTag) string {
+	for _, name := range unsupportedTags {
+		if _, exists := tag.Lookup(name); exists {
+			return name
+		}
+	}
+	return ""
+}
+
+// embeddedName returns the name of an embedded field of the given type
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	}
+	return ""
+}
+
+func nestedStruct(expr ast.Expr, structs map[string]*ast.StructType) *ast.StructType {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t
+	case *ast.Ident:
+		return structs[t.Name]
+	}
+	return nil
+}
+
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return typeString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + typeString(t.Elt)
+		}
+	case *ast.StarExpr:
+		return "*" + typeString(t.X)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func (g *generator) processField(expr ast.Expr, tag reflect.StructTag, name string, envBase string, ref string, path string) error {
+	flagName, exists := tag.Lookup("flag")
+	if !exists {
+		flagName = flagsfiller.KebabRenamer()(name)
+	}
+	var names []string
+	names = append(names, flagName)
+	if aliases := tag.Get("aliases"); aliases != "" {
+		names = append(names, strings.Split(aliases, ",")...)
+	}
+
+	envNames := g.envNames(tag, envBase)
+	usage := requoteUsage(tag.Get("usage"))
+	if len(envNames) > 0 {
+		usage = fmt.Sprintf("%s (env %s)", usage, strings.Join(envNames, ", "))
+	}
+	required, _ := strconv.ParseBool(tag.Get("required"))
+	if required {
+		usage += " (required)"
+		g.required = append(g.required, requiredFlag{name: flagName, condition: setCondition(names)})
+	}
+	tagDefault, hasDefault := tag.Lookup("default")
+	typeName := typeString(expr)
+	err := g.declareConstraints(typeName, tag, names, hasDefault, tagDefault, ref)
+	if err != nil {
+		return err
+	}
+	if choices, exists := tag.Lookup("choices"); exists {
+		usage = fmt.Sprintf("%s (one of %s)", usage, strings.Join(strings.Split(choices, ","), ", "))
+	}
+
+	g.separate()
+	fmt.Fprintf(&g.body, "// %s\n", path)
+	switch typeName {
+	case "string":
+		defaultExpr := ref
+		if hasDefault {
+			defaultExpr = strconv.Quote(tagDefault)
+		}
+		g.declareVars("StringVar", ref, names, defaultExpr, usage)
+
+	case "bool":
+		defaultExpr := ref
+		if hasDefault {
+			value, err := strconv.ParseBool(tagDefault)
+			if err != nil {
+				return fmt.Errorf("invalid default: %w", err)
+			}
+			defaultExpr = strconv.FormatBool(value)
+		}
+		g.declareVars("BoolVar", ref, names, defaultExpr, usage)
+
+	case "int", "int64", "uint", "uint64", "float64":
+		defaultExpr := ref
+		if hasDefault {
+			literal, err := numericLiteral(typeName, tagDefault)
+			if err != nil {
+				return fmt.Errorf("invalid default: %w", err)
+			}
+			defaultExpr = literal
+		}
+		method := strings.ToUpper(typeName[:1]) + typeName[1:] + "Var"
+		g.declareVars(method, ref, names, defaultExpr, usage)
+
+	case "time.Duration":
+		defaultExpr := ref
+		if hasDefault {
+			value, err := time.ParseDuration(tagDefault)
+			if err != nil {
+				return fmt.Errorf("invalid default: %w", err)
+			}
+			defaultExpr = fmt.Sprintf("time.Duration(%d) /* %s */", int64(value), value)
+			g.imports["time"] = true
+		}
+		g.declareVars("DurationVar", ref, names, defaultExpr, usage)
+
+	case "[]string":
+		override, _ := strconv.ParseBool(tag.Get("override-value"))
+		g.declareStringSlice(ref, names, hasDefault, tagDefault, override, usage)
+
+	default:
+		return fmt.Errorf("unsupported type %s", typeName)
+	}
+
+	if len(envNames) > 0 {
+		g.declareEnv(flagName, envNames)
+	}
+	return nil
+}
+
+// separate starts the declarations of a field with a blank line, unless they are the first
+func (g *generator) separate() {
+	if g.body.Len() > 0 {
+		g.body.WriteString("\n")
+	}
+}
+
+// setCondition returns the Go expression of the validate function checking that any of the
+// given flag names was set
+func setCondition(names []string) string {
+	conditions := make([]string, len(names))
+	for i, name := range names {
+		conditions[i] = fmt.Sprintf("set[%q]", name)
+	}
+	return strings.Join(conditions, " || ")
+}
+
+// declareConstraints emits the checks of the min, max, and choices tags to the validate function
+// and checks the default. Like flagsfiller, a zero value that was not set is not checked.
+func (g *generator) declareConstraints(typeName string, tag reflect.StructTag, names []string,
+	hasDefault bool, tagDefault string, ref string) error {
+	minTag, hasMin := tag.Lookup("min")
+	maxTag, hasMax := tag.Lookup("max")
+	choices, hasChoices := tag.Lookup("choices")
+	if !hasMin && !hasMax && !hasChoices {
+		return nil
+	}
+	checked := fmt.Sprintf("%s || %s != %s", setCondition(names), ref, zeroLiteral(typeName))
+
+	if hasChoices {
+		if typeName != "string" {
+			return fmt.Errorf("choices tag only applies to string fields")
+		}
+		allowed := strings.Split(choices, ",")
+		message := "must be one of " + strings.Join(allowed, ", ")
+		if hasDefault && !contains(allowed, tagDefault) {
+			return fmt.Errorf("default is invalid: %s", message)
+		}
+		quoted := make([]string, len(allowed))
+		for i, choice := range allowed {
+			quoted[i] = strconv.Quote(choice)
+		}
+		fmt.Fprintf(&g.validations, "if %s {\nswitch %s {\ncase %s:\ndefault:\n", checked, ref, strings.Join(quoted, ", "))
+		g.declareViolation(names[0], ref, message)
+		g.validations.WriteString("}\n}\n")
+	}
+
+	if !hasMin && !hasMax {
+		return nil
+	}
+	var parse func(s string) (string, float64, error)
+	switch typeName {
+	case "int", "int64", "uint", "uint64", "float64":
+		parse = func(s string) (string, float64, error) {
+			literal, err := numericLiteral(typeName, s)
+			if err != nil {
+				return "", 0, err
+			}
+			value, err := strconv.ParseFloat(literal, 64)
+			return literal, value, err
+		}
+	case "time.Duration":
+		parse = func(s string) (string, float64, error) {
+			value, err := time.ParseDuration(s)
+			return fmt.Sprintf("time.Duration(%d)", int64(value)), float64(value), err
+		}
+	default:
+		return fmt.Errorf("min and max tags only apply to numeric fields")
+	}
+	var defaultValue float64
+	if hasDefault {
+		var err error
+		_, defaultValue, err = parse(tagDefault)
+		if err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	for _, bound := range []struct {
+		exists   bool
+		tag      string
+		name     string
+		operator string
+		message  string
+	}{
+		{exists: hasMin, tag: minTag, name: "min", operator: "<", message: "must be at least "},
+		{exists: hasMax, tag: maxTag, name: "max", operator: ">", message: "must be at most "},
+	} {
+		if !bound.exists {
+			continue
+		}
+		literal, value, err := parse(bound.tag)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q", bound.name, bound.tag)
+		}
+		if hasDefault && ((bound.operator == "<" && defaultValue < value) || (bound.operator == ">" && defaultValue > value)) {
+			return fmt.Errorf("default is invalid: %s%s", bound.message, bound.tag)
+		}
+		if typeName == "time.Duration" {
+			g.imports["time"] = true
+		}
+		fmt.Fprintf(&g.validations, "if (%s) && %s %s %s {\n", checked, ref, bound.operator, literal)
+		g.declareViolation(names[0], ref, bound.message+bound.tag)
+		g.validations.WriteString("}\n")
+	}
+	return nil
+}
+
+// declareViolation emits the error of a value rejected by a constraint, which is worded like the
+// error of flagsfiller when the value is parsed
+func (g *generator) declareViolation(flagName string, ref string, message string) {
+	fmt.Fprintf(&g.validations, "errs = append(errs, fmt.Errorf(\"invalid value \\\"%%v\\\" for flag -%s: %s\", %s))\n",
+		flagName, strings.ReplaceAll(message, "%", "%%"), ref)
+}
+
+func zeroLiteral(typeName string) string {
+	if typeName == "string" {
+		return `""`
+	}
+	return "0"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func numericLiteral(typeName string, s string) (string, error) {
+	switch typeName {
+	case "int", "int64":
+		value, err := strconv.ParseInt(s, 10, 64)
+		return strconv.FormatInt(value, 10), err
+	case "uint", "uint64":
+		value, err := strconv.ParseUint(s, 10, 64)
+		return strconv.FormatUint(value, 10), err
+	default:
+		value, err := strconv.ParseFloat(s, 64)
+		return strconv.FormatFloat(value, 'g', -1, 64), err
+	}
+}
+
+func (g *generator) envNames(tag reflect.StructTag, envBase string) []string {
+	if g.options.NoEnv {
+		return nil
+	}
+	if override, exists := tag.Lookup("env"); exists {
+		if override == "" {
+			return nil
+		}
+		return strings.Split(override, ",")
+	}
+	if g.options.EnvPrefix == "" {
+		return nil
+	}
+	return []string{flagsfiller.ScreamingSnakeRenamer()(g.options.EnvPrefix + envBase)}
+}
+
+func (g *generator) declareVars(method string, ref string, names []string, defaultExpr string, usage string) {
+	// the default is captured first since it may refer to the field's current value
+	if len(names) > 1 {
+		fmt.Fprintf(&g.body, "{\ndefaultValue := %s\n", defaultExpr)
+		defaultExpr = "defaultValue"
+	}
+	for _, name := range names {
+		fmt.Fprintf(&g.body, "flagSet.%s(&%s, %q, %s, %q)\n", method, ref, name, defaultExpr, usage)
+	}
+	if len(names) > 1 {
+		g.body.WriteString("}\n")
+	}
+}
+
+func (g *generator) declareStringSlice(ref string, names []string, hasDefault bool, tagDefault string, override bool, usage string) {
+	g.imports["strings"] = true
+	if hasDefault {
+		fmt.Fprintf(&g.body, "%s = %#v\n", ref, splitValues(tagDefault))
+	}
+	for _, name := range names {
+		fmt.Fprintf(&g.body, "flagSet.Func(%q, %q, func(s string) error {\n", name, usage)
+		fmt.Fprintf(&g.body, "parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\\n' })\n")
+		g.body.WriteString("values := make([]string, 0, len(parts))\n")
+		g.body.WriteString("for _, part := range parts {\n")
+		g.body.WriteString("if part = strings.TrimSpace(part); part != \"\" {\nvalues = append(values, part)\n}\n}\n")
+		if override {
+			fmt.Fprintf(&g.body, "%s = values\n", ref)
+		} else {
+			fmt.Fprintf(&g.body, "%s = append(%s, values...)\n", ref, ref)
+		}
+		g.body.WriteString("return nil\n})\n")
+	}
+}
+
+func (g *generator) declareEnv(flagName string, envNames []string) {
+	g.imports["fmt"] = true
+	g.imports["os"] = true
+	fmt.Fprintf(&g.body, "for _, name := range %#v {\n", envNames)
+	g.body.WriteString("if value, ok := os.LookupEnv(name); ok {\n")
+	// flagSet.Set records the flag as set, like flagsfiller records the fields set from the
+	// environment for Validate
+	fmt.Fprintf(&g.body, "if err := flagSet.Set(%q, value); err != nil {\n", flagName)
+	g.body.WriteString("return fmt.Errorf(\"failed to set from environment variable %s: %w\", name, err)\n}\n")
+	g.body.WriteString("break\n}\n}\n")
+}
+
+// splitValues splits a default the same way as the default value split pattern of flagsfiller
+func splitValues(s string) []string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' })
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func requoteUsage(usage string) string {
+	return strings.NewReplacer("[", "`", "]", "`").Replace(usage)
+}
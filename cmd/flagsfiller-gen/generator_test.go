@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMatchesCommitted(t *testing.T) {
+	src, err := Generate(Options{
+		Dir:       "../../internal/gentest",
+		TypeName:  "Config",
+		EnvPrefix: "App",
+	})
+	require.NoError(t, err)
+
+	committed, err := os.ReadFile("../../internal/gentest/config_flags.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(src), "re-run go generate in internal/gentest")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		error  string
+	}{
+		{
+			name:   "unsupported type",
+			source: "type Config struct {\n\tLabels map[string]int\n}",
+			error:  "failed to process Labels: unsupported type",
+		},
+		{
+			name:   "invalid default",
+			source: "type Config struct {\n\tPort int `default:\"eighty\"`\n}",
+			error:  "failed to process Port: invalid default",
+		},
+		{
+			name:   "recursive pointer",
+			source: "type Config struct {\n\tNext *Config\n}",
+			error:  "failed to process Next: recursive struct type Config",
+		},
+		{
+			name:   "invalid choices default",
+			source: "type Config struct {\n\tLevel string `default:\"trace\" choices:\"debug,info\"`\n}",
+			error:  "failed to process Level: default is invalid: must be one of debug, info",
+		},
+		{
+			name:   "default out of range",
+			source: "type Config struct {\n\tPort int `default:\"0\" min:\"1\"`\n}",
+			error:  "failed to process Port: default is invalid: must be at least 1",
+		},
+		{
+			name:   "unsupported tag",
+			source: "type Config struct {\n\tToken string `sensitive:\"true\"`\n}",
+			error:  "failed to process Token: sensitive tag is unsupported by flagsfiller-gen",
+		},
+		{
+			name:   "unsupported tag of nested struct",
+			source: "type Config struct {\n\tRemote Remote `deprecated:\"\"`\n}\ntype Remote struct {\n\tHost string\n}",
+			error:  "failed to process Remote: deprecated tag is unsupported by flagsfiller-gen",
+		},
+		{
+			name:   "unsupported type tag",
+			source: "type Config struct {\n\tKey []byte `type:\"bytes\"`\n}",
+			error:  "failed to process Key: type tag is unsupported by flagsfiller-gen",
+		},
+		{
+			name:   "missing type",
+			source: "type Other struct{}",
+			error:  "struct type Config not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.WriteFile(filepath.Join(dir, "config.go"),
+				[]byte("package config\n\n"+test.source+"\n"), 0644)
+			require.NoError(t, err)
+
+			_, err = Generate(Options{Dir: dir, TypeName: "Config"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.error)
+		})
+	}
+}
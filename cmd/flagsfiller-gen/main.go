@@ -0,0 +1,69 @@
+// Command flagsfiller-gen generates a reflection-free fill function for a config struct, which
+// declares the same flags as flagsfiller.FlagSetFiller.Fill using direct flag.FlagSet calls.
+//
+// It is intended to be used with go:generate, such as
+//
+//	//go:generate go run github.com/itzg/go-flagsfiller/cmd/flagsfiller-gen -type Config -env App
+//
+// which writes config_flags.go containing
+//
+//	func FillConfig(flagSet *flag.FlagSet, c *Config) error
+//	func ValidateConfig(flagSet *flag.FlagSet, c *Config) error
+//
+// The supported field types are string, bool, int, int64, uint, uint64, float64,
+// time.Duration, []string, and nested structs declared in the same package, which may be embedded
+// or referenced by pointer. The default, usage, aliases, flag, env, envPrefix, and override-value
+// tags are processed like flagsfiller does. The required, min, max, and choices tags are checked by
+// ValidateConfig, which is called after parsing, rather than when each value is set. The other
+// tags processed by flagsfiller, such as sensitive or deprecated, are rejected since the generated
+// code would not behave like Fill.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		typeName  = flag.String("type", "", "name of the struct type to generate for (required)")
+		output    = flag.String("output", "", "output file name, default is <type>_flags.go")
+		envPrefix = flag.String("env", "", "enables environment variables using the given prefix, like flagsfiller.WithEnv")
+		noEnv     = flag.Bool("no-env", false, "disables environment variables, even those declared by env tags")
+		dir       = flag.String("dir", ".", "directory of the package containing the type")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("flagsfiller-gen: ")
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := Generate(Options{
+		Dir:       *dir,
+		TypeName:  *typeName,
+		EnvPrefix: *envPrefix,
+		NoEnv:     *noEnv,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	outputPath := *output
+	if outputPath == "" {
+		outputPath = strings.ToLower(*typeName) + "_flags.go"
+	}
+	if !filepath.IsAbs(outputPath) {
+		outputPath = filepath.Join(*dir, outputPath)
+	}
+	err = os.WriteFile(outputPath, src, 0644)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to write output: %w", err))
+	}
+}
@@ -0,0 +1,173 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Command describes the subcommand selected by ParseCommands or ParseCommandArgs
+type Command struct {
+	// Name is the name of the selected command, where the names of nested commands are separated
+	// by spaces, such as "remote add". It is empty when the struct declared no commands.
+	Name string
+	// Args are the arguments remaining after the flags of the selected command
+	Args []string
+}
+
+// commandField is a field declared with the command tag
+type commandField struct {
+	name  string
+	usage string
+	index int
+}
+
+// commandFields returns the fields of the given struct type that are declared with the command
+// tag, where the name defaults to the field name converted by the field renamer
+func commandFields(structType reflect.Type, options *fillerOptions) ([]commandField, error) {
+	var commands []commandField
+	for i, field := range schemaFor(structType).fields {
+		if !field.isCommand {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct || !field.IsExported() {
+			return nil, fmt.Errorf("command field %s of %s must be an exported struct or struct pointer",
+				field.Name, structType)
+		}
+		name := field.Tag.Get("command")
+		if name == "" {
+			name = options.renameLongName(field.Name)
+		}
+		commands = append(commands, commandField{name: name, usage: field.Tag.Get("usage"), index: i})
+	}
+	return commands, nil
+}
+
+// ParseCommands is a convenience function like ParseFlagSet for CLIs with subcommands, which are
+// declared by struct, or struct pointer, fields with the command tag, such as
+//
+//	type Config struct {
+//		Verbose bool
+//		Serve   *ServeConfig `command:"serve" usage:"start the server"`
+//		Migrate *MigrateConfig `command:"migrate"`
+//	}
+//
+// The flags of the given struct are parsed from os.Args up to the first non-flag argument, which
+// selects the command whose struct is then filled and parsed from the remaining arguments. The
+// commands can be nested in the same way. Pointer fields of the commands that were not selected
+// are left nil. Like flag.CommandLine, errors are handled with flag.ExitOnError unless the
+// WithErrorHandling option is given.
+func ParseCommands(from interface{}, options ...FillerOption) (Command, error) {
+	return ParseCommandArgs(from, os.Args[1:],
+		append([]FillerOption{WithErrorHandling(flag.ExitOnError)}, options...)...)
+}
+
+// ParseCommandArgs is like ParseCommands, but parses the given args. Unless the WithErrorHandling
+// option is given, errors are returned like with ParseArgs.
+func ParseCommandArgs(from interface{}, args []string, options ...FillerOption) (Command, error) {
+	v := reflect.ValueOf(from)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return Command{}, fmt.Errorf("can only parse commands from struct pointer, but it was %s", v.Kind())
+	}
+
+	name := os.Args[0]
+	var selected []string
+	for {
+		filler := New(options...)
+		commands, err := commandFields(v.Elem().Type(), filler.options)
+		if err != nil {
+			return Command{}, err
+		}
+		flagSet := filler.newFlagSet(name)
+		err = filler.Fill(flagSet, v.Interface())
+		if err != nil {
+			return Command{}, err
+		}
+		if len(commands) > 0 {
+			usage := flagSet.Usage
+			flagSet.Usage = func() {
+				usage()
+				printCommands(flagSet, commands)
+			}
+		}
+		err = filler.ParseWithSources(flagSet, args)
+		if err != nil {
+			return Command{Name: strings.Join(selected, " ")}, err
+		}
+		if len(commands) == 0 {
+			return Command{Name: strings.Join(selected, " "), Args: flagSet.Args()}, nil
+		}
+
+		remaining := flagSet.Args()
+		if len(remaining) == 0 {
+			return Command{}, commandError(flagSet, fmt.Errorf("expected a command, one of %s",
+				commandNames(commands)))
+		}
+		command, found := findCommand(commands, remaining[0])
+		if !found {
+			return Command{}, commandError(flagSet, fmt.Errorf("unknown command %s, expected one of %s",
+				remaining[0], commandNames(commands)))
+		}
+
+		fieldValue := v.Elem().Field(command.index)
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			v = fieldValue
+		} else {
+			v = fieldValue.Addr()
+		}
+		selected = append(selected, command.name)
+		name += " " + command.name
+		args = remaining[1:]
+	}
+}
+
+func findCommand(commands []commandField, name string) (commandField, bool) {
+	for _, command := range commands {
+		if command.name == name {
+			return command, true
+		}
+	}
+	return commandField{}, false
+}
+
+func commandNames(commands []commandField) string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// printCommands prints the commands after the flags in the usage
+func printCommands(flagSet *flag.FlagSet, commands []commandField) {
+	_, _ = fmt.Fprintf(flagSet.Output(), "\nCommands:\n")
+	w := tabwriter.NewWriter(flagSet.Output(), 0, 4, 2, ' ', 0)
+	for _, command := range commands {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", command.name, command.usage)
+	}
+	_ = w.Flush()
+}
+
+// commandError reports a missing or unknown command according to the error handling of flagSet,
+// like flag.FlagSet does for an unknown flag
+func commandError(flagSet *flag.FlagSet, err error) error {
+	_, _ = fmt.Fprintln(flagSet.Output(), err)
+	flagSet.Usage()
+	switch flagSet.ErrorHandling() {
+	case flag.ExitOnError:
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type commandsConfig struct {
+	Verbose bool `usage:"enables verbose output"`
+	Serve   *struct {
+		Port    int           `default:"8080"`
+		Timeout time.Duration `default:"5s"`
+	} `command:"serve" usage:"start the server"`
+	Remote struct {
+		Add struct {
+			Url string
+		} `command:""`
+		Remove *struct{} `command:"rm"`
+	} `command:"remote" usage:"manage remotes"`
+}
+
+func TestParseCommandArgs(t *testing.T) {
+	t.Run("serve", func(t *testing.T) {
+		var config commandsConfig
+		command, err := flagsfiller.ParseCommandArgs(&config, []string{"--verbose", "serve", "--port", "9090", "extra"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "serve", command.Name)
+		assert.Equal(t, []string{"extra"}, command.Args)
+		assert.True(t, config.Verbose)
+		require.NotNil(t, config.Serve)
+		assert.Equal(t, 9090, config.Serve.Port)
+		assert.Equal(t, 5*time.Second, config.Serve.Timeout)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		var config commandsConfig
+		command, err := flagsfiller.ParseCommandArgs(&config, []string{"remote", "add", "--url", "https://example.com"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "remote add", command.Name)
+		assert.Equal(t, "https://example.com", config.Remote.Add.Url)
+		assert.Nil(t, config.Serve)
+		assert.Nil(t, config.Remote.Remove)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		var config commandsConfig
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseCommandArgs(&config, []string{"--verbose"}, flagsfiller.WithOutput(&output))
+		assert.EqualError(t, err, "expected a command, one of serve, remote")
+		assert.Equal(t, `expected a command, one of serve, remote
+Usage of `+os.Args[0]+`:
+  -verbose
+    	enables verbose output
+
+Commands:
+  serve   start the server
+  remote  manage remotes
+`, output.String())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		var config commandsConfig
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseCommandArgs(&config, []string{"remote", "rename"}, flagsfiller.WithOutput(&output))
+		assert.EqualError(t, err, "unknown command rename, expected one of add, rm")
+	})
+}
+
+func TestParseCommandArgsInvalidCommandField(t *testing.T) {
+	type Config struct {
+		Serve string `command:"serve"`
+	}
+
+	var config Config
+	_, err := flagsfiller.ParseCommandArgs(&config, []string{"serve"})
+	assert.ErrorContains(t, err, "command field Serve")
+}
@@ -0,0 +1,168 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// completeMacros maps the values of the complete tag to the carapace-spec macros that complete
+// them
+var completeMacros = map[string]string{
+	"file": "$files",
+	"dir":  "$directories",
+}
+
+// choicesValue is implemented by the flag values that only accept a fixed set of names
+type choicesValue interface {
+	// choices returns the accepted names and if several can be given as a comma-separated list
+	choices() (names []string, multiple bool)
+}
+
+// CompletionFlag describes a flag for generating shell completions, such as by the completion
+// package
+type CompletionFlag struct {
+	// Names are the flag's name and aliases, where single letter names are listed first.
+	// Deprecated aliases are omitted.
+	Names []string
+	// Usage is the usage declared by the field
+	Usage string
+	// IsBool is true for flags that do not take a value, such as "-verbose"
+	IsBool bool
+	// Values are the accepted names of enum and bitmask fields, as well as the values declared by
+	// a choices tag
+	Values []string
+	// Multiple is true when several of the Values can be given as a comma-separated list
+	Multiple bool
+	// Complete is the value of the field's complete tag, such as "file" or "dir"
+	Complete string
+}
+
+// CompletionFlags describes the flags declared in flagSet by the fields of this FlagSetFiller, in
+// declaration order, for generating shell completions. Deprecated flags are omitted.
+func (f *FlagSetFiller) CompletionFlags(flagSet *flag.FlagSet) []CompletionFlag {
+	aliases := f.flagAliases(flagSet)
+	var result []CompletionFlag
+	for _, field := range f.declaredFields(flagSet) {
+		if _, deprecated := field.StructField.Tag.Lookup("deprecated"); deprecated {
+			continue
+		}
+		names := flagNames(field, aliases)
+		boolFlag, ok := field.flag.Value.(interface{ IsBoolFlag() bool })
+		values, multiple := completionChoices(field)
+		result = append(result, CompletionFlag{
+			Names:    names,
+			Usage:    field.usage,
+			IsBool:   ok && boolFlag.IsBoolFlag(),
+			Values:   values,
+			Multiple: multiple,
+			Complete: field.StructField.Tag.Get("complete"),
+		})
+	}
+	return result
+}
+
+// WriteCompletionSpec writes a carapace-spec (https://carapace-sh.github.io/carapace-spec/) to w
+// for the fields that declared a flag in flagSet, which needs to have been filled by this
+// FlagSetFiller. The spec allows for generating completions for the common shells with carapace.
+// Each flag is described by its field's usage along with its aliases, the names accepted by enum
+// and bitmask fields, as well as choices tags, are completed, and fields declared with
+// `complete:"file"` or `complete:"dir"` complete paths. Deprecated aliases are omitted.
+func (f *FlagSetFiller) WriteCompletionSpec(w io.Writer, flagSet *flag.FlagSet) error {
+	flags := &yaml.Node{Kind: yaml.MappingNode}
+	completions := &yaml.Node{Kind: yaml.MappingNode}
+	for _, completion := range f.CompletionFlags(flagSet) {
+		names := make([]string, len(completion.Names))
+		for i, name := range completion.Names {
+			names[i] = completionFlagName(name)
+		}
+		key := strings.Join(names, ", ")
+		if !completion.IsBool {
+			key += "="
+		}
+		flags.Content = append(flags.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: completion.Usage},
+		)
+
+		values := completionValues(completion)
+		if len(values) == 0 {
+			continue
+		}
+		valuesNode := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, value := range values {
+			valuesNode.Content = append(valuesNode.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+		}
+		// keyed by the primary name, which may not be listed first
+		completions.Content = append(completions.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: f.fieldFlags[f.flagPaths[completion.Names[0]]]},
+			valuesNode,
+		)
+	}
+
+	spec := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "name"},
+		{Kind: yaml.ScalarNode, Value: filepath.Base(flagSet.Name())},
+		{Kind: yaml.ScalarNode, Value: "flags"},
+		flags,
+	}}
+	if len(completions.Content) > 0 {
+		spec.Content = append(spec.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "completion"},
+			&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Value: "flag"},
+				completions,
+			}},
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{spec}})
+	if err != nil {
+		return fmt.Errorf("failed to write completion spec: %w", err)
+	}
+	return encoder.Close()
+}
+
+// completionFlagName renders a flag name the way a user would typically type it, where single
+// letter names are given with one dash and the others with two dashes
+func completionFlagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// completionValues returns the carapace-spec values that complete the given flag
+func completionValues(completion CompletionFlag) []string {
+	if macro, exists := completeMacros[completion.Complete]; exists {
+		return []string{macro}
+	}
+	if completion.Multiple && len(completion.Values) > 0 {
+		return append(completion.Values[:len(completion.Values):len(completion.Values)], "$uniquelist(,)")
+	}
+	return completion.Values
+}
+
+// completionChoices returns the names accepted by the given field's flag and if several can be
+// given as a comma-separated list
+func completionChoices(field declaredField) ([]string, bool) {
+	// the choices tag is implemented by a wrapper, so look for choices before unwrapping it
+	value := field.flag.Value
+	if wrapped, ok := value.(*fieldValue); ok {
+		value = wrapped.Value
+	}
+	if choices, ok := value.(choicesValue); ok {
+		names, multiple := choices.choices()
+		if len(names) == 0 {
+			return nil, false
+		}
+		return names, multiple
+	}
+	return nil, false
+}
@@ -0,0 +1,238 @@
+// Package completion generates shell completion scripts for the flags that flagsfiller declares
+// from a config struct, such as
+//
+//	if len(os.Args) == 3 && os.Args[1] == "completion" {
+//		err := completion.Generate(os.Stdout, os.Args[2], &Config{})
+//		...
+//	}
+//
+// where the script is then loaded by the shell, such as with
+//
+//	source <(app completion bash)
+//
+// The names accepted by enum and bitmask fields, as well as the values declared by choices tags,
+// are completed, and fields declared with `complete:"file"` or `complete:"dir"` complete paths.
+package completion
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/itzg/go-flagsfiller"
+)
+
+// Shells are the shells supported by Generate and Write
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Generate writes the completion script for the given shell, one of Shells, to w. The flags are
+// declared from the given struct reference with the given options, like flagsfiller.Parse, and
+// the completed command is named by os.Args[0].
+func Generate(w io.Writer, shell string, cfg interface{}, options ...flagsfiller.FillerOption) error {
+	filler := flagsfiller.New(options...)
+	flagSet := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	err := filler.Fill(flagSet, cfg)
+	if err != nil {
+		return err
+	}
+	return Write(w, shell, filler, flagSet)
+}
+
+// Write writes the completion script for the given shell, one of Shells, to w for the flags that
+// the given filler declared in flagSet. The completed command is named by the base name of the
+// flag set.
+func Write(w io.Writer, shell string, filler *flagsfiller.FlagSetFiller, flagSet *flag.FlagSet) error {
+	command := filepath.Base(flagSet.Name())
+	flags := filler.CompletionFlags(flagSet)
+
+	var script string
+	switch shell {
+	case "bash":
+		script = bashScript(command, flags)
+	case "zsh":
+		script = zshScript(command, flags)
+	case "fish":
+		script = fishScript(command, flags)
+	default:
+		return fmt.Errorf("unsupported shell %q, expected one of %s", shell, strings.Join(Shells, ", "))
+	}
+	_, err := io.WriteString(w, script)
+	if err != nil {
+		return fmt.Errorf("failed to write %s completion: %w", shell, err)
+	}
+	return nil
+}
+
+var invalidFunctionChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// functionName returns the name of the shell function that completes the given command
+func functionName(command string) string {
+	return "_" + invalidFunctionChars.ReplaceAllString(command, "_")
+}
+
+// flagName renders a flag name the way a user would typically type it, where single letter names
+// are given with one dash and the others with two dashes
+func flagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// quote single-quotes s for the shells, where each single quote in s ends the quoted string,
+// is escaped with a backslash, and then starts another quoted string
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func bashScript(command string, flags []flagsfiller.CompletionFlag) string {
+	fn := functionName(command)
+	var allNames []string
+	var cases strings.Builder
+	for _, completion := range flags {
+		var names []string
+		for _, name := range completion.Names {
+			names = append(names, flagName(name))
+		}
+		allNames = append(allNames, names...)
+		if completion.IsBool {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&cases, "        %s)\n", strings.Join(names, "|"))
+		switch {
+		case completion.Complete == "file":
+			cases.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		case completion.Complete == "dir":
+			cases.WriteString("            COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+		case len(completion.Values) > 0 && completion.Multiple:
+			_, _ = fmt.Fprintf(&cases, "            COMPREPLY=($(compgen -P \"${cur%%\"${cur##*,}\"}\" -W %s -- \"${cur##*,}\"))\n",
+				quote(strings.Join(completion.Values, " ")))
+		case len(completion.Values) > 0:
+			_, _ = fmt.Fprintf(&cases, "            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n",
+				quote(strings.Join(completion.Values, " ")))
+		}
+		cases.WriteString("            return\n            ;;\n")
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "# bash completion for %s\n\n", command)
+	_, _ = fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    # the value of --name=value is split into its own word\n")
+	b.WriteString("    if [[ \"$cur\" == \"=\" ]]; then\n")
+	b.WriteString("        cur=\"\"\n")
+	b.WriteString("    elif [[ \"$prev\" == \"=\" ]]; then\n")
+	b.WriteString("        prev=\"${COMP_WORDS[COMP_CWORD-2]}\"\n")
+	b.WriteString("    fi\n")
+	if cases.Len() > 0 {
+		b.WriteString("    case \"$prev\" in\n")
+		b.WriteString(cases.String())
+		b.WriteString("    esac\n")
+	}
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	_, _ = fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", quote(strings.Join(allNames, " ")))
+	b.WriteString("    fi\n")
+	b.WriteString("}\n\n")
+	_, _ = fmt.Fprintf(&b, "complete -o default -F %s %s\n", fn, command)
+	return b.String()
+}
+
+// zshEscaper escapes the characters that are special within the brackets and the message of an
+// _arguments spec
+var zshEscaper = strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`)
+
+func zshScript(command string, flags []flagsfiller.CompletionFlag) string {
+	fn := functionName(command)
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "#compdef %s\n\n", command)
+	_, _ = fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    _arguments \\\n")
+	for _, completion := range flags {
+		var names []string
+		for _, name := range completion.Names {
+			name = flagName(name)
+			if !completion.IsBool {
+				// accepts the value as the next argument or after =
+				name += "="
+			}
+			names = append(names, name)
+		}
+		var spec string
+		if completion.Usage != "" {
+			spec = "[" + zshEscaper.Replace(completion.Usage) + "]"
+		}
+		if !completion.IsBool {
+			message := zshEscaper.Replace(completion.Names[len(completion.Names)-1])
+			spec += ":" + message + ":" + zshAction(completion, message)
+		}
+		if len(names) == 1 {
+			_, _ = fmt.Fprintf(&b, "        %s \\\n", quote(names[0]+spec))
+		} else {
+			exclusions := strings.Join(names, " ")
+			exclusions = strings.ReplaceAll(exclusions, "=", "")
+			_, _ = fmt.Fprintf(&b, "        %s{%s}%s \\\n",
+				quote("("+exclusions+")"), strings.Join(names, ","), quote(spec))
+		}
+	}
+	b.WriteString("        '*::arg:_default'\n")
+	b.WriteString("}\n\n")
+	_, _ = fmt.Fprintf(&b, "if [ \"$funcstack[1]\" = %q ]; then\n", fn)
+	_, _ = fmt.Fprintf(&b, "    %s \"$@\"\n", fn)
+	b.WriteString("else\n")
+	_, _ = fmt.Fprintf(&b, "    compdef %s %s\n", fn, command)
+	b.WriteString("fi\n")
+	return b.String()
+}
+
+func zshAction(completion flagsfiller.CompletionFlag, message string) string {
+	switch {
+	case completion.Complete == "file":
+		return "_files"
+	case completion.Complete == "dir":
+		return "_files -/"
+	case len(completion.Values) > 0 && completion.Multiple:
+		return "_values -s , " + message + " " + strings.Join(completion.Values, " ")
+	case len(completion.Values) > 0:
+		return "(" + strings.Join(completion.Values, " ") + ")"
+	}
+	return " "
+}
+
+func fishScript(command string, flags []flagsfiller.CompletionFlag) string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "# fish completion for %s\n\n", command)
+	for _, completion := range flags {
+		line := "complete -c " + command
+		for _, name := range completion.Names {
+			if len(name) == 1 {
+				line += " -s " + name
+			} else {
+				line += " -l " + name
+			}
+		}
+		if completion.Usage != "" {
+			line += " -d " + quote(completion.Usage)
+		}
+		if !completion.IsBool {
+			line += " -r"
+			switch {
+			case completion.Complete == "file":
+				line += " -F"
+			case completion.Complete == "dir":
+				line += " -f -a '(__fish_complete_directories)'"
+			case len(completion.Values) > 0 && completion.Multiple:
+				line += " -f -a " + quote("(__fish_append , "+strings.Join(completion.Values, " ")+")")
+			case len(completion.Values) > 0:
+				line += " -f -a " + quote(strings.Join(completion.Values, " "))
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
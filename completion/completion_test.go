@@ -0,0 +1,134 @@
+package completion_test
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/itzg/go-flagsfiller/completion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type color int
+
+type feature uint8
+
+func init() {
+	flagsfiller.RegisterEnum(map[string]color{"red": 1, "green": 2})
+	flagsfiller.RegisterBitmask(map[string]feature{"metrics": 1, "tracing": 2})
+}
+
+type config struct {
+	Verbose  bool          `aliases:"v" usage:"enables verbose output"`
+	Config   string        `complete:"file" usage:"the app's config file"`
+	Data     string        `complete:"dir"`
+	Color    color         `usage:"the color"`
+	Features feature       `usage:"the features"`
+	Timeout  time.Duration `aliases:"t" deprecated-aliases:"wait"`
+	Format   string        `choices:"json,text"`
+}
+
+func write(t *testing.T, shell string) string {
+	var cfg config
+	filler := flagsfiller.New()
+	flagSet := flag.NewFlagSet("/usr/bin/my-app", flag.ContinueOnError)
+	err := filler.Fill(flagSet, &cfg)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = completion.Write(&buf, shell, filler, flagSet)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func TestWriteBash(t *testing.T) {
+	assert.Equal(t, `# bash completion for my-app
+
+_my_app() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    # the value of --name=value is split into its own word
+    if [[ "$cur" == "=" ]]; then
+        cur=""
+    elif [[ "$prev" == "=" ]]; then
+        prev="${COMP_WORDS[COMP_CWORD-2]}"
+    fi
+    case "$prev" in
+        --config)
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return
+            ;;
+        --data)
+            COMPREPLY=($(compgen -d -- "$cur"))
+            return
+            ;;
+        --color)
+            COMPREPLY=($(compgen -W 'green red' -- "$cur"))
+            return
+            ;;
+        --features)
+            COMPREPLY=($(compgen -P "${cur%"${cur##*,}"}" -W 'metrics tracing' -- "${cur##*,}"))
+            return
+            ;;
+        -t|--timeout)
+            return
+            ;;
+        --format)
+            COMPREPLY=($(compgen -W 'json text' -- "$cur"))
+            return
+            ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W '-v --verbose --config --data --color --features -t --timeout --format' -- "$cur"))
+    fi
+}
+
+complete -o default -F _my_app my-app
+`, write(t, "bash"))
+}
+
+func TestWriteZsh(t *testing.T) {
+	assert.Equal(t, `#compdef my-app
+
+_my_app() {
+    _arguments \
+        '(-v --verbose)'{-v,--verbose}'[enables verbose output]' \
+        '--config=[the app'\''s config file]:config:_files' \
+        '--data=:data:_files -/' \
+        '--color=[the color]:color:(green red)' \
+        '--features=[the features]:features:_values -s , features metrics tracing' \
+        '(-t --timeout)'{-t=,--timeout=}':timeout: ' \
+        '--format=:format:(json text)' \
+        '*::arg:_default'
+}
+
+if [ "$funcstack[1]" = "_my_app" ]; then
+    _my_app "$@"
+else
+    compdef _my_app my-app
+fi
+`, write(t, "zsh"))
+}
+
+func TestWriteFish(t *testing.T) {
+	assert.Equal(t, `# fish completion for my-app
+
+complete -c my-app -s v -l verbose -d 'enables verbose output'
+complete -c my-app -l config -d 'the app'\''s config file' -r -F
+complete -c my-app -l data -r -f -a '(__fish_complete_directories)'
+complete -c my-app -l color -d 'the color' -r -f -a 'green red'
+complete -c my-app -l features -d 'the features' -r -f -a '(__fish_append , metrics tracing)'
+complete -c my-app -s t -l timeout -r
+complete -c my-app -l format -r -f -a 'json text'
+`, write(t, "fish"))
+}
+
+func TestGenerateUnsupportedShell(t *testing.T) {
+	var cfg config
+	var buf strings.Builder
+	err := completion.Generate(&buf, "powershell", &cfg)
+	assert.EqualError(t, err, `unsupported shell "powershell", expected one of bash, zsh, fish`)
+}
@@ -0,0 +1,108 @@
+package flagsfiller
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// conditionalDefault is declared by the default-if tag of the field at path
+type conditionalDefault struct {
+	path       string
+	conditions []defaultCondition
+}
+
+// defaultCondition selects the default value when the field named by controlling has the value
+// given by match
+type defaultCondition struct {
+	controlling string
+	match       string
+	value       string
+	// resolved is the path of the controlling field
+	resolved string
+}
+
+// parseDefaultIf parses a default-if tag, such as "Scheme=https:443,Scheme=http:80"
+func parseDefaultIf(path string, tagValue string) (conditionalDefault, error) {
+	conditional := conditionalDefault{path: path}
+	for _, entry := range strings.Split(tagValue, ",") {
+		condition, value, hasValue := strings.Cut(entry, ":")
+		controlling, match, hasMatch := strings.Cut(condition, "=")
+		if !hasValue || !hasMatch || controlling == "" {
+			return conditional, fmt.Errorf("invalid default-if tag entry %q of field %s: expected Field=value:default",
+				entry, path)
+		}
+		conditional.conditions = append(conditional.conditions, defaultCondition{
+			controlling: controlling,
+			match:       match,
+			value:       value,
+		})
+	}
+	return conditional, nil
+}
+
+// resolveConditionalDefaults resolves the controlling fields named by default-if tags
+func (f *FlagSetFiller) resolveConditionalDefaults() error {
+	var errs []error
+	for i := range f.conditionalDefaults {
+		conditional := &f.conditionalDefaults[i]
+		for j := range conditional.conditions {
+			condition := &conditional.conditions[j]
+			resolved, exists := f.resolveFieldPath(conditional.path, condition.controlling)
+			if !exists {
+				errs = append(errs, fmt.Errorf("field %s has a default depending on unknown field %s",
+					conditional.path, condition.controlling))
+				continue
+			}
+			condition.resolved = resolved
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyConditionalDefaults sets the fields declared with a default-if tag that were not set by
+// any source, which is called by ParseWithSources after parsing. For example, a Port field
+// declared with `default-if:"Scheme=https:443,Scheme=http:80"` is set to 443 when the Scheme
+// field's value is https. The conditions are given as comma-separated Field=value:default entries,
+// where the first one that matches applies. Like the requires tag, Field is the name of a sibling
+// field or a full field path.
+func (f *FlagSetFiller) ApplyConditionalDefaults(flagSet *flag.FlagSet) error {
+	set := f.setPaths(flagSet)
+	for _, conditional := range f.conditionalDefaults {
+		if set[conditional.path] {
+			continue
+		}
+		declared := flagSet.Lookup(f.fieldFlags[conditional.path])
+		if declared == nil {
+			continue
+		}
+		for _, condition := range conditional.conditions {
+			controlling := f.fields[condition.resolved]
+			if formatValue(reflect.ValueOf(controlling.Value).Elem()) != condition.match {
+				continue
+			}
+			// the flags of slices and maps add to the field, so that the default replaces it.
+			// Maps are cleared in place since some of their flags, such as strToStrMapVar, hold
+			// the map itself.
+			field := reflect.ValueOf(f.fields[conditional.path].Value).Elem()
+			switch {
+			case field.Kind() == reflect.Slice:
+				field.Set(reflect.Zero(field.Type()))
+			case field.Kind() == reflect.Map && field.IsNil():
+				field.Set(reflect.MakeMap(field.Type()))
+			case field.Kind() == reflect.Map:
+				clearMap(field)
+			}
+			err := declared.Value.Set(condition.value)
+			if err != nil {
+				return fmt.Errorf("failed to apply default %q of field %s: %w",
+					condition.value, conditional.path, err)
+			}
+			f.fieldSources[conditional.path] = SourceDefault
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalDefaults(t *testing.T) {
+	type Config struct {
+		Scheme string `default:"http"`
+		Port   int    `default:"8000" default-if:"Scheme=https:443,Scheme=http:80"`
+		Remote struct {
+			Fast    bool
+			Timeout time.Duration `default:"30s" default-if:"Fast=true:1s"`
+		}
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantPort    int
+		wantTimeout time.Duration
+	}{
+		{name: "default scheme", args: nil, wantPort: 80, wantTimeout: 30 * time.Second},
+		{name: "https", args: []string{"--scheme", "https"}, wantPort: 443, wantTimeout: 30 * time.Second},
+		{name: "no match", args: []string{"--scheme", "ftp"}, wantPort: 8000, wantTimeout: 30 * time.Second},
+		{name: "explicit", args: []string{"--scheme", "https", "--port", "8443"}, wantPort: 8443, wantTimeout: 30 * time.Second},
+		{name: "nested", args: []string{"--remote-fast"}, wantPort: 80, wantTimeout: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			_, err := flagsfiller.ParseArgs(&config, tt.args)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantPort, config.Port)
+			assert.Equal(t, tt.wantTimeout, config.Remote.Timeout)
+		})
+	}
+}
+
+func TestConditionalDefaultsReplaceCollections(t *testing.T) {
+	type Config struct {
+		Mode    string            `default:"dev"`
+		Hosts   []string          `default:"localhost" default-if:"Mode=prod:a.example.com,Mode=prod:ignored"`
+		Ports   []int             `default:"8080,8081" default-if:"Mode=prod:443"`
+		Weights map[string]int    `default:"local=1" default-if:"Mode=prod:remote=2"`
+		Labels  map[string]string `default:"env=local" default-if:"Mode=prod:env=prod"`
+	}
+
+	var config Config
+	flagset, err := flagsfiller.ParseArgs(&config, []string{"--mode", "prod"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a.example.com"}, config.Hosts)
+	assert.Equal(t, []int{443}, config.Ports)
+	assert.Equal(t, map[string]int{"remote": 2}, config.Weights)
+	assert.Equal(t, map[string]string{"env": "prod"}, config.Labels)
+	assert.Equal(t, "env=prod", flagset.Lookup("labels").Value.String())
+}
+
+func TestConditionalDefaultsStringMapWithoutDefault(t *testing.T) {
+	type Config struct {
+		Mode   string
+		Labels map[string]string `default-if:"Mode=dev:env=dev"`
+	}
+
+	var config Config
+	_, err := flagsfiller.ParseArgs(&config, []string{"--mode", "dev"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "dev"}, config.Labels)
+}
+
+func TestConditionalDefaultsSetFromEnv(t *testing.T) {
+	type Config struct {
+		Scheme string `default:"https"`
+		Port   int    `default-if:"Scheme=https:443"`
+	}
+
+	t.Setenv("COND_PORT", "8443")
+
+	var config Config
+	_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithEnv("Cond"))
+	require.NoError(t, err)
+	assert.Equal(t, 8443, config.Port)
+}
+
+func TestConditionalDefaultsInvalid(t *testing.T) {
+	t.Run("malformed", func(t *testing.T) {
+		type Config struct {
+			Port int `default-if:"Scheme:443"`
+		}
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid default-if tag entry "Scheme:443" of field Port`)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		type Config struct {
+			Port int `default-if:"Schema=https:443"`
+		}
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		assert.EqualError(t, err, "field Port has a default depending on unknown field Schema")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		type Config struct {
+			Scheme string `default:"https"`
+			Port   int    `default-if:"Scheme=https:secure"`
+		}
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithOutput(io.Discard))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `failed to apply default "secure" of field Port`)
+	})
+}
@@ -0,0 +1,111 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configFile holds the values loaded from a config file
+type configFile struct {
+	path string
+	tree valueTree
+}
+
+// loadConfigFile loads the config file at path with the given decoder, or when nil, the decoder
+// is selected by the file extension, where .toml files are decoded as TOML and others as YAML
+func loadConfigFile(path string, decode func(content []byte) (valueTree, error)) (configFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return configFile{}, err
+	}
+	if decode == nil {
+		decode = decodeValueTree
+		if strings.EqualFold(filepath.Ext(path), ".toml") {
+			decode = decodeTOMLValueTree
+		}
+	}
+	tree, err := decode(content)
+	if err != nil {
+		return configFile{}, err
+	}
+	return configFile{path: path, tree: tree}, nil
+}
+
+// applyConfigFiles sets the flag of the field at path from the config files that have a value for
+// it, in the order the files were given. When a file has no value at the field's path, the flag's
+// name is also looked up as a top-level key, which allows for flat files and respects the names
+// given by flag tags.
+func (f *FlagSetFiller) applyConfigFiles(flagSet *flag.FlagSet, path string, flagName string) error {
+	if len(f.options.configFiles) == 0 {
+		return nil
+	}
+	f.source = SourceFile
+	defer func() {
+		f.source = SourceArgs
+	}()
+	for _, file := range f.options.configFiles {
+		node, exists := file.tree.locate(strings.Split(path, "."))
+		if !exists {
+			node, exists = file.tree.locate([]string{flagName})
+		}
+		if !exists {
+			continue
+		}
+		value := flagSet.Lookup(flagName).Value
+		val, err := setFromTree(value, node)
+		if err != nil {
+			return fmt.Errorf("failed to set %s from config file %s: %w", path, file.path,
+				f.redactError(flagName, err, val))
+		}
+		endSource(value)
+		f.fieldSources[path] = SourceFile
+	}
+	return nil
+}
+
+// listValue is implemented by the flag values of slices, which are set from the elements of a
+// list in a config file rather than splitting a value
+type listValue interface {
+	// beginList empties the slice and sets each of the following values as one element
+	beginList()
+	endList()
+}
+
+// setFromTree sets value from a value decoded from a config file and returns the rendered value
+// that was set. The elements of a list are set one at a time when value holds a slice, so that
+// they are not split.
+func setFromTree(value flag.Value, node interface{}) (string, error) {
+	elements, isList := node.([]interface{})
+	list, hasList := findListValue(value)
+	if !isList || !hasList {
+		val := renderTreeValue(node)
+		return val, value.Set(val)
+	}
+	list.beginList()
+	defer list.endList()
+	for _, element := range elements {
+		val := renderTreeValue(element)
+		err := value.Set(val)
+		if err != nil {
+			return val, err
+		}
+	}
+	return "", nil
+}
+
+// findListValue returns the listValue of the given flag value, which may be wrapped
+func findListValue(value flag.Value) (listValue, bool) {
+	for {
+		switch v := value.(type) {
+		case listValue:
+			return v, true
+		case wrappingValue:
+			value = v.unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
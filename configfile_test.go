@@ -0,0 +1,179 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type configFileConfig struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080"`
+	Timeout time.Duration `default:"5s"`
+	Tags    []string
+	Remote  struct {
+		MaxTimeout time.Duration
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestWithConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+host: example.com
+port: 9090
+timeout: 10s
+tags: [a, b]
+remote:
+  max-timeout: 1m
+`)
+	t.Setenv("CFG_PORT", "7070")
+
+	var config configFileConfig
+	_, err := flagsfiller.ParseArgs(&config, []string{"--timeout", "20s"},
+		flagsfiller.WithConfigFile(path), flagsfiller.WithEnv("Cfg"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", config.Host)
+	assert.Equal(t, 7070, config.Port)
+	assert.Equal(t, 20*time.Second, config.Timeout)
+	assert.Equal(t, []string{"a", "b"}, config.Tags)
+	assert.Equal(t, time.Minute, config.Remote.MaxTimeout)
+}
+
+func TestWithConfigFileLists(t *testing.T) {
+	type Config struct {
+		Tags    []string `default:"default"`
+		Ports   []int
+		Names   []string `max-occurs:"2"`
+		Columns string
+	}
+
+	path := writeConfigFile(t, `
+tags: ["a,b", c]
+ports: [80, 443]
+names: [x, y, z]
+columns: [id, name]
+`)
+
+	var config Config
+	_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithConfigFile(path))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set Names from config file")
+	assert.Contains(t, err.Error(), "can have at most 2 values")
+
+	path = writeConfigFile(t, `
+tags: ["a,b", c]
+ports: [80, 443]
+names: [x, y]
+columns: [id, name]
+`)
+	config = Config{}
+	_, err = flagsfiller.ParseArgs(&config, []string{"--ports", "8080"},
+		flagsfiller.WithConfigFile(path), flagsfiller.WithValueSplitPattern(";"))
+	require.NoError(t, err)
+
+	// the elements replace the default and are not split
+	assert.Equal(t, []string{"a,b", "c"}, config.Tags)
+	assert.Equal(t, []int{80, 443, 8080}, config.Ports)
+	assert.Equal(t, []string{"x", "y"}, config.Names)
+	assert.Equal(t, "id,name", config.Columns)
+}
+
+func TestWithConfigFileFlagNameKeys(t *testing.T) {
+	path := writeConfigFile(t, "remote-max-timeout: 1m\n")
+
+	var config configFileConfig
+	_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithConfigFile(path))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, config.Remote.MaxTimeout)
+}
+
+func TestWithConfigFileDeferred(t *testing.T) {
+	path := writeConfigFile(t, "host: example.com\n")
+
+	var config configFileConfig
+	filler := flagsfiller.New(flagsfiller.WithConfigFile(path), flagsfiller.WithDeferredSources())
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", config.Host)
+
+	err = filler.ParseWithSources(&flagset, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", config.Host)
+}
+
+func TestWithConfigFileErrors(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		var config configFileConfig
+		err := flagsfiller.New(flagsfiller.WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))).
+			Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to load config file")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		path := writeConfigFile(t, "port: eighty\n")
+		var config configFileConfig
+		err := flagsfiller.New(flagsfiller.WithConfigFile(path)).
+			Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to set Port from config file "+path)
+	})
+}
+
+func TestWithTOML(t *testing.T) {
+	type Config struct {
+		Host    string `default:"localhost"`
+		Listen  string `flag:"listen-address"`
+		Started time.Time
+		Tags    []string
+		Remote  struct {
+			MaxTimeout time.Duration
+			Labels     map[string]string
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.conf")
+	require.NoError(t, os.WriteFile(path, []byte(`
+host = "example.com"
+listen-address = ":8080"
+started = 2024-03-01T10:00:00Z
+tags = ["a", "b"]
+
+[remote]
+max_timeout = "1m"
+labels = { env = "prod", tier = "web" }
+`), 0644))
+
+	var config Config
+	_, err := flagsfiller.ParseArgs(&config, []string{"--host", "override.example.com"},
+		flagsfiller.WithTOML(path))
+	require.NoError(t, err)
+
+	assert.Equal(t, "override.example.com", config.Host)
+	assert.Equal(t, ":8080", config.Listen)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), config.Started)
+	assert.Equal(t, []string{"a", "b"}, config.Tags)
+	assert.Equal(t, time.Minute, config.Remote.MaxTimeout)
+	assert.Equal(t, map[string]string{"env": "prod", "tier": "web"}, config.Remote.Labels)
+}
+
+func TestWithConfigFileTOMLExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("port = 9090\n"), 0644))
+
+	var config configFileConfig
+	_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithConfigFile(path))
+	require.NoError(t, err)
+	assert.Equal(t, 9090, config.Port)
+}
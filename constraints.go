@@ -0,0 +1,206 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// constrainedValue wraps the flag.Value declared for a field to reject the values that do not
+// satisfy the constraints declared by the field's tags, where the field is restored to its
+// previous value
+type constrainedValue struct {
+	flag.Value
+	field reflect.Value
+	check func(field reflect.Value) error
+	// allowed are the values declared by the choices tag, if any
+	allowed []string
+}
+
+// wrapConstraints replaces the value of the given flag with a constrainedValue, when the field's
+// tags declare constraints, and checks the field's default, which is either declared or assigned
+// to the struct. A zero value without a default tag is not checked since it means unset.
+func wrapConstraints(declared *flag.Flag, path string, fieldRef interface{}, structField reflect.StructField,
+	hasDefault bool) error {
+	fieldType := reflect.TypeOf(fieldRef).Elem()
+	checkRange, err := rangeCheck(path, fieldType, structField.Tag)
+	if err != nil {
+		return err
+	}
+	checkChoices, allowed, err := choicesCheck(path, fieldType, structField.Tag)
+	if err != nil {
+		return err
+	}
+	checkValidators, err := validatorsCheck(path, structField)
+	if err != nil {
+		return err
+	}
+	var checks []func(field reflect.Value) error
+	for _, c := range []func(field reflect.Value) error{checkRange, checkChoices, checkValidators} {
+		if c != nil {
+			checks = append(checks, c)
+		}
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	check := func(field reflect.Value) error {
+		for _, c := range checks {
+			if err := c(field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	field := reflect.ValueOf(fieldRef).Elem()
+	if hasDefault || !field.IsZero() {
+		err = check(field)
+		if err != nil {
+			return fmt.Errorf("default of field %s is invalid: %w", path, err)
+		}
+	}
+
+	if isZeroDefault(declared) {
+		declared.DefValue = ""
+	}
+	declared.Usage = quotePlaceholder(declared.Usage, declared)
+	if len(allowed) > 0 {
+		declared.Usage = fmt.Sprintf("%s (one of %s)", declared.Usage, strings.Join(allowed, ", "))
+	}
+	declared.Value = &constrainedValue{Value: declared.Value, field: field, check: check, allowed: allowed}
+	return nil
+}
+
+func (v *constrainedValue) String() string {
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *constrainedValue) Set(s string) error {
+	previous := reflect.New(v.field.Type()).Elem()
+	previous.Set(v.field)
+	err := v.Value.Set(s)
+	if err != nil {
+		return err
+	}
+	err = v.check(v.field)
+	if err != nil {
+		v.field.Set(previous)
+		return err
+	}
+	return nil
+}
+
+func (v *constrainedValue) unwrap() flag.Value {
+	return v.Value
+}
+
+func (v *constrainedValue) choices() ([]string, bool) {
+	if len(v.allowed) > 0 {
+		return v.allowed, false
+	}
+	if choices, ok := v.Value.(choicesValue); ok {
+		return choices.choices()
+	}
+	return nil, false
+}
+
+// Get implements flag.Getter when the wrapped value does
+func (v *constrainedValue) Get() interface{} {
+	if getter, ok := v.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return v.field.Interface()
+}
+
+// rangeCheck returns a check of the min and max tags of a numeric field, or nil when neither is
+// declared. The bounds of time.Duration fields are given as durations, such as "1s".
+func rangeCheck(path string, fieldType reflect.Type, tag reflect.StructTag) (func(field reflect.Value) error, error) {
+	minTag, hasMin := tag.Lookup("min")
+	maxTag, hasMax := tag.Lookup("max")
+	if !hasMin && !hasMax {
+		return nil, nil
+	}
+
+	var parse func(s string) (float64, error)
+	var numeric func(field reflect.Value) float64
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parse = func(s string) (float64, error) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			return float64(n), err
+		}
+		if fieldType == durationType {
+			parse = func(s string) (float64, error) {
+				d, err := time.ParseDuration(s)
+				return float64(d), err
+			}
+		}
+		numeric = func(field reflect.Value) float64 { return float64(field.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parse = func(s string) (float64, error) {
+			n, err := strconv.ParseUint(s, 10, 64)
+			return float64(n), err
+		}
+		numeric = func(field reflect.Value) float64 { return float64(field.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		parse = func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		}
+		numeric = func(field reflect.Value) float64 { return field.Float() }
+	default:
+		return nil, fmt.Errorf("min and max tags of field %s only apply to numeric fields", path)
+	}
+
+	var lower, upper float64
+	var err error
+	if hasMin {
+		lower, err = parse(minTag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min tag %q of field %s", minTag, path)
+		}
+	}
+	if hasMax {
+		upper, err = parse(maxTag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max tag %q of field %s", maxTag, path)
+		}
+	}
+	return func(field reflect.Value) error {
+		value := numeric(field)
+		if hasMin && value < lower {
+			return fmt.Errorf("must be at least %s", minTag)
+		}
+		if hasMax && value > upper {
+			return fmt.Errorf("must be at most %s", maxTag)
+		}
+		return nil
+	}, nil
+}
+
+// choicesCheck returns a check of the choices tag of a string field, which is a comma-separated
+// list of the allowed values, or nil when not declared
+func choicesCheck(path string, fieldType reflect.Type, tag reflect.StructTag) (func(field reflect.Value) error, []string, error) {
+	choices, exists := tag.Lookup("choices")
+	if !exists {
+		return nil, nil, nil
+	}
+	if fieldType.Kind() != reflect.String {
+		return nil, nil, fmt.Errorf("choices tag of field %s only applies to string fields", path)
+	}
+	allowed := strings.Split(choices, ",")
+	return func(field reflect.Value) error {
+		value := field.String()
+		for _, choice := range allowed {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}, allowed, nil
+}
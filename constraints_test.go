@@ -0,0 +1,222 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMaxTags(t *testing.T) {
+	type Config struct {
+		Port    int           `default:"8080" min:"1" max:"65535"`
+		Workers uint          `max:"16"`
+		Ratio   float64       `min:"0" max:"1"`
+		Timeout time.Duration `min:"1s" usage:"the request timeout duration"`
+	}
+
+	t.Run("in range", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--port", "1", "--workers", "16", "--ratio", "0.5", "--timeout", "2s"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, config.Port)
+		assert.Equal(t, uint(16), config.Workers)
+		assert.Equal(t, 0.5, config.Ratio)
+		assert.Equal(t, 2*time.Second, config.Timeout)
+	})
+
+	t.Run("out of range args", func(t *testing.T) {
+		tests := []struct {
+			args []string
+			err  string
+		}{
+			{args: []string{"--port", "0"}, err: `invalid value "0" for flag -port: must be at least 1`},
+			{args: []string{"--port", "70000"}, err: `invalid value "70000" for flag -port: must be at most 65535`},
+			{args: []string{"--workers", "17"}, err: `invalid value "17" for flag -workers: must be at most 16`},
+			{args: []string{"--ratio", "1.5"}, err: `invalid value "1.5" for flag -ratio: must be at most 1`},
+			{args: []string{"--timeout", "10ms"}, err: `invalid value "10ms" for flag -timeout: must be at least 1s`},
+		}
+		for _, tt := range tests {
+			var config Config
+			_, err := flagsfiller.ParseArgs(&config, tt.args, flagsfiller.WithOutput(io.Discard))
+			assert.EqualError(t, err, tt.err)
+			assert.Equal(t, 8080, config.Port, "the previous value is restored")
+		}
+	})
+
+	t.Run("out of range env", func(t *testing.T) {
+		t.Setenv("RANGE_PORT", "70000")
+		var config Config
+		err := flagsfiller.New(flagsfiller.WithEnv("Range")).Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to set from environment variable RANGE_PORT: must be at most 65535")
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+		assert.Equal(t, "", flagset.Lookup("workers").DefValue)
+		assert.Equal(t, "8080", flagset.Lookup("port").DefValue)
+
+		var output bytes.Buffer
+		flagset.SetOutput(&output)
+		flagset.PrintDefaults()
+		// the placeholder is retained when the usage mentions it
+		assert.Equal(t, `  -port value
+    	 (default 8080)
+  -ratio value
+    	
+  -timeout duration
+    	the request timeout duration
+  -workers value
+    	
+`, output.String())
+	})
+}
+
+func TestMinMaxTagsInvalid(t *testing.T) {
+	t.Run("default out of range", func(t *testing.T) {
+		type Config struct {
+			Port int `default:"0" min:"1"`
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "default of field Port is invalid: must be at least 1")
+	})
+
+	t.Run("not numeric", func(t *testing.T) {
+		type Config struct {
+			Host string `min:"1"`
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "min and max tags of field Host only apply to numeric fields")
+	})
+
+	t.Run("invalid bound", func(t *testing.T) {
+		type Config struct {
+			Port int `max:"lots"`
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, `invalid max tag "lots" of field Port`)
+	})
+}
+
+type choicesLevel string
+
+func TestChoicesTag(t *testing.T) {
+	type Config struct {
+		Level  string       `default:"info" choices:"debug,info,warn,error" usage:"the log level"`
+		Format choicesLevel `choices:"json,text"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--level", "warn", "--format", "json"})
+		require.NoError(t, err)
+		assert.Equal(t, "warn", config.Level)
+		assert.Equal(t, choicesLevel("json"), config.Format)
+	})
+
+	t.Run("invalid arg", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--level", "trace"}, flagsfiller.WithOutput(io.Discard))
+		assert.EqualError(t, err, `invalid value "trace" for flag -level: must be one of debug, info, warn, error`)
+		assert.Equal(t, "info", config.Level)
+	})
+
+	t.Run("invalid env", func(t *testing.T) {
+		t.Setenv("CHOICES_FORMAT", "xml")
+		var config Config
+		err := flagsfiller.New(flagsfiller.WithEnv("Choices")).Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to set from environment variable CHOICES_FORMAT: must be one of json, text")
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		type Config struct {
+			Level string `default:"trace" choices:"debug,info"`
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "default of field Level is invalid: must be one of debug, info")
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+		assert.Equal(t, "the log level (one of debug, info, warn, error)", flagset.Lookup("level").Usage)
+	})
+}
+
+func init() {
+	flagsfiller.RegisterValidator("even", func(value any, field reflect.StructField) error {
+		if value.(int)%2 != 0 {
+			return fmt.Errorf("%s must be even", field.Name)
+		}
+		return nil
+	})
+}
+
+func TestValidateTag(t *testing.T) {
+	type Config struct {
+		Address string `default:"localhost:8080" validate:"nonempty,hostport"`
+		Workers int    `validate:"even"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--address", ":9090", "--workers", "4"})
+		require.NoError(t, err)
+		assert.Equal(t, ":9090", config.Address)
+		assert.Equal(t, 4, config.Workers)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			args []string
+			err  string
+		}{
+			{args: []string{"--address", ""}, err: `invalid value "" for flag -address: must not be empty`},
+			{args: []string{"--address", "localhost"}, err: `invalid value "localhost" for flag -address: must be a host:port, but was "localhost"`},
+			{args: []string{"--workers", "3"}, err: `invalid value "3" for flag -workers: Workers must be even`},
+		}
+		for _, tt := range tests {
+			var config Config
+			_, err := flagsfiller.ParseArgs(&config, tt.args, flagsfiller.WithOutput(io.Discard))
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		type Config struct {
+			Token   string `validate:"nonempty"`
+			Workers int    `validate:"even"`
+		}
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, nil)
+		assert.EqualError(t, err, "flag -token: must not be empty")
+
+		_, err = flagsfiller.ParseArgs(&config, []string{"--token", "secret"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		type Config struct {
+			Address string `validate:"hostport,bogus"`
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, `field Address has unknown validator "bogus"`)
+	})
+}
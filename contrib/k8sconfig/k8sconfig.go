@@ -0,0 +1,154 @@
+// Package k8sconfig sets flagsfiller flags from the keys of a Kubernetes ConfigMap or Secret
+// that is mounted as a volume, such that in-cluster services pick up config rollouts live.
+//
+// Each key of the ConfigMap or Secret is mounted as a file named by the key, which is expected
+// to be a flag name, such as remote-host. Apply sets the flags once, typically before parsing
+// the command-line, and Watch keeps polling the mounted directory to apply the keys whose values
+// changed when the kubelet updates the volume:
+//
+//	filler := flagsfiller.New()
+//	err := filler.Fill(flagSet, &config)
+//	...
+//	go k8sconfig.Watch(ctx, filler, flagSet, "/etc/config", 10*time.Second, nil)
+//
+// The fields are set from the goroutine calling Watch, so reads of the config struct need to be
+// synchronized by the caller, such as with an AfterSet interceptor. Keys that are removed from
+// the ConfigMap or Secret leave their fields unchanged. Since setting a []string or
+// map[string]string flag adds to its value, such fields should be declared with
+// `override-value:"true"` when they are reloaded.
+//
+// Only mounted volumes are supported. Watching the ConfigMap or Secret through the Kubernetes
+// API is out of scope, since it would require the client-go dependencies and RBAC access to the
+// objects. Mount the ConfigMap or Secret instead, noting that volumes mounted with subPath are
+// not updated by the kubelet.
+package k8sconfig
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+)
+
+// Load reads the keys of the ConfigMap or Secret mounted at dir. The hidden entries that the
+// kubelet uses for atomic updates, such as ..data, are skipped and trailing newlines are
+// removed from the values.
+func Load(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// stat follows the symlinks of mounted keys
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values[entry.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+	return values, nil
+}
+
+// Apply sets the flags of flagSet named by the keys of the ConfigMap or Secret mounted at dir.
+func Apply(filler *flagsfiller.FlagSetFiller, flagSet *flag.FlagSet, dir string) error {
+	values, err := Load(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", dir, err)
+	}
+	return filler.SetFromMap(flagSet, values)
+}
+
+// Watch applies the keys of the ConfigMap or Secret mounted at dir and then polls dir every
+// interval to apply the keys whose values changed, until ctx is done. After each poll that found
+// changes, onReload, when not nil, is called with the sorted names of the changed keys and the
+// error, if any, from loading or applying them. A key that could not be applied is retried, and
+// reported again, by the following polls. Watch returns the error from the initial apply or
+// otherwise the error of ctx when it is done.
+func Watch(ctx context.Context, filler *flagsfiller.FlagSetFiller, flagSet *flag.FlagSet, dir string,
+	interval time.Duration, onReload func(changed []string, err error)) error {
+
+	applied, err := Load(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", dir, err)
+	}
+	err = filler.SetFromMap(flagSet, applied)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		values, err := Load(dir)
+		if err != nil {
+			// the volume may be in the middle of an update, so retry on the next poll
+			if onReload != nil {
+				onReload(nil, fmt.Errorf("failed to load %s: %w", dir, err))
+			}
+			continue
+		}
+
+		changes := make(map[string]string)
+		for key, value := range values {
+			if previous, exists := applied[key]; !exists || previous != value {
+				changes[key] = value
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		changed := make([]string, 0, len(changes))
+		for key := range changes {
+			changed = append(changed, key)
+		}
+		sort.Strings(changed)
+
+		// each key is set on its own since SetFromMap stops at the first failure, where only the
+		// keys that were set are recorded as applied
+		var errs []error
+		for _, key := range changed {
+			err := filler.SetFromMap(flagSet, map[string]string{key: changes[key]})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			applied[key] = changes[key]
+		}
+		for key := range applied {
+			if _, exists := values[key]; !exists {
+				delete(applied, key)
+			}
+		}
+
+		if onReload != nil {
+			onReload(changed, errors.Join(errs...))
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package k8sconfig_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/itzg/go-flagsfiller/contrib/k8sconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mountVolume lays out the files the same way as the kubelet, where each key is a symlink into
+// the ..data symlink that is swapped to a new timestamped directory on each update
+func mountVolume(t *testing.T, dir string, version string, values map[string]string) {
+	t.Helper()
+
+	versionDir := filepath.Join(dir, "..v"+version)
+	require.NoError(t, os.Mkdir(versionDir, 0o755))
+	for key, value := range values {
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, key), []byte(value), 0o644))
+	}
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	require.NoError(t, os.Symlink(filepath.Base(versionDir), tmpLink))
+	require.NoError(t, os.Rename(tmpLink, filepath.Join(dir, "..data")))
+
+	for key := range values {
+		link := filepath.Join(dir, key)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		require.NoError(t, os.Symlink(filepath.Join("..data", key), link))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	mountVolume(t, dir, "1", map[string]string{
+		"host": "example.com\n",
+		"port": "8080",
+	})
+
+	values, err := k8sconfig.Load(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"host": "example.com", "port": "8080"}, values)
+}
+
+func TestApply(t *testing.T) {
+	type Config struct {
+		Host string `default:"localhost"`
+		Port int
+	}
+
+	dir := t.TempDir()
+	mountVolume(t, dir, "1", map[string]string{"host": "example.com"})
+
+	var config Config
+	filler := flagsfiller.New()
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = k8sconfig.Apply(filler, &flagset, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", config.Host)
+	assert.Equal(t, 0, config.Port)
+}
+
+func TestWatch(t *testing.T) {
+	type Config struct {
+		Host string
+		Port int
+	}
+
+	dir := t.TempDir()
+	mountVolume(t, dir, "1", map[string]string{"host": "example.com", "port": "8080"})
+
+	var config Config
+	ports := make(chan string, 2)
+	filler := flagsfiller.New(flagsfiller.WithAfterSet(func(path string, raw string, value interface{}) {
+		if path == "Port" {
+			ports <- raw
+		}
+	}))
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloads := make(chan []string)
+	done := make(chan error)
+	go func() {
+		done <- k8sconfig.Watch(ctx, filler, &flagset, dir, 10*time.Millisecond, func(changed []string, err error) {
+			assert.NoError(t, err)
+			reloads <- changed
+		})
+	}()
+
+	// wait for the initial apply before updating the volume
+	assert.Equal(t, "8080", <-ports)
+	mountVolume(t, dir, "2", map[string]string{"host": "example.com", "port": "9090"})
+
+	select {
+	case changed := <-reloads:
+		assert.Equal(t, []string{"port"}, changed)
+		assert.Equal(t, "9090", <-ports)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+	assert.Equal(t, "example.com", config.Host)
+	assert.Equal(t, 9090, config.Port)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchRetriesFailedKeys(t *testing.T) {
+	type Config struct {
+		Count int
+		Port  int
+	}
+
+	dir := t.TempDir()
+	mountVolume(t, dir, "1", map[string]string{"count": "1", "port": "8080"})
+
+	var config Config
+	ports := make(chan string, 2)
+	filler := flagsfiller.New(flagsfiller.WithAfterSet(func(path string, raw string, value interface{}) {
+		if path == "Port" {
+			ports <- raw
+		}
+	}))
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type reload struct {
+		changed []string
+		err     error
+	}
+	reloads := make(chan reload, 1)
+	done := make(chan error)
+	go func() {
+		done <- k8sconfig.Watch(ctx, filler, &flagset, dir, 10*time.Millisecond, func(changed []string, err error) {
+			select {
+			case reloads <- reload{changed: changed, err: err}:
+			default:
+			}
+		})
+	}()
+
+	// wait for the initial apply before updating the volume, where the failing count key is
+	// applied before the port key
+	assert.Equal(t, "8080", <-ports)
+	mountVolume(t, dir, "2", map[string]string{"count": "many", "port": "9090"})
+
+	select {
+	case r := <-reloads:
+		assert.Equal(t, []string{"count", "port"}, r.changed)
+		require.Error(t, r.err)
+		assert.Contains(t, r.err.Error(), "count")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+	assert.Equal(t, "9090", <-ports)
+	assert.Equal(t, 9090, config.Port)
+
+	// the failed key is retried by the following polls
+	select {
+	case r := <-reloads:
+		assert.Equal(t, []string{"count"}, r.changed)
+		require.Error(t, r.err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for retry")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchInitialError(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	dir := t.TempDir()
+	mountVolume(t, dir, "1", map[string]string{"port": "not a number"})
+
+	var config Config
+	filler := flagsfiller.New()
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = k8sconfig.Watch(context.Background(), filler, &flagset, dir, time.Second, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port")
+}
@@ -0,0 +1,23 @@
+// Package logruslevel registers logrus.Level as a flagsfiller field type, so the log level of a
+// logrus-based service can be declared in its config struct. Import it for its side effects:
+//
+//	import _ "github.com/itzg/go-flagsfiller/contrib/logruslevel"
+//
+// Levels are parsed by name, such as debug, info, warn, or error, and rendered by name in the
+// usage, where the zero value of logrus.Level renders as panic.
+package logruslevel
+
+import (
+	"reflect"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	flagsfiller.RegisterSimpleType(levelConverter)
+}
+
+func levelConverter(s string, _ reflect.StructTag) (logrus.Level, error) {
+	return logrus.ParseLevel(s)
+}
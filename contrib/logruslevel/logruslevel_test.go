@@ -0,0 +1,50 @@
+package logruslevel_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	_ "github.com/itzg/go-flagsfiller/contrib/logruslevel"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	type Config struct {
+		Level    logrus.Level `default:"info"`
+		Instance logrus.Level
+	}
+
+	config := Config{Instance: logrus.WarnLevel}
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New().Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, logrus.InfoLevel, config.Level)
+	assert.Equal(t, "info", flagset.Lookup("level").DefValue)
+	assert.Equal(t, "warning", flagset.Lookup("instance").DefValue)
+
+	err = flagset.Parse([]string{"--level", "debug", "--instance", "ERROR"})
+	require.NoError(t, err)
+
+	assert.Equal(t, logrus.DebugLevel, config.Level)
+	assert.Equal(t, logrus.ErrorLevel, config.Instance)
+}
+
+func TestInvalidLevel(t *testing.T) {
+	type Config struct {
+		Level logrus.Level
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+	err := flagset.Parse([]string{"--level", "loud"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a valid logrus Level")
+}
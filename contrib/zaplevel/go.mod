@@ -0,0 +1,18 @@
+module github.com/itzg/go-flagsfiller/contrib/zaplevel
+
+go 1.21
+
+require (
+	github.com/itzg/go-flagsfiller v0.0.0-20261016191744-de1af80f8b0d
+	github.com/stretchr/testify v1.10.0
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/iancoleman/strcase v0.3.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
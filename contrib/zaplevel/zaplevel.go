@@ -0,0 +1,52 @@
+// Package zaplevel registers zapcore.Level and zap.AtomicLevel as flagsfiller field types, so the
+// log level of a zap-based service can be declared in its config struct. Import it for its side
+// effects:
+//
+//	import _ "github.com/itzg/go-flagsfiller/contrib/zaplevel"
+//
+// Levels are parsed by name, such as debug, info, or warn, and rendered by name in the usage.
+// A zap.AtomicLevel field is updated in place by SetLevel, so loggers built from it before
+// parsing see the parsed level. Since the zero value of zap.AtomicLevel is not usable, zero
+// fields are initialized with zap.NewAtomicLevel.
+package zaplevel
+
+import (
+	"flag"
+	"reflect"
+
+	"github.com/itzg/go-flagsfiller"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func init() {
+	flagsfiller.RegisterSimpleType(levelConverter)
+	flagsfiller.RegisterFlagValue(newAtomicLevelValue)
+}
+
+func levelConverter(s string, _ reflect.StructTag) (zapcore.Level, error) {
+	return zapcore.ParseLevel(s)
+}
+
+type atomicLevelValue struct {
+	level *zap.AtomicLevel
+}
+
+func newAtomicLevelValue(field *zap.AtomicLevel) flag.Value {
+	if *field == (zap.AtomicLevel{}) {
+		*field = zap.NewAtomicLevel()
+	}
+	return &atomicLevelValue{level: field}
+}
+
+func (v *atomicLevelValue) String() string {
+	if v.level == nil {
+		return ""
+	}
+	return v.level.String()
+}
+
+// Set parses the level by name and sets it on the existing zap.AtomicLevel
+func (v *atomicLevelValue) Set(s string) error {
+	return v.level.UnmarshalText([]byte(s))
+}
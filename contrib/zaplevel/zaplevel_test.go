@@ -0,0 +1,81 @@
+package zaplevel_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	_ "github.com/itzg/go-flagsfiller/contrib/zaplevel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevels(t *testing.T) {
+	type Config struct {
+		Level       zapcore.Level `default:"warn"`
+		NoDefault   zapcore.Level
+		AtomicLevel zap.AtomicLevel `default:"info"`
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New().Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, zapcore.WarnLevel, config.Level)
+	assert.Equal(t, "warn", flagset.Lookup("level").DefValue)
+	assert.Equal(t, "info", flagset.Lookup("no-default").DefValue)
+	assert.Equal(t, "info", flagset.Lookup("atomic-level").DefValue)
+
+	err = flagset.Parse([]string{"--level", "ERROR", "--no-default", "debug", "--atomic-level", "debug"})
+	require.NoError(t, err)
+
+	assert.Equal(t, zapcore.ErrorLevel, config.Level)
+	assert.Equal(t, zapcore.DebugLevel, config.NoDefault)
+	assert.Equal(t, zapcore.DebugLevel, config.AtomicLevel.Level())
+}
+
+func TestInvalidLevel(t *testing.T) {
+	type Config struct {
+		Level zapcore.Level
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(nopWriter{})
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+	err := flagset.Parse([]string{"--level", "loud"})
+	assert.Error(t, err)
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestAtomicLevelSharedWithLogger(t *testing.T) {
+	type Config struct {
+		NoDefault   zap.AtomicLevel
+		Initialized zap.AtomicLevel
+	}
+
+	config := Config{Initialized: zap.NewAtomicLevelAt(zapcore.ErrorLevel)}
+	logLevel := config.Initialized
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+	assert.Equal(t, "info", flagset.Lookup("no-default").DefValue)
+	assert.Equal(t, "error", flagset.Lookup("initialized").DefValue)
+	noDefaultLevel := config.NoDefault
+
+	err := flagset.Parse([]string{"--no-default", "warn", "--initialized", "debug"})
+	require.NoError(t, err)
+
+	assert.Equal(t, zapcore.WarnLevel, noDefaultLevel.Level())
+	assert.Equal(t, zapcore.DebugLevel, logLevel.Level())
+	assert.Equal(t, zapcore.DebugLevel, config.Initialized.Level())
+}
@@ -0,0 +1,36 @@
+// Package debugconfig exposes the effective config of a struct filled by flagsfiller for
+// inspection on a debug port. It is separate from flagsfiller since importing expvar registers
+// the /debug/vars handler on http.DefaultServeMux, which only the programs using it should do.
+//
+//	debugconfig.PublishExpvar("config", &config)
+//	http.Handle("/debug/config", debugconfig.ConfigHandler(&config))
+package debugconfig
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+
+	"github.com/itzg/go-flagsfiller"
+)
+
+// PublishExpvar publishes the effective config of the given struct reference, as returned by
+// flagsfiller.EffectiveConfig, as an expvar variable with the given name, which is served with
+// the other variables at /debug/vars. The values are read on each request, so changes such as
+// reloads are reflected. Like expvar.Publish, it panics if the name is already registered.
+func PublishExpvar(name string, from interface{}) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return flagsfiller.EffectiveConfig(from)
+	}))
+}
+
+// ConfigHandler returns an http.Handler that responds with the effective config of the given
+// struct reference as a JSON object, which can be mounted on a debug port.
+func ConfigHandler(from interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(flagsfiller.EffectiveConfig(from))
+	})
+}
@@ -0,0 +1,51 @@
+package debugconfig_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller/debugconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedConfig struct {
+	Host   string
+	Remote struct {
+		Password string `sensitive:"true"`
+	}
+}
+
+// published numbers the expvar names, since a name can't be published again when the
+// tests are run more than once, such as with -count=2
+var published atomic.Int32
+
+func TestPublishExpvar(t *testing.T) {
+	config := publishedConfig{Host: "localhost"}
+	name := fmt.Sprintf("debugconfig-test-config-%d", published.Add(1))
+	debugconfig.PublishExpvar(name, &config)
+
+	config.Host = "reloaded"
+
+	variable := expvar.Get(name)
+	require.NotNil(t, variable)
+	var values map[string]string
+	require.NoError(t, json.Unmarshal([]byte(variable.String()), &values))
+	assert.Equal(t, "reloaded", values["Host"])
+	assert.Equal(t, "*****", values["Remote.Password"])
+}
+
+func TestConfigHandler(t *testing.T) {
+	config := publishedConfig{Host: "localhost"}
+	config.Remote.Password = "hunter2"
+
+	recorder := httptest.NewRecorder()
+	debugconfig.ConfigHandler(&config).ServeHTTP(recorder, httptest.NewRequest("GET", "/config", nil))
+
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"Host":"localhost","Remote.Password":"*****"}`, recorder.Body.String())
+}
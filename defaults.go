@@ -0,0 +1,37 @@
+package flagsfiller
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Defaulter can be implemented by a struct, or nested struct, being filled to compute default
+// values, such as the hostname or CPU count, that are then rendered as the flag defaults.
+// SetDefaults is called before the struct's fields are mapped to flags, so default tags still
+// take precedence.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// ErrorDefaulter is like Defaulter, but for default computations that can fail. An error
+// returned by Default is returned by Fill.
+type ErrorDefaulter interface {
+	Default() error
+}
+
+// applyDefaulter invokes the Defaulter or ErrorDefaulter implemented by the given struct value
+func applyDefaulter(structVal reflect.Value) error {
+	if !structVal.CanAddr() || !structVal.Addr().CanInterface() {
+		return nil
+	}
+	switch defaulter := structVal.Addr().Interface().(type) {
+	case Defaulter:
+		defaulter.SetDefaults()
+	case ErrorDefaulter:
+		err := defaulter.Default()
+		if err != nil {
+			return fmt.Errorf("failed to compute defaults of %s: %w", structVal.Type(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package flagsfiller_test
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type defaulterRemote struct {
+	Host    string
+	Workers int
+}
+
+func (r *defaulterRemote) SetDefaults() {
+	r.Host = "computed-host"
+	r.Workers = 4
+}
+
+type defaulterConfig struct {
+	Remote defaulterRemote
+	Name   string `default:"from tag"`
+}
+
+func (c *defaulterConfig) Default() error {
+	c.Name = "computed"
+	return nil
+}
+
+type failingDefaulterConfig struct {
+	Name string
+}
+
+func (c *failingDefaulterConfig) Default() error {
+	return errors.New("detection failed")
+}
+
+func TestDefaulter(t *testing.T) {
+	var config defaulterConfig
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	assert.Equal(t, `
+  -name string
+    	 (default "from tag")
+  -remote-host string
+    	 (default "computed-host")
+  -remote-workers int
+    	 (default 4)
+`, buf.String())
+
+	err = flagset.Parse([]string{"--remote-workers", "8"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "computed-host", config.Remote.Host)
+	assert.Equal(t, 8, config.Remote.Workers)
+	assert.Equal(t, "from tag", config.Name)
+}
+
+func TestErrorDefaulter(t *testing.T) {
+	var config failingDefaulterConfig
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	assert.ErrorContains(t, err, "detection failed")
+}
+
+func TestWithDefaultsProvider(t *testing.T) {
+	type Config struct {
+		Version string
+		Remote  struct {
+			Host string `default:"from tag"`
+			Port int
+		}
+		Other string
+	}
+
+	defaults := map[string]string{
+		"Version":     "1.2.3",
+		"Remote.Host": "ignored since tag",
+		"Remote.Port": "8080",
+	}
+
+	var config Config
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New(flagsfiller.WithDefaultsProvider(func(fieldPath string) (string, bool) {
+		value, exists := defaults[fieldPath]
+		return value, exists
+	})).Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2.3", config.Version)
+	assert.Equal(t, "from tag", config.Remote.Host)
+	assert.Equal(t, 8080, config.Remote.Port)
+	assert.Equal(t, "", config.Other)
+	assert.Equal(t, "8080", flagset.Lookup("remote-port").DefValue)
+}
+
+func TestWithDefaultsFS(t *testing.T) {
+	type Config struct {
+		Host   string
+		Remote struct {
+			MaxTimeout time.Duration
+			Tags       []string
+			Labels     map[string]string
+			Port       int `default:"80"`
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"defaults.yaml": &fstest.MapFile{Data: []byte(`
+host: localhost
+remote:
+  max-timeout: 5s
+  tags: [one, two]
+  labels:
+    env: dev
+    team: core
+  port: 8080
+`)},
+	}
+
+	var config Config
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New(flagsfiller.WithDefaultsFS(fsys, "defaults.yaml")).Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost", config.Host)
+	assert.Equal(t, 5*time.Second, config.Remote.MaxTimeout)
+	assert.Equal(t, []string{"one", "two"}, config.Remote.Tags)
+	assert.Equal(t, map[string]string{"env": "dev", "team": "core"}, config.Remote.Labels)
+	// default tag takes precedence
+	assert.Equal(t, 80, config.Remote.Port)
+
+	err = flagset.Parse([]string{"--host", "h1"})
+	require.NoError(t, err)
+	assert.Equal(t, "h1", config.Host)
+}
+
+func TestWithDefaultsFSMissing(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	var config Config
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New(flagsfiller.WithDefaultsFS(fstest.MapFS{}, "defaults.yaml")).Fill(&flagset, &config)
+	assert.ErrorContains(t, err, "failed to load defaults from defaults.yaml")
+}
@@ -0,0 +1,88 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// deprecatedValue is the flag.Value of a flag declared by the deprecated tag, or a legacy flag
+// name declared by the deprecated-aliases tag, which sets the field's flag and warns about the
+// use of the flag once
+type deprecatedValue struct {
+	flag.Value
+	name string
+	// message is the migration message, such as "use -host instead"
+	message string
+	flagSet *flag.FlagSet
+	once    sync.Once
+}
+
+func (v *deprecatedValue) String() string {
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *deprecatedValue) Set(s string) error {
+	v.once.Do(func() {
+		_, _ = fmt.Fprintf(v.flagSet.Output(), "flag -%s is %s\n", v.name, deprecationNotice(v.message))
+	})
+	return v.Value.Set(s)
+}
+
+// IsBoolFlag retains the handling of boolean flags without a value, such as -verbose
+func (v *deprecatedValue) IsBoolFlag() bool {
+	boolFlag, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// Get implements flag.Getter when the wrapped value does
+func (v *deprecatedValue) Get() interface{} {
+	if getter, ok := v.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return v.Value.String()
+}
+
+func (v *deprecatedValue) unwrap() flag.Value {
+	return v.Value
+}
+
+func (v *deprecatedValue) choices() ([]string, bool) {
+	if choices, ok := v.Value.(choicesValue); ok {
+		return choices.choices()
+	}
+	return nil, false
+}
+
+// deprecationNotice renders the given migration message, which may be empty, as in
+// "deprecated, use -host instead"
+func deprecationNotice(message string) string {
+	if message == "" {
+		return "deprecated"
+	}
+	return "deprecated, " + message
+}
+
+// wrapDeprecated replaces the value of the given flag with a deprecatedValue when the field is
+// declared with the deprecated tag, whose migration message is appended to the flag's usage
+// along with the placeholder name of the wrapped value
+func wrapDeprecated(declared *flag.Flag, flagSet *flag.FlagSet, tag reflect.StructTag) {
+	message, exists := tag.Lookup("deprecated")
+	if !exists {
+		return
+	}
+	if isZeroDefault(declared) {
+		declared.DefValue = ""
+	}
+	declared.Usage = quotePlaceholder(declared.Usage, declared)
+	if declared.Usage == "" {
+		declared.Usage = deprecationNotice(message)
+	} else {
+		declared.Usage = fmt.Sprintf("%s (%s)", declared.Usage, deprecationNotice(message))
+	}
+	declared.Value = &deprecatedValue{Value: declared.Value, name: declared.Name, message: message, flagSet: flagSet}
+}
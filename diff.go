@@ -0,0 +1,57 @@
+package flagsfiller
+
+import (
+	"reflect"
+)
+
+// Change describes the values of a field that differs between two configs. The values of fields
+// declared with the sensitive tag are redacted.
+type Change struct {
+	Old string
+	New string
+}
+
+// Diff compares two instances of the same config struct and returns the fields that differ keyed
+// by their dot-separated path, such as Remote.Auth.Username. This allows for reload handlers and
+// admin endpoints to report exactly what changed.
+// Fields declared with `sensitive:"true"`, or nested within such a field, have their values
+// redacted; however, they are still reported when changed.
+func Diff[T any](before, after *T) map[string]Change {
+	oldValues := collectValues(before)
+	newValues := collectValues(after)
+
+	changes := make(map[string]Change)
+	for path, oldLeaf := range oldValues {
+		newLeaf, exists := newValues[path]
+		if !exists {
+			changes[path] = Change{Old: oldLeaf.display(), New: ""}
+		} else if !reflect.DeepEqual(oldLeaf.value.Interface(), newLeaf.value.Interface()) {
+			changes[path] = Change{Old: oldLeaf.display(), New: newLeaf.display()}
+		}
+	}
+	for path, newLeaf := range newValues {
+		if _, exists := oldValues[path]; !exists {
+			changes[path] = Change{Old: "", New: newLeaf.display()}
+		}
+	}
+	return changes
+}
+
+func collectValues[T any](config *T) map[string]leafValue {
+	values := make(map[string]leafValue)
+	if config == nil {
+		return values
+	}
+	visitLeaves(reflect.ValueOf(config).Elem(), "", false, func(leaf leafValue) {
+		values[leaf.path] = leaf
+	})
+	return values
+}
+
+// display renders the leaf's value with redaction applied
+func (l leafValue) display() string {
+	if l.sensitive {
+		return redacted
+	}
+	return formatValue(l.value)
+}
@@ -0,0 +1,54 @@
+package flagsfiller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type Auth struct {
+		Username string
+		Password string `sensitive:"true"`
+	}
+	type Config struct {
+		Host    string
+		Timeout time.Duration
+		Tags    []string
+		Remote  struct {
+			Auth Auth
+		}
+		Backup  *Auth
+		Secrets map[string]string `sensitive:"true"`
+	}
+
+	old := Config{
+		Host:    "h1",
+		Timeout: 5 * time.Second,
+		Tags:    []string{"one"},
+		Secrets: map[string]string{"a": "1"},
+	}
+	old.Remote.Auth = Auth{Username: "user", Password: "secret"}
+
+	updated := flagsfiller.Clone(&old)
+	updated.Timeout = 10 * time.Second
+	updated.Tags = append(updated.Tags, "two")
+	updated.Remote.Auth.Password = "changed"
+	updated.Backup = &Auth{Username: "backup"}
+	updated.Secrets["a"] = "2"
+
+	changes := flagsfiller.Diff(&old, updated)
+
+	assert.Equal(t, map[string]flagsfiller.Change{
+		"Timeout":              {Old: "5s", New: "10s"},
+		"Tags":                 {Old: "one", New: "one,two"},
+		"Remote.Auth.Password": {Old: "*****", New: "*****"},
+		"Backup.Username":      {Old: "", New: "backup"},
+		"Backup.Password":      {Old: "", New: "*****"},
+		"Secrets":              {Old: "*****", New: "*****"},
+	}, changes)
+
+	assert.Empty(t, flagsfiller.Diff(&old, &old))
+}
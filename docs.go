@@ -37,6 +37,37 @@ as the snippet above in one call:
 
 	flagsfiller.Parse(&config)
 
+MustParse does the same, but prints the error along with the usage and exits when the struct could
+not be filled. ParseArgs and ParseFlagSet instead fill and parse a newly created flag.FlagSet,
+which is returned for further inspection, where ParseArgs parses an explicit argument slice.
+ParseString is like ParseArgs, but splits a single string into arguments like a shell, which is
+useful for options delivered in one environment variable, such as APP_OPTS:
+
+	flagsfiller.ParseString(&config, os.Getenv("APP_OPTS"))
+
+With the WithArgFiles option, an argument of the form @path is replaced by the arguments read
+from the file at path, one per line, where blank lines and lines starting with # are skipped.
+This allows for command lines generated by build systems that exceed the limits of the OS.
+
+# Subcommands
+
+Subcommands are declared by struct, or struct pointer, fields with the `command` tag, whose fields
+become the flags of that command. ParseCommands parses the flags of the root struct from os.Args
+up to the first non-flag argument, which selects the command that is then parsed from the remaining
+arguments:
+
+	type Config struct {
+		Verbose bool
+		Serve   *ServeConfig   `command:"serve" usage:"start the server"`
+		Migrate *MigrateConfig `command:"migrate" usage:"migrate the database"`
+	}
+
+	command, err := flagsfiller.ParseCommands(&config)
+
+The returned Command holds the name of the selected command, such as "serve", and the remaining
+arguments. Pointer fields of the commands that were not selected are left nil. When filling a
+struct with Fill, the command fields are skipped.
+
 # Flag Naming
 
 By default, the flags are named by taking the field name and performing a word-wise conversion
@@ -44,7 +75,15 @@ to kebab-case. For example the field named "MyMultiWordField" becomes the flag n
 "my-multi-word-field".
 
 The naming strategy can be changed by passing a custom Renamer using the WithFieldRenamer
-option in the constructor.
+option in the constructor. For example, passing WithFieldRenamer(DotRenamer()) names the nested
+field Remote.Auth.Username as "remote.auth.username".
+
+The separator placed between the names of nested structs and their fields can be chosen
+independently of the renamer with WithNestedNameSeparator, and likewise for environment variables
+with WithEnvNestedNameSeparator. For example, combining WithNestedNameSeparator(".") and
+WithEnvNestedNameSeparator("__") maps the field Remote.MaxTimeout to the flag "remote.max-timeout"
+and, with WithEnv("App"), the environment variable APP_REMOTE__MAX_TIMEOUT. With a separator, the
+renamers are applied to the name of each level on its own.
 
 Additional aliases, such as short names, can be declared with the `aliases` tag as a comma-separated list:
 
@@ -53,6 +92,18 @@ Additional aliases, such as short names, can be declared with the `aliases` tag
 		Limit   int `aliases:"l,lim"`
 	}
 
+When renaming a flag, the old names can be declared with the `deprecated-aliases` tag, which
+keep working but print a warning pointing at the new name the first time each is used:
+
+	Address string `deprecated-aliases:"host,server"`
+
+A flag that is going away can itself be declared with the `deprecated` tag, whose migration
+message is appended to the flag's usage. The flag keeps working, but setting it prints a warning
+to the flag set's output, which is stderr unless changed by SetOutput or, for the convenience
+functions such as Parse, the WithOutput option:
+
+	Host string `deprecated:"use -address instead"`
+
 # Nested Structs
 
 FlagSetFiller supports nested structs and computes the flag names by prefixing the field
@@ -69,6 +120,10 @@ the flags named remote-host, remote-auth-username, and remote-auth-password:
 		}
 	}
 
+A nested struct pointer that refers to the type of a struct containing it, such as an override
+config of the same type, would be walked forever, so Fill returns an error for it unless the
+field is ignored with the `flag:""` tag.
+
 # Flag Usage
 
 To declare a flag's usage add a `usage:""` tag to the field, such as:
@@ -88,6 +143,21 @@ results in the rendered output:
 	-some-url URL
 		a URL to configure
 
+For CLIs with many flags, fields can be declared with the `advanced:"true"` tag to hide their
+flags from the usage shown by -help. A -help-all flag is then declared, which shows the usage
+including the advanced flags. Like -help, it exits or panics while parsing according to the error
+handling of the flag set; with flag.ContinueOnError, ParseWithSources and the Parse functions
+show the usage and return flag.ErrHelp after parsing.
+
+With the WithGroupedUsage option, the usage lists the flags of nested structs under headings
+derived from the structs' field names, such as "Remote Auth:", after the flags of the top-level
+fields, rather than as one alphabetized list.
+
+For full control of the help formatting, such as adding examples and footer text, the
+WithUsageTemplate option renders the usage with a text/template. The template is given a
+UsageData, whose Flags describe each flag's name, aliases, environment variables, default, usage,
+type, and group. The same descriptions are returned by the FlagInfos method of a FlagSetFiller.
+
 # Defaults
 
 To declare the default value of a flag, you can either set a field's value before passing the
@@ -106,6 +176,23 @@ converted into the field's type. For example,
 		Timeout time.Duration `default:"1m"`
 	}
 
+Defaults that need to be computed, such as the hostname or CPU count, can be provided by having
+the struct, or any nested struct, implement Defaulter or ErrorDefaulter. Those are invoked before
+the struct's fields are mapped to flags, so the computed values are rendered as the flag defaults.
+
+Defaults can also be provided without tags by passing the WithDefaultsProvider option, which is
+consulted by field path, such as "Remote.Port", for fields that do not have a default tag.
+Similarly, the WithDefaultsFS option loads defaults from a YAML or JSON file within an fs.FS, such
+as an embed.FS, where nested keys correspond to nested struct fields.
+
+Defaults that depend on the value of another field can be declared with the `default-if` tag as
+comma-separated Field=value:default entries, where the first one that matches applies. Those are
+applied after parsing by ParseWithSources, as well as Parse and ParseArgs, unless the field was set
+by any source. For example, the following sets Port to 443 when Scheme is https:
+
+	Scheme string `default:"http"`
+	Port   int    `default-if:"Scheme=https:443,Scheme=http:80"`
+
 # String Slices
 
 FlagSetFiller also includes support for []string fields.
@@ -122,6 +209,36 @@ The default tag's value is provided as a comma-separated list, such as
 
 	MultiValues []string `default:"one,two,three"`
 
+A []string field that is not set remains nil. With the WithEmptySlices option, such fields are
+instead set to an empty slice and an empty argument value, such as --arg "", explicitly sets the
+field to an empty slice.
+
+The number of values that can be given for a slice field, such as peers that need to be
+bounded, can be limited with the `max-occurs` tag. Parsing fails when the flag is given more
+times, or with more values, than declared by the tag:
+
+	Peers []string `max-occurs:"3"`
+
+The tag is rejected on other fields, including slices set by a converter, since their flags
+can't count the values given.
+
+Leading and trailing whitespace is trimmed from each element, and blank elements are dropped.
+The WithPreserveWhitespace option keeps the whitespace of elements, which a field can override
+with the `trim:"true"` or `trim:"false"` tag, such as
+
+	Prefixes []string `trim:"false"`
+
+Similarly, the WithEmptyElements option or the `keep-empty:"true"` tag keeps blank elements,
+such that --cols a,,c results in a three element slice where the second element is empty.
+
+The []int and []int64 fields are handled the same way, where each element is parsed as a
+number, such that --ports 80,443 --ports 8080 results in a three element slice:
+
+	Ports []int `default:"80,443"`
+
+Likewise, each element of a []time.Duration field, such as a retry backoff schedule, is parsed by
+time.ParseDuration, such as --backoff 1s,5s,30s.
+
 # Maps of String to String
 
 FlagSetFiller also includes support for map[string]string fields.
@@ -138,6 +255,15 @@ The default tag's value is provided a comma-separate list of key=value entries,
 
 	Mappings map[string]string `default:"k1=v1,k2=v2,k3=v3"`
 
+As with string slices, the whitespace of entries is trimmed unless the WithPreserveWhitespace
+option or the `trim:"false"` tag is given.
+
+Maps with string keys and other value types, such as map[string]int or map[string]time.Duration,
+are handled the same way, where each value is parsed like a field of that type, including the
+registered enums and simple types. For example, --limits read=100,write=50 sets two entries of
+
+	Limits map[string]int
+
 # Other supported types
 
 FlagSetFiller also supports following field types:
@@ -145,8 +271,226 @@ FlagSetFiller also supports following field types:
 - net.IP: format used by net.ParseIP()
 - net.IPNet: format used by net.ParseCIDR()
 - net.HardwareAddr (MAC addr): format used by net.ParseMAC()
-- time.Time: format is the layout string used by time.Parse(), default layout is time.DateTime, could be overriden by field tag "layout"
+- []byte: decoded from the encoding given by the tag "encoding", which is either base64 or hex,
+such as `encoding:"hex"`. []byte fields without the tag are ignored.
+- os.FileMode: parsed and rendered in octal, such as 0644, where 04000, 02000, and 01000 are the
+setuid, setgid, and sticky bits like chmod
+- big.Int and *big.Int: decimal, or another base given by a prefix, such as 0x for hexadecimal
+- big.Float and *big.Float: decimal or hexadecimal floating-point numbers, such as 1e-30 or 0x1p-2,
+parsed with 64 bits of precision
+- url.URL and *url.URL: format used by url.Parse(), where the tag "schemes" optionally restricts the
+accepted schemes to a comma-separated list, such as `schemes:"http,https"`
+- time.Time: format is the layout string used by time.Parse(), default layout is time.DateTime, could be overriden by field tag "layout".
+The keywords "now" and "startofday", optionally followed by a duration such as "now-24h", are
+evaluated when filling the defaults or parsing the flag.
 - slog.Level: parsed as specified by https://pkg.go.dev/log/slog#Level.UnmarshalText, such as "info"
+- int8, int16, int32, uint8, uint16, uint32, and float32: parsed like the wider types, where
+values outside the range of the type are rejected with an error stating the range
+- ByteSize: a number of bytes with an optional unit, such as "512KiB", "10MB", or "1G", where the
+single letter units and the units ending in "iB" are powers of 1024 and the other units ending in
+"B" are powers of 1000. Defaults are rendered in the same form. The `type:"bytes"` tag parses int,
+int64, uint, and uint64 fields the same way, such as
+
+	CacheSize int64 `type:"bytes" default:"512MiB"`
+
+Fields whose type implements flag.Value, with either a value or pointer receiver, are declared
+directly as the flag's value, where a default tag is applied by calling Set. Otherwise, types
+implementing encoding.TextUnmarshaler are parsed by UnmarshalText, and their defaults are shown in
+the usage by MarshalText or String, when implemented, such as "(default 9.9.9.9)" for a netip.Addr.
+
+Types of other libraries are supported by the modules under contrib, which register the types
+when imported, such as
+
+	import _ "github.com/itzg/go-flagsfiller/contrib/zaplevel"
+
+for zapcore.Level and zap.AtomicLevel fields, or contrib/logruslevel for logrus.Level fields.
+
+Custom int or string types with a fixed set of values can be registered with RegisterEnum, which
+takes a map of the names to the values. Only those names are accepted, the usage lists them, and
+values are rendered by name. Similarly, integer types holding bit flags can be registered with
+RegisterBitmask, where a comma-separated list of names, such as --features metrics,tracing, sets
+the corresponding bits.
+
+# Custom converters
+
+The parsing of a specific field can be replaced by passing the WithFieldConverter option with the
+field's dot-separated path, such as "Remote.Timeout". Converters can also be registered by name
+with RegisterNamedConverter and then selected with the `converter:"name"` tag, such as
+
+	BufferSize int64 `converter:"kilobytes"`
+
+where `convert:"kilobytes"` is accepted as a shorter form of the tag.
+
+In either case, the converter's result must be convertible to the field's type.
+
+Types registered package-wide, such as by RegisterSimpleType or RegisterEnum, apply to every
+filler. RegisterSimpleType replaces the field with each parsed value, whereas RegisterFlagValue
+declares the field with a flag.Value that can update it in place, such as for types sharing
+state with their copies. A type can instead be registered for the fields of one filler with
+RegisterType, which takes precedence over the package-wide types and keeps concurrent fillers
+independent, such as
+
+	filler := flagsfiller.New()
+	filler.RegisterType(Endpoint{}, parseEndpoint)
+
+# Interface fields
+
+A field with an interface type can be mapped to a flag that selects an implementation by name after
+registering named factories with RegisterInterfaceFactories. When an implementation is a pointer to
+a struct, its fields are also mapped to flags prefixed with the field and implementation names.
+For example, given factories registered for the names "cloud" and "local", the following field is
+selected by --storage cloud and the cloud implementation's Bucket field by --storage-cloud-bucket:
+
+	Storage StorageBackend `default:"local"`
+
+# Sensitive fields
+
+Fields holding secrets, such as passwords or tokens, can be declared with `sensitive:"true"`, such as
+
+	Token string `sensitive:"true" usage:"API token"`
+
+The default of a sensitive field is shown as "*****" in the usage and its value is redacted in
+the errors of setting it from environment variables, config files, or the default tag, as well as
+by the features below. An invalid command-line argument is likewise redacted in the error returned
+by ParseWithSources and the Parse functions, both in the returned error and in the message that
+flag.FlagSet prints to its output.
+
+# Config snapshots
+
+Clone returns a deep copy of a filled config struct, which allows for taking a snapshot before a
+reload or mutation that can later be compared or rolled back. Diff compares two instances of a
+config struct and reports the changed fields keyed by their path, such as "Remote.Auth.Username".
+The values of fields declared with `sensitive:"true"` are redacted in the reported changes.
+
+SlogAttr converts a config struct into a slog attribute grouped by nested struct, where the
+values of sensitive fields are redacted, so that the effective config can be logged at startup:
+
+	logger.Info("starting", flagsfiller.SlogAttr("config", &config))
+
+EffectiveConfig returns the current, redacted values of a config struct keyed by field path.
+The debugconfig subpackage's PublishExpvar publishes those as an expvar variable and its
+ConfigHandler serves them as JSON, so that operators can inspect the live config on a debug port.
+Rather than the whole config, the NonDefaultValues method of a FlagSetFiller returns only the
+fields that differ from their defaults, such as those set by arguments or environment variables.
+After parsing, its Sources method reports where each field's value came from: the zero value, a
+default tag, the struct as given, a config file, an environment variable, SetFromMap, or the
+command line, which helps with debugging the precedence of sources. WasSet reports if the field
+at a given path was explicitly set rather than left at its default, such as to only override a
+timeout when the user gave one.
+
+Merge overlays one config struct onto another, such as a per-environment config onto a base
+config. A wasSet function can be given to declare which fields explicitly override; otherwise,
+fields that are not the zero value override.
+
+# Reloading configuration
+
+SetFromMap sets flags by name from a map of values, which allows for bridging other config
+systems and reloading the config of a running service. The contrib/k8sconfig module uses that
+to apply the keys of a Kubernetes ConfigMap or Secret mounted as a volume, and to watch the
+volume for config rollouts:
+
+	go k8sconfig.Watch(ctx, filler, flagSet, "/etc/config", 10*time.Second, nil)
+
+Watching the ConfigMap or Secret through the Kubernetes API, rather than a mounted volume, is not
+supported.
+
+# Code generation
+
+For CLIs sensitive to startup time or binary size, the flagsfiller-gen command generates a
+reflection-free equivalent of Fill for a config struct. It is typically invoked by go:generate:
+
+	//go:generate go run github.com/itzg/go-flagsfiller/cmd/flagsfiller-gen -type Config -env App
+
+The generated FillConfig function declares the same flags and environment variables as Fill with
+the default renamers, but supports a subset of the field types and tags, where a field declaring
+an unsupported tag, such as sensitive, fails the generation. The generated ValidateConfig
+function checks the required, min, max, and choices tags after parsing. See the command
+documentation for the details.
+
+# Deployment templates
+
+After filling a flag set, the FlagSetFiller can write templates that stay in sync with the config
+struct. WriteHelmValues writes a values.yaml skeleton for a Helm chart, with nested keys matching
+the nested structs, the flag defaults, and comments from the usage tags. WriteHelmEnv writes the
+corresponding env section of a Deployment's container, which maps those values to the
+environment variables of the fields.
+
+Similarly, WriteEnvFile writes the environment variables of the fields as NAME=default lines
+commented with the usage, which can be installed as the EnvironmentFile of a systemd service.
+WriteComposeEnvironment writes the environment block of a docker-compose service, where the
+output of WriteEnvFile can instead be referenced as an env_file.
+
+WriteManOptions writes the OPTIONS section of a man page in roff, which lists each flag with its
+aliases, usage, default, and environment variables, so that packaged CLIs can ship man pages
+derived from the config struct.
+
+WriteCompletionSpec writes a carapace-spec, from which carapace provides completions for the
+common shells. The names accepted by enum and bitmask fields are completed, and fields declared
+with the `complete:"file"` or `complete:"dir"` tag complete paths. Without carapace, the
+completion package generates the completion scripts for bash, zsh, and fish directly, such as
+with completion.Generate(os.Stdout, "bash", &config).
+
+# Set interceptors
+
+The WithBeforeSet option registers a function that is called with the field path and string
+value before every flag is set, including from environment variables. It can normalize the
+value, such as trimming or lowercasing it, or reject it with an error. The WithAfterSet option
+registers a function that is also given the field's typed value after it was set, which is
+useful for auditing and metrics.
+
+The WithValueResolver option registers a function that is called with every raw value before
+it is converted, including the defaults, so that references to secrets, such as
+"vault:secret/db#password", can be resolved by application code. The function returns values
+that aren't references as is. Fields holding resolved secrets are best declared with
+`sensitive:"true"`, so that their defaults are redacted in the usage.
+
+The WithAuditor option registers a function that is given a record of every value that is set,
+including its source, such as an environment variable or SetFromMap when reloading configuration.
+The old and new values of fields declared with `sensitive:"true"` are redacted. WithAuditLogger
+logs the records with a slog.Logger.
+
+# Filling more than once
+
+By default, Fill returns an error when a flag name or alias is already defined in the flag set.
+The WithConflictStrategy option allows for filling the same flag set again, such as after adding
+fields or when subcommands share a base struct. ConflictSkip keeps the existing flags and
+ConflictRebind updates the existing flags to set the fields being filled.
+
+# Testing
+
+The flagsfillertest package provides helpers for testing config structs, such as Parse that sets
+environment variables for the duration of a test, fills a new flag set, and parses arguments,
+Usage that captures the rendered usage, and AssertValues that checks the resulting field values
+by path.
+
+# Config files
+
+The WithConfigFile option loads a YAML or JSON config file, where nested keys correspond to nested
+struct fields and are matched case-insensitively ignoring dashes and underscores:
+
+	remote:
+	  max-timeout: 5s
+
+Files with a .toml extension, or given by the WithTOML option, are loaded as TOML, where tables
+correspond to nested structs. For any of the formats, when a file has no value at a field's nested
+keys, the flag name is also looked up as a top-level key, which allows for flat files and names
+given by flag tags, such as a top-level remote-max-timeout key in YAML.
+
+A list replaces the value of a slice field, where each element is set as is rather than being
+split by the value split pattern, so that tags: ["a,b", c] sets the two elements "a,b" and "c".
+
+Unlike defaults, the values from config files take precedence over default tags, so the precedence,
+from lowest to highest, is default values, config files, environment variables, and then
+command-line arguments.
+
+# Remote sources
+
+Values can also be set from key-value backends, such as etcd, Consul, or AWS SSM, by implementing
+the RemoteSource interface, whose Lookup method is called with the path of each field, such as
+"Remote.MaxTimeout", and passing it to the WithRemoteSources option. RemoteSourceFunc adapts a
+function. The values from remote sources take precedence over config files, so the precedence,
+from lowest to highest, is default values, config files, remote sources, environment variables,
+and then command-line arguments.
 
 # Environment variable mapping
 
@@ -165,6 +509,26 @@ would render the following usage:
 	-host string
 	  	the host to use (env APP_HOST) (default "localhost")
 
+Environment variables are normally applied during Fill. With the WithDeferredSources option, they
+are instead applied when calling FlagSetFiller.ParseWithSources, just before parsing the
+command-line arguments. The precedence is then: defaults, including values assigned to the struct
+after Fill, then environment variables, and then command-line arguments.
+
+Adding the WithStrictEnv option causes Fill to return an error when an environment variable
+starting with the prefix, such as APP_TIMEOUTT, does not map to any field.
+
+The WithDotEnv option loads KEY=VALUE entries from .env files, which are used as if the environment
+variables were set, but without modifying the process environment. Variables that are actually set
+take precedence over the files.
+
+Secrets are often mounted as files, such as by Docker secrets, where the variable names the file
+instead. The WithEnvFiles option also sets each field from the file at the path given by its
+variable suffixed with _FILE, such as APP_TOKEN_FILE for APP_TOKEN, where trailing newlines are
+trimmed. The variable without the suffix takes precedence. A field can instead name its file
+variables with the `envfile` tag, such as
+
+	Token string `env:"TOKEN" envfile:"TOKEN_FILE" sensitive:"true"`
+
 # Per-field overrides
 
 To override the naming of a flag, the field can be declared with the tag `flag:"name"` where
@@ -182,5 +546,70 @@ per field with `env:"name"` even when the flagsfiller-wide option was not includ
 
 	Host 			string `env:"SERVER_ADDRESS"`
 	NotEnvMapped 	string `env:""`
+
+A nested struct field can declare an `envPrefix:"name"` tag to replace its field name when
+computing the environment variable names of its fields. For example, with WithEnv("App") the
+following maps to APP_DB_HOST and APP_DB_PORT:
+
+	type Config struct {
+		Database struct {
+			Host string
+			Port int
+		} `envPrefix:"DB"`
+	}
+
+Multiple, comma-separated environment variable names can be given, such as `env:"NEW_NAME,OLD_NAME"`,
+where the first one that is set is used. This allows for renaming environment variables without
+breaking existing deployments.
+
+# Validation
+
+Numeric fields can be declared with the `min` and `max` tags, which reject values out of that
+range as they are set from arguments, environment variables, or defaults, such that parsing fails
+with an error naming the flag. The bounds of time.Duration fields are given as durations:
+
+	Port    int           `default:"8080" min:"1" max:"65535"`
+	Timeout time.Duration `min:"1s"`
+
+Similarly, string fields can be declared with the `choices` tag, a comma-separated list of the
+accepted values, which are also listed in the usage:
+
+	Level string `default:"info" choices:"debug,info,warn,error"`
+
+Reusable validations are registered by name with RegisterValidator and attached to fields with the
+`validate` tag, which runs them in order whenever the field is set, as well as by Validate for the
+fields that were not set, so that "nonempty" rejects a field that was never given. The validators
+"nonempty" and "hostport" are built in:
+
+	Address string `default:"localhost:8080" validate:"nonempty,hostport"`
+
+After parsing, ParseWithSources, as well as Parse and ParseArgs, check the constraints declared by
+the fields' tags with Validate, which can also be called after SetFromMap. A field declared with
+`required:"true"` must be set by an argument, environment variable, or config file, where all the
+missing flags are reported together and the usage marks the flag as required. A field declared with
+the `requires` tag can only be set along with the named fields, which are sibling field names or
+full field paths:
+
+	TlsCert string `requires:"TlsKey"`
+	TlsKey  string
+
+A bool field declared with the `enables` tag gates the named fields or nested structs, which can
+only be set when the bool field is true. In the usage, the gated flags are listed in a group
+after the other flags:
+
+	Metrics struct {
+		Enabled bool `enables:"Metrics"`
+		Port    int  `default:"9100"`
+	}
+
+# Tag validation
+
+Misspelled tags, such as `defult:"5s"`, are normally ignored. With the WithStrictTags option, Fill
+returns an error that names the field for any malformed tag, unknown tag key, or invalid tag value.
+Tag keys used by other libraries need to be passed to the option, such as WithStrictTags("json").
+
+The WithRequiredUsage option causes Fill to return an error listing the fields that declare a flag
+without a usage tag. Filling the config struct with that option in a unit test keeps the help
+output complete as fields are added.
 */
 package flagsfiller
@@ -0,0 +1,93 @@
+package flagsfiller
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadDotEnv reads the KEY=VALUE entries of the .env file at path
+func loadDotEnv(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDotEnv(string(content))
+}
+
+// parseDotEnv parses the lines of a .env file, which are KEY=VALUE entries optionally preceded by
+// export. Blank lines and lines starting with # are skipped. Values can be enclosed in double
+// quotes, where \n, \", and \\ are escaped, or in single quotes, which are taken literally.
+// Otherwise, a # preceded by whitespace starts a comment.
+func parseDotEnv(content string) (map[string]string, error) {
+	entries := make(map[string]string)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", i+1)
+		}
+		value, err := parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entries[key] = value
+	}
+	return entries, nil
+}
+
+func parseDotEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	switch quote := value[0]; quote {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote in %s", value)
+		}
+		return value[1 : end+1], nil
+	case '"':
+		var result strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch c := value[i]; {
+			case c == '"':
+				return result.String(), nil
+			case c == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					result.WriteByte('\n')
+				case '"', '\\':
+					result.WriteByte(value[i])
+				default:
+					result.WriteByte('\\')
+					result.WriteByte(value[i])
+				}
+			default:
+				result.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quote in %s", value)
+	default:
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = strings.TrimSpace(value[:comment])
+		}
+		return value, nil
+	}
+}
+
+// lookupEnv looks up the environment variable with the given name, which falls back to the
+// entries loaded from .env files by WithDotEnv
+func (o *fillerOptions) lookupEnv(name string) (string, bool) {
+	if val, exists := os.LookupEnv(name); exists {
+		return val, true
+	}
+	val, exists := o.dotEnv[name]
+	return val, exists
+}
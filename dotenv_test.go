@@ -0,0 +1,79 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDotEnv(t *testing.T) {
+	type Config struct {
+		Host     string
+		Port     int
+		Greeting string
+		Path     string
+		Note     string
+		Remote   struct {
+			Username string
+		}
+	}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(first, []byte(`# local settings
+DOTENV_HOST=example.com
+export DOTENV_PORT=8080
+DOTENV_GREETING="hello\n\"world\""
+DOTENV_PATH='C:\tmp'
+DOTENV_NOTE=plain # comment
+`), 0644))
+	second := filepath.Join(dir, ".env.defaults")
+	require.NoError(t, os.WriteFile(second, []byte("DOTENV_HOST=ignored\nDOTENV_REMOTE_USERNAME=user\n"), 0644))
+	t.Setenv("DOTENV_PORT", "9090")
+
+	var config Config
+	_, err := flagsfiller.ParseArgs(&config, nil, flagsfiller.WithEnv("Dotenv"), flagsfiller.WithDotEnv(first, second))
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", config.Host)
+	assert.Equal(t, 9090, config.Port)
+	assert.Equal(t, "hello\n\"world\"", config.Greeting)
+	assert.Equal(t, `C:\tmp`, config.Path)
+	assert.Equal(t, "plain", config.Note)
+	assert.Equal(t, "user", config.Remote.Username)
+
+	_, exists := os.LookupEnv("DOTENV_HOST")
+	assert.False(t, exists, "process environment is not modified")
+}
+
+func TestWithDotEnvStrict(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("DOTENV_HOST=example.com\nDOTENV_HOTS=typo\n"), 0644))
+
+	var config Config
+	err := flagsfiller.New(flagsfiller.WithEnv("Dotenv"), flagsfiller.WithStrictEnv(), flagsfiller.WithDotEnv(path)).
+		Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	assert.EqualError(t, err, "unknown environment variables with prefix DOTENV_: DOTENV_HOTS")
+}
+
+func TestWithDotEnvInvalid(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("DOTENV_HOST=example.com\nnot an entry\n"), 0644))
+
+	var config Config
+	err := flagsfiller.New(flagsfiller.WithDotEnv(path)).Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	assert.EqualError(t, err, "failed to load env file "+path+": line 2: expected KEY=VALUE")
+}
@@ -0,0 +1,69 @@
+package flagsfiller
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+var byteSliceType = reflect.TypeOf([]byte{})
+
+// binaryEncoding is one of the values of the encoding tag of []byte fields
+type binaryEncoding struct {
+	encode func(src []byte) string
+	decode func(s string) ([]byte, error)
+}
+
+// binaryEncodings are the accepted values of the encoding tag
+var binaryEncodings = map[string]binaryEncoding{
+	"base64": {encode: base64.StdEncoding.EncodeToString, decode: base64.StdEncoding.DecodeString},
+	"hex":    {encode: hex.EncodeToString, decode: hex.DecodeString},
+}
+
+// bytesVar is the flag.Value of []byte fields, which are decoded from and rendered in the
+// encoding given by the field's encoding tag
+type bytesVar struct {
+	val      *[]byte
+	name     string
+	encoding binaryEncoding
+}
+
+func (v *bytesVar) String() string {
+	if v.val == nil {
+		return ""
+	}
+	return v.encoding.encode(*v.val)
+}
+
+func (v *bytesVar) Set(s string) error {
+	decoded, err := v.encoding.decode(s)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", v.name, err)
+	}
+	*v.val = decoded
+	return nil
+}
+
+// Get implements flag.Getter
+func (v *bytesVar) Get() interface{} {
+	return *v.val
+}
+
+func (f *FlagSetFiller) processBytes(fieldRef interface{}, encodingName string, hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string, usage string) error {
+	encoding, known := binaryEncodings[encodingName]
+	if !known {
+		return fmt.Errorf("unknown encoding %q, expected base64 or hex", encodingName)
+	}
+	value := &bytesVar{val: fieldRef.(*[]byte), name: encodingName, encoding: encoding}
+	if hasDefaultTag {
+		err := value.Set(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into []byte: %w", err)
+		}
+	}
+	flagSet.Var(value, renamed, usage)
+	return nil
+}
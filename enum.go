@@ -0,0 +1,97 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RegisterEnum registers the type T, typically a custom int or string type, as an enumeration
+// whose values are given by name. Fields of that type accept only the given names, or their case
+// insensitive equivalent, the usage lists the names, and the values are rendered by name.
+// Like RegisterSimpleType, it should be called in init().
+func RegisterEnum[T comparable](values map[string]T) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	valueNames := make(map[T]string, len(values))
+	// iterate in sorted order so the first name is used for a value that has several names
+	for _, name := range names {
+		if _, exists := valueNames[values[name]]; !exists {
+			valueNames[values[name]] = name
+		}
+	}
+
+	registerHandler(getTypeName(reflect.TypeOf(*new(T))), func(tag reflect.StructTag, fieldRef interface{},
+		hasDefaultTag bool, tagDefault string,
+		flagSet *flag.FlagSet, renamed string,
+		usage string) error {
+
+		val := &enumValue[T]{
+			ref:        fieldRef.(*T),
+			values:     values,
+			names:      names,
+			valueNames: valueNames,
+		}
+		if hasDefaultTag {
+			err := val.Set(tagDefault)
+			if err != nil {
+				return fmt.Errorf("failed to parse default into %T: %w", *new(T), err)
+			}
+		}
+		usage = appendUsage(usage, fmt.Sprintf("(one of %s)", strings.Join(names, ", ")))
+		flagSet.Var(val, renamed, usage)
+		return nil
+	})
+}
+
+type enumValue[T comparable] struct {
+	ref        *T
+	values     map[string]T
+	names      []string
+	valueNames map[T]string
+}
+
+func (v *enumValue[T]) String() string {
+	if v.ref == nil {
+		return ""
+	}
+	if name, exists := v.valueNames[*v.ref]; exists {
+		return name
+	}
+	return fmt.Sprint(*v.ref)
+}
+
+func (v *enumValue[T]) Set(s string) error {
+	if value, exists := v.values[s]; exists {
+		*v.ref = value
+		return nil
+	}
+	for _, name := range v.names {
+		if strings.EqualFold(name, s) {
+			*v.ref = v.values[name]
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(v.names, ", "))
+}
+
+func (v *enumValue[T]) choices() ([]string, bool) {
+	return v.names, false
+}
+
+func (v *enumValue[T]) Get() interface{} {
+	return *v.ref
+}
+
+// appendUsage appends the given note, such as the allowed values, to usage, which may be empty
+func appendUsage(usage string, note string) string {
+	if usage == "" {
+		return note
+	}
+	return usage + " " + note
+}
@@ -0,0 +1,86 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type enumColor int
+
+const (
+	enumRed enumColor = iota + 1
+	enumGreen
+	enumBlue
+)
+
+type enumShape string
+
+func init() {
+	flagsfiller.RegisterEnum(map[string]enumColor{
+		"red":   enumRed,
+		"green": enumGreen,
+		"blue":  enumBlue,
+	})
+	flagsfiller.RegisterEnum(map[string]enumShape{
+		"circle": "CIRCLE",
+		"square": "SQUARE",
+	})
+}
+
+func TestRegisterEnum(t *testing.T) {
+	type Config struct {
+		Color    enumColor `default:"green" usage:"the color"`
+		Shape    enumShape
+		Instance enumColor
+	}
+
+	config := Config{Instance: enumBlue}
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New().Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, enumGreen, config.Color)
+	assert.Equal(t, `
+  -color value
+    	the color (one of blue, green, red) (default green)
+  -instance value
+    	(one of blue, green, red) (default blue)
+  -shape value
+    	(one of circle, square)
+`, grabUsage(*flagset).String())
+
+	err = flagset.Parse([]string{"--color", "Red", "--shape", "square"})
+	require.NoError(t, err)
+
+	assert.Equal(t, enumRed, config.Color)
+	assert.Equal(t, enumShape("SQUARE"), config.Shape)
+	assert.Equal(t, "red", flagset.Lookup("color").Value.String())
+}
+
+func TestRegisterEnumInvalid(t *testing.T) {
+	type Config struct {
+		Color enumColor
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+	err := flagset.Parse([]string{"--color", "purple"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be one of blue, green, red")
+
+	type BadDefault struct {
+		Color enumColor `default:"purple"`
+	}
+	var badDefault BadDefault
+	err = flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &badDefault)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be one of blue, green, red")
+}
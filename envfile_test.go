@@ -0,0 +1,88 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEnvFiles(t *testing.T) {
+	type Config struct {
+		Token    string
+		Password string
+		Port     int
+	}
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0600))
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("ignored\n"), 0600))
+	t.Setenv("SECRETS_TOKEN_FILE", tokenFile)
+	t.Setenv("SECRETS_PASSWORD", "from env")
+	t.Setenv("SECRETS_PASSWORD_FILE", passwordFile)
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("Secrets"), flagsfiller.WithEnvFiles(), flagsfiller.WithStrictEnv())
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "s3cr3t", config.Token)
+	// the variable without the suffix takes precedence
+	assert.Equal(t, "from env", config.Password)
+	assert.Equal(t, 0, config.Port)
+	assert.Equal(t, flagsfiller.SourceEnv, filler.Sources(flagset)["Token"])
+}
+
+func TestEnvFileTag(t *testing.T) {
+	type Config struct {
+		Token string `env:"API_TOKEN" envfile:"API_TOKEN_PATH"`
+		Host  string `env:"API_HOST"`
+	}
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("from file\r\n"), 0600))
+	t.Setenv("API_TOKEN_PATH", tokenFile)
+	t.Setenv("API_HOST_FILE", tokenFile)
+
+	var config Config
+	err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "from file", config.Token)
+	// _FILE variables are only used with WithEnvFiles
+	assert.Equal(t, "", config.Host)
+}
+
+func TestEnvFileErrors(t *testing.T) {
+	type Config struct {
+		Pin int16 `env:"PIN" envfile:"PIN_FILE" sensitive:"true"`
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("PIN_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to read file of environment variable PIN_FILE")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		pinFile := filepath.Join(t.TempDir(), "pin")
+		require.NoError(t, os.WriteFile(pinFile, []byte("12ab34\n"), 0600))
+		t.Setenv("PIN_FILE", pinFile)
+
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to set from file "+pinFile+" of environment variable PIN_FILE")
+		assert.NotContains(t, err.Error(), "12ab34")
+	})
+}
@@ -0,0 +1,98 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerHandler(getTypeName(reflect.TypeOf(fs.FileMode(0))), processFileMode)
+}
+
+// fileModeVar is the flag.Value of os.FileMode fields, which are parsed and rendered in octal
+type fileModeVar struct {
+	val *fs.FileMode
+}
+
+func (v *fileModeVar) String() string {
+	if v.val == nil {
+		return ""
+	}
+	return fmt.Sprintf("%#o", formatFileMode(*v.val))
+}
+
+func (v *fileModeVar) Set(s string) error {
+	mode, err := parseFileMode(s)
+	if err != nil {
+		return err
+	}
+	*v.val = mode
+	return nil
+}
+
+// Get implements flag.Getter
+func (v *fileModeVar) Get() interface{} {
+	return *v.val
+}
+
+// specialModeBits maps the octal bits of the setuid, setgid, and sticky modes, as given to chmod,
+// to the corresponding bits of fs.FileMode
+var specialModeBits = []struct {
+	octal uint64
+	mode  fs.FileMode
+}{
+	{octal: 04000, mode: fs.ModeSetuid},
+	{octal: 02000, mode: fs.ModeSetgid},
+	{octal: 01000, mode: fs.ModeSticky},
+}
+
+// parseFileMode parses s as an octal number, such as 0644, 644, 0o644, or 4755 to include the
+// setuid, setgid, and sticky bits like chmod
+func parseFileMode(s string) (fs.FileMode, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O")
+	octal, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil || octal > 07777 {
+		return 0, fmt.Errorf("%s is not a valid octal file mode", s)
+	}
+	mode := fs.FileMode(octal) & fs.ModePerm
+	for _, special := range specialModeBits {
+		if octal&special.octal != 0 {
+			mode |= special.mode
+		}
+	}
+	return mode, nil
+}
+
+// formatFileMode returns the octal number of mode like parseFileMode accepts
+func formatFileMode(mode fs.FileMode) uint64 {
+	octal := uint64(mode & fs.ModePerm)
+	for _, special := range specialModeBits {
+		if mode&special.mode != 0 {
+			octal |= special.octal
+		}
+	}
+	return octal
+}
+
+func processFileMode(_ reflect.StructTag, fieldRef interface{},
+	hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string,
+	usage string) error {
+	value := &fileModeVar{val: fieldRef.(*fs.FileMode)}
+	if hasDefaultTag {
+		err := value.Set(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into os.FileMode: %w", err)
+		}
+	}
+	flagSet.Var(value, renamed, usage)
+	if *value.val == 0 {
+		// like the other numeric types, zero defaults are omitted by flag.PrintDefaults
+		flagSet.Lookup(renamed).DefValue = ""
+	}
+	return nil
+}
@@ -2,11 +2,14 @@ package flagsfiller
 
 import (
 	"encoding"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,31 +19,167 @@ var (
 	durationType          = reflect.TypeOf(time.Duration(0))
 	stringSliceType       = reflect.TypeOf([]string{})
 	stringToStringMapType = reflect.TypeOf(map[string]string{})
+	textUnmarshalerIface  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	// parsedSliceTypes are the slice types handled by processParsedSlice
+	parsedSliceTypes = map[reflect.Type]bool{
+		reflect.TypeOf([]int{}):           true,
+		reflect.TypeOf([]int64{}):         true,
+		reflect.TypeOf([]time.Duration{}): true,
+	}
 )
 
 // FlagSetFiller is used to map the fields of a struct into flags of a flag.FlagSet
 type FlagSetFiller struct {
 	options *fillerOptions
+	// envNames tracks the environment variable names mapped to fields
+	envNames map[string]bool
+	// envBindings are applied by ParseWithSources when WithDeferredSources is used
+	envBindings []envBinding
+	// flagPaths maps the names of defined flags to the paths of their fields
+	flagPaths map[string]string
+	// fieldFlags maps the paths of fields to the names of their flags
+	fieldFlags map[string]string
+	// fields maps the paths of fields that declared a flag to their details
+	fields map[string]Field
+	// paths are the paths of fields that declared a flag, in declaration order
+	paths []string
+	// fieldEnvs maps the paths of fields to the names of their environment variables
+	fieldEnvs map[string][]string
+	// fieldDefaults maps the paths of fields to their default values, rendered by formatValue
+	fieldDefaults map[string]string
+	// fieldSources tracks where the values of fields came from, other than command-line
+	// arguments, which are instead visited in the flag set
+	fieldSources map[string]Source
+	// flagSets are the flag sets given to Fill, which are visited for the flags set by arguments
+	flagSets []*flag.FlagSet
+	// requirements are declared by the requires tag and checked by Validate
+	requirements []requirement
+	// requiredPaths are the paths of the fields declared with the required tag
+	requiredPaths []string
+	// validatedPaths are the paths of the fields declared with the validate tag
+	validatedPaths []string
+	// conditionalDefaults are declared by the default-if tag and applied after parsing
+	conditionalDefaults []conditionalDefault
+	// gates are declared by the enables tag and checked by Validate
+	gates []gate
+	// sensitiveFlags are the names of the flags declared with the sensitive tag, whose values are
+	// redacted from errors
+	sensitiveFlags map[string]bool
+	// advancedFlags are the names of the flags declared with the advanced tag
+	advancedFlags map[string]bool
+	// helpAll is set by the help-all flag, which is declared when there are advanced flags, to
+	// include them in the usage
+	helpAll bool
+	// source is the source of the values currently being set, which is reported in audit records
+	source Source
+	// missingUsage tracks the paths of fields without a usage tag for WithRequiredUsage
+	missingUsage []string
+	// types are registered by RegisterType and take precedence over the package-level types
+	types map[string]handlerFunc
+	// walkingTypes counts the struct types being walked, which detects recursive struct pointers
+	walkingTypes map[reflect.Type]int
+	// walkingStructs are the addresses of the structs being walked, which detects pointer cycles
+	walkingStructs map[walkedStruct]bool
+}
+
+type walkedStruct struct {
+	t    reflect.Type
+	addr uintptr
 }
 
 // Parse is a convenience function that creates a FlagSetFiller with the given options,
 // fills and maps the flags from the given struct reference into flag.CommandLine, and uses
-// flag.Parse to parse the os.Args.
-// Returns an error if the given struct could not be used for filling flags.
+// ParseWithSources to parse the os.Args.
+// The WithErrorHandling and WithOutput options can be given to re-initialize the error handling
+// and output of flag.CommandLine.
+// Returns an error if the given struct could not be used for filling flags or, when the error
+// handling is flag.ContinueOnError, if the arguments could not be parsed.
 func Parse(from interface{}, options ...FillerOption) error {
 	filler := New(options...)
+	if filler.options.errorHandling != nil {
+		flag.CommandLine.Init(os.Args[0], *filler.options.errorHandling)
+	}
+	if filler.options.output != nil {
+		flag.CommandLine.SetOutput(filler.options.output)
+	}
 	err := filler.Fill(flag.CommandLine, from)
 	if err != nil {
 		return err
 	}
 
-	flag.Parse()
-	return nil
+	return filler.ParseWithSources(flag.CommandLine, os.Args[1:])
+}
+
+// MustParse is a convenience function like Parse; however, if the given struct could not be used
+// for filling flags or a source, such as an environment variable, could not be applied, then the
+// error is printed along with the usage and the program exits with status 2.
+// Argument parsing errors are handled according to the error handling of flag.CommandLine, which
+// exits with status 2 by default.
+func MustParse(from interface{}, options ...FillerOption) {
+	err := Parse(from, options...)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		fmt.Fprintln(flag.CommandLine.Output(), err)
+		flag.CommandLine.Usage()
+		os.Exit(2)
+	}
+}
+
+// ParseArgs is a convenience function like Parse; however, it fills a newly created flag.FlagSet
+// and parses the given args rather than os.Args. Unless the WithErrorHandling option is given,
+// the flag.FlagSet is created with flag.ContinueOnError, so parsing errors are returned along
+// with the flag.FlagSet.
+// This is useful for testing and for embedding, such as in REPLs and job runners.
+func ParseArgs(from interface{}, args []string, options ...FillerOption) (*flag.FlagSet, error) {
+	filler := New(options...)
+	flagSet := filler.newFlagSet(os.Args[0])
+	err := filler.Fill(flagSet, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return flagSet, filler.ParseWithSources(flagSet, args)
+}
+
+// ParseFlagSet is a convenience function like Parse; however, rather than using
+// flag.CommandLine, it fills and parses a newly created flag.FlagSet that is returned to allow
+// for inspecting Args, NFlag, etc. Like flag.CommandLine, the flag.FlagSet is created with
+// flag.ExitOnError unless the WithErrorHandling option is given.
+func ParseFlagSet(from interface{}, options ...FillerOption) (*flag.FlagSet, error) {
+	return ParseArgs(from, os.Args[1:],
+		append([]FillerOption{WithErrorHandling(flag.ExitOnError)}, options...)...)
+}
+
+// newFlagSet creates a flag.FlagSet that uses the error handling and output options
+func (f *FlagSetFiller) newFlagSet(name string) *flag.FlagSet {
+	errorHandling := flag.ContinueOnError
+	if f.options.errorHandling != nil {
+		errorHandling = *f.options.errorHandling
+	}
+	flagSet := flag.NewFlagSet(name, errorHandling)
+	if f.options.output != nil {
+		flagSet.SetOutput(f.options.output)
+	}
+	return flagSet
 }
 
 // New creates a new FlagSetFiller with zero or more of the given FillerOption's
 func New(options ...FillerOption) *FlagSetFiller {
-	return &FlagSetFiller{options: newFillerOptions(options...)}
+	return &FlagSetFiller{
+		options:    newFillerOptions(options...),
+		envNames:   make(map[string]bool),
+		flagPaths:  make(map[string]string),
+		fieldFlags: make(map[string]string),
+		fields:     make(map[string]Field),
+		fieldEnvs:  make(map[string][]string),
+		source:     SourceArgs,
+
+		fieldDefaults:  make(map[string]string),
+		fieldSources:   make(map[string]Source),
+		walkingTypes:   make(map[reflect.Type]int),
+		walkingStructs: make(map[walkedStruct]bool),
+	}
 }
 
 // Fill populates the flagSet with a flag for each field in given struct passed in the 'from'
@@ -50,42 +189,147 @@ func New(options ...FillerOption) *FlagSetFiller {
 func (f *FlagSetFiller) Fill(flagSet *flag.FlagSet, from interface{}) error {
 	v := reflect.ValueOf(from)
 	t := v.Type()
+	if f.options.err != nil {
+		return f.options.err
+	}
 	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
-		return f.walkFields(flagSet, "", v.Elem(), t.Elem())
+		if !slices.Contains(f.flagSets, flagSet) {
+			f.flagSets = append(f.flagSets, flagSet)
+		}
+		err := f.walkFields(flagSet, nil, nil, "", v.Elem(), t.Elem())
+		if err != nil {
+			return err
+		}
+		err = f.checkUnknownEnv()
+		if err != nil {
+			return err
+		}
+		err = f.resolveRequirements()
+		if err != nil {
+			return err
+		}
+		err = f.resolveConditionalDefaults()
+		if err != nil {
+			return err
+		}
+		err = f.resolveGates()
+		if err != nil {
+			return err
+		}
+		f.declareHelpAll(flagSet)
+		return f.checkMissingUsage()
 	} else {
 		return fmt.Errorf("can only fill from struct pointer, but it was %s", t.Kind())
 	}
 }
 
-func isSupportedStruct(in any) bool {
-	t := reflect.TypeOf(in)
-	_, ok := extendedTypes[getTypeName(t)]
-	if ok {
-		return true
+// FlagNameFor returns the name of the flag declared by Fill for the field at the given path, such
+// as "Remote.Auth.Username", after applying the renamers and any flag tag. The second return
+// value is false if no flag was declared for that path.
+func (f *FlagSetFiller) FlagNameFor(fieldPath string) (string, bool) {
+	name, ok := f.fieldFlags[fieldPath]
+	return name, ok
+}
+
+// Field describes a struct field that declared a flag
+type Field struct {
+	// Path is the dot-separated path of the field, such as "Remote.Auth.Username"
+	Path string
+	// StructField is the reflection info of the field
+	StructField reflect.StructField
+	// Value is a pointer to the field's value
+	Value interface{}
+}
+
+// FieldFor returns the struct field that declared the flag with the given name or alias, which
+// allows for getting and setting configuration by flag name. The second return value is false if
+// no field declared that flag.
+func (f *FlagSetFiller) FieldFor(flagName string) (Field, bool) {
+	path, ok := f.flagPaths[flagName]
+	if !ok {
+		return Field{}, false
 	}
-	if t.Kind() != reflect.Pointer {
-		val := reflect.ValueOf(in)
-		t = val.Addr().Type()
+	field, ok := f.fields[path]
+	return field, ok
+}
+
+// checkUnknownEnv reports environment variables that have the WithEnv prefix but were not
+// mapped to any field, when the WithStrictEnv option is enabled
+func (f *FlagSetFiller) checkUnknownEnv() error {
+	if !f.options.strictEnv || f.options.noSetFromEnv || f.options.envPrefix == "" {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		names[name] = true
+	}
+	for name := range f.options.dotEnv {
+		names[name] = true
+	}
+	var unknown []string
+	for name := range names {
+		if strings.HasPrefix(name, f.options.envPrefix) && !f.envNames[name] {
+			unknown = append(unknown, name)
+		}
 	}
-	if t.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
-		RegisterTextUnmarshaler(in)
-		return true
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown environment variables with prefix %s: %s",
+			f.options.envPrefix, strings.Join(unknown, ", "))
 	}
-	return false
+	return nil
+}
+
+// checkMissingUsage reports the fields that declared a flag without a usage tag, when the
+// WithRequiredUsage option is enabled
+func (f *FlagSetFiller) checkMissingUsage() error {
+	if len(f.missingUsage) == 0 {
+		return nil
+	}
+	return fmt.Errorf("fields are missing a usage tag: %s", strings.Join(f.missingUsage, ", "))
 }
 
-func getTypeName(t reflect.Type) string {
-	if t.Kind() == reflect.Pointer {
-		t = t.Elem()
+func (f *FlagSetFiller) isSupportedStruct(in any) bool {
+	t := reflect.TypeOf(in)
+	if t.Kind() != reflect.Pointer {
+		t = reflect.PointerTo(t)
 	}
-	return fmt.Sprint(t)
+	return f.handlerFor(t) != nil
 }
 
-func (f *FlagSetFiller) walkFields(flagSet *flag.FlagSet, prefix string,
+// walkFields declares the flags of the fields of the given struct, where levels and envLevels are
+// the names of the enclosing struct fields from which the flag and environment variable names
+// are derived
+func (f *FlagSetFiller) walkFields(flagSet *flag.FlagSet, levels []string, envLevels []string, pathPrefix string,
 	structVal reflect.Value, structType reflect.Type) error {
 
-	if prefix != "" {
-		prefix += "-"
+	f.walkingTypes[structType]++
+	defer func() {
+		f.walkingTypes[structType]--
+	}()
+	if structVal.CanAddr() {
+		walked := walkedStruct{t: structType, addr: structVal.Addr().Pointer()}
+		f.walkingStructs[walked] = true
+		defer delete(f.walkingStructs, walked)
+	}
+
+	err := applyDefaulter(structVal)
+	if err != nil {
+		return err
+	}
+
+	if pathPrefix != "" {
+		pathPrefix += "."
+	}
+	// nestedEnvPrefix allows a nested struct field to replace its name in the environment
+	// variable names of its fields via the envPrefix tag
+	nestedEnvPrefix := func(field fieldSchema) []string {
+		if field.hasEnvPrefix {
+			return appendLevel(envLevels, field.envPrefix)
+		}
+		return appendLevel(envLevels, field.Name)
 	}
 	handleDefault := func(field reflect.StructField, fieldValue reflect.Value) error {
 		addr := fieldValue.Addr()
@@ -95,36 +339,53 @@ func (f *FlagSetFiller) walkFields(flagSet *flag.FlagSet, prefix string,
 			ftype = field.Type.Elem()
 		}
 		if addr.CanInterface() {
-			err := f.processField(flagSet, addr.Interface(), prefix+field.Name, ftype, field.Tag)
+			path := pathPrefix + field.Name
+			err := f.processField(flagSet, addr.Interface(), appendLevel(levels, field.Name), appendLevel(envLevels, field.Name), path, ftype, field)
 			if err != nil {
 				return fmt.Errorf("failed to process %s of %s: %w", field.Name, structType.String(), err)
 			}
+			if _, declared := f.fieldFlags[path]; declared {
+				if _, exists := f.fields[path]; !exists {
+					f.paths = append(f.paths, path)
+				}
+				f.fields[path] = Field{Path: path, StructField: field, Value: addr.Interface()}
+			}
 		}
 		return nil
 	}
-	for i := 0; i < structVal.NumField(); i++ {
-		field := structType.Field(i)
+	schema := schemaFor(structType)
+	for i, field := range schema.fields {
 		fieldValue := structVal.Field(i)
 
-		if flagTag, ok := field.Tag.Lookup("flag"); ok {
-			if flagTag == "" {
-				continue
+		if f.options.strictTags {
+			err := validateTags(pathPrefix+field.Name, field.StructField, f.options.allowedTags)
+			if err != nil {
+				return err
 			}
 		}
 
+		if field.ignored || field.isCommand {
+			continue
+		}
+
+		_, hasFieldConverter := f.options.fieldConverters[pathPrefix+field.Name]
+		if field.hasConverterTag {
+			hasFieldConverter = true
+		}
+
 		switch field.Type.Kind() {
 		case reflect.Struct:
 			// fieldTypeName := getTypeName(field.Type)
 			if field.IsExported() {
-				if isSupportedStruct(fieldValue.Addr().Interface()) {
-					err := handleDefault(field, fieldValue)
+				if hasFieldConverter || f.isSupportedStruct(fieldValue.Addr().Interface()) {
+					err := handleDefault(field.StructField, fieldValue)
 					if err != nil {
 						return err
 					}
 					continue
 				}
 			}
-			err := f.walkFields(flagSet, prefix+field.Name, fieldValue, field.Type)
+			err := f.walkFields(flagSet, appendLevel(levels, field.Name), nestedEnvPrefix(field), pathPrefix+field.Name, fieldValue, field.Type)
 			if err != nil {
 				return fmt.Errorf("failed to process %s of %s: %w", field.Name, structType.String(), err)
 			}
@@ -132,13 +393,17 @@ func (f *FlagSetFiller) walkFields(flagSet *flag.FlagSet, prefix string,
 		case reflect.Ptr:
 			if fieldValue.CanSet() && field.Type.Elem().Kind() == reflect.Struct {
 				// fieldTypeName := getTypeName(field.Type.Elem())
+				err := f.checkRecursion(field, fieldValue, hasFieldConverter, pathPrefix)
+				if err != nil {
+					return err
+				}
 				// fill the pointer with a new struct of their type if it is nil
 				if fieldValue.IsNil() {
 					fieldValue.Set(reflect.New(field.Type.Elem()))
 				}
 				if field.IsExported() {
-					if isSupportedStruct(fieldValue.Interface()) {
-						err := handleDefault(field, fieldValue.Elem())
+					if hasFieldConverter || f.isSupportedStruct(fieldValue.Interface()) {
+						err := handleDefault(field.StructField, fieldValue.Elem())
 						if err != nil {
 							return err
 						}
@@ -146,14 +411,14 @@ func (f *FlagSetFiller) walkFields(flagSet *flag.FlagSet, prefix string,
 					}
 				}
 
-				err := f.walkFields(flagSet, field.Name, fieldValue.Elem(), field.Type.Elem())
+				err = f.walkFields(flagSet, []string{field.Name}, nestedEnvPrefix(field), pathPrefix+field.Name, fieldValue.Elem(), field.Type.Elem())
 				if err != nil {
 					return fmt.Errorf("failed to process %s of %s: %w", field.Name, structType.String(), err)
 				}
 			}
 
 		default:
-			err := handleDefault(field, fieldValue)
+			err := handleDefault(field.StructField, fieldValue)
 			if err != nil {
 				return err
 			}
@@ -163,28 +428,98 @@ func (f *FlagSetFiller) walkFields(flagSet *flag.FlagSet, prefix string,
 	return nil
 }
 
+// checkRecursion returns an error when walking into the struct referenced by a pointer field
+// would not terminate, which is when the field is nil and refers to the type of a struct being
+// walked, such that a new struct would be allocated at every level, or when the field points at
+// a struct being walked
+func (f *FlagSetFiller) checkRecursion(field fieldSchema, fieldValue reflect.Value, hasFieldConverter bool, pathPrefix string) error {
+	elemType := field.Type.Elem()
+	if f.walkingTypes[elemType] == 0 || hasFieldConverter || f.isSupportedStruct(fieldValue.Interface()) {
+		return nil
+	}
+	if fieldValue.IsNil() {
+		return fmt.Errorf("field %s%s recursively refers to %s, which can be ignored with the tag flag:\"\"",
+			pathPrefix, field.Name, elemType)
+	}
+	if f.walkingStructs[walkedStruct{t: elemType, addr: fieldValue.Pointer()}] {
+		return fmt.Errorf("field %s%s points at a %s that contains it, which can be ignored with the tag flag:\"\"",
+			pathPrefix, field.Name, elemType)
+	}
+	return nil
+}
+
 func (f *FlagSetFiller) processField(flagSet *flag.FlagSet, fieldRef interface{},
-	name string, t reflect.Type, tag reflect.StructTag) (err error) {
+	levels []string, envLevels []string, path string, t reflect.Type, field reflect.StructField) (err error) {
+	tag := field.Tag
 
-	var envName string
+	var envNames []string
 	if override, exists := tag.Lookup("env"); exists {
-		envName = override
-	} else if len(f.options.envRenamer) > 0 {
-		envName = name
-		for _, renamer := range f.options.envRenamer {
-			envName = renamer(envName)
+		if override != "" {
+			envNames = strings.Split(override, ",")
+		}
+	} else {
+		var envName string
+		if len(f.options.envRenamer) > 0 {
+			envName = f.options.renameEnvLevels(envLevels)
 		}
+		if f.options.envNameMapper != nil {
+			envName = f.options.envNameMapper(path, envName)
+		}
+		if envName != "" {
+			envNames = strings.Split(envName, ",")
+		}
+	}
+	var envFileNames []string
+	if override, exists := tag.Lookup("envfile"); exists {
+		if override != "" {
+			envFileNames = strings.Split(override, ",")
+		}
+	} else if f.options.envFiles {
+		for _, envName := range envNames {
+			envFileNames = append(envFileNames, envName+"_FILE")
+		}
+	}
+	for _, envName := range envNames {
+		f.envNames[envName] = true
+	}
+	for _, envName := range envFileNames {
+		f.envNames[envName] = true
 	}
 
 	aliases := tag.Get("aliases")
 	usage := requoteUsage(tag.Get("usage"))
-	if envName != "" {
-		usage = fmt.Sprintf("%s (env %s)", usage, envName)
+	if len(envNames) > 0 {
+		usage = fmt.Sprintf("%s (env %s)", usage, strings.Join(envNames, ", "))
+	}
+	required, _ := strconv.ParseBool(tag.Get("required"))
+	if required {
+		usage += " (required)"
 	}
 
 	tagDefault, hasDefaultTag := tag.Lookup("default")
+	for _, provider := range f.options.defaultsProviders {
+		if hasDefaultTag {
+			break
+		}
+		tagDefault, hasDefaultTag = provider(path)
+	}
+
+	if hasDefaultTag {
+		tagDefault, err = f.resolveValue(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to resolve default of field %s: %w", path, err)
+		}
+	}
+
+	origin := SourceZero
+	if hasDefaultTag {
+		origin = SourceDefault
+	} else if !reflect.ValueOf(fieldRef).Elem().IsZero() {
+		origin = SourceStruct
+	}
 
 	fieldType, _ := tag.Lookup("type")
+	encodingName, hasEncodingTag := tag.Lookup("encoding")
 
 	var renamed string
 	if override, exists := tag.Lookup("flag"); exists {
@@ -194,93 +529,583 @@ func (f *FlagSetFiller) processField(flagSet *flag.FlagSet, fieldRef interface{}
 		}
 		renamed = override
 	} else {
-		renamed = f.options.renameLongName(name)
+		renamed = f.options.renameLevels(levels)
+	}
+	if f.options.dashUnderscore {
+		aliases = addDashUnderscoreAliases(renamed, aliases)
+	}
+	// target is the flag set where the field's flags are declared, which is a temporary one
+	// when rebinding existing flags
+	target := flagSet
+	if flagSet.Lookup(renamed) != nil {
+		switch f.options.conflictStrategy {
+		case ConflictSkip:
+			return nil
+		case ConflictRebind:
+			target = flag.NewFlagSet(renamed, flag.ContinueOnError)
+		default:
+			return f.checkFlagName(flagSet, "flag", renamed, path)
+		}
+	}
+	var aliasNames []string
+	if aliases != "" {
+		aliasNames = strings.Split(aliases, ",")
+	}
+	deprecated := make(map[string]bool)
+	if deprecatedAliases := tag.Get("deprecated-aliases"); deprecatedAliases != "" {
+		for _, alias := range strings.Split(deprecatedAliases, ",") {
+			deprecated[alias] = true
+			aliasNames = append(aliasNames, alias)
+		}
+	}
+	declared := map[string]bool{renamed: true}
+	for i := 0; i < len(aliasNames); i++ {
+		alias := aliasNames[i]
+		if declared[alias] {
+			return fmt.Errorf("alias %s of field %s is declared more than once", alias, path)
+		}
+		declared[alias] = true
+		if flagSet.Lookup(alias) != nil {
+			switch f.options.conflictStrategy {
+			case ConflictSkip:
+				aliasNames = append(aliasNames[:i], aliasNames[i+1:]...)
+				i--
+			case ConflictRebind:
+				target = flag.NewFlagSet(renamed, flag.ContinueOnError)
+			default:
+				return f.checkFlagName(flagSet, "alias", alias, path)
+			}
+		}
 	}
 	// go through all supported structs
-	if isSupportedStruct(fieldRef) {
-		handler := extendedTypes[getTypeName(t)]
-		err = handler(tag, fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+	converter, err := f.lookupConverter(path, tag)
+	if err != nil {
 		return err
 	}
 
+	handler := f.handlerFor(reflect.TypeOf(fieldRef))
+	if _, exists := tag.Lookup("max-occurs"); exists {
+		isSlice := t == stringSliceType || fieldType == "stringSlice" || parsedSliceTypes[t]
+		if converter != nil || handler != nil || !isSlice {
+			return fmt.Errorf("max-occurs tag of field %s only applies to []string, []int, []int64, and []time.Duration fields", path)
+		}
+	}
 	switch {
+	case converter != nil:
+		err = f.processCustom(fieldRef, converter, hasDefaultTag, tagDefault, target, renamed, usage)
+
+	case handler != nil:
+		err = handler(tag, fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
+
+	case t.Kind() == reflect.Interface && factoriesFor(t) != nil:
+		err = f.processInterface(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage,
+			levels, envLevels, path, t)
+
+	case fieldType == "bytes":
+		if !isByteSizeKind(t.Kind()) {
+			return fmt.Errorf("bytes type of field %s only applies to int, int64, uint, and uint64 fields", path)
+		}
+		err = processByteSize(tag, fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
+
 	case t.Kind() == reflect.String:
-		f.processString(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processString(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	case t.Kind() == reflect.Bool:
-		err = f.processBool(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processBool(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	case t.Kind() == reflect.Float64:
-		err = f.processFloat64(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processFloat64(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	// NOTE check time.Duration before int64 since it is aliasesed from int64
 	case t == durationType, fieldType == "duration":
-		err = f.processDuration(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processDuration(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	case t.Kind() == reflect.Int64:
-		err = f.processInt64(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processInt64(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	case t.Kind() == reflect.Int:
-		err = f.processInt(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processInt(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	case t.Kind() == reflect.Uint64:
-		err = f.processUint64(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processUint64(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
 	case t.Kind() == reflect.Uint:
-		err = f.processUint(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		err = f.processUint(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
 
-	case t == stringSliceType, fieldType == "stringSlice":
+	case isNarrowNumber(t.Kind()):
+		err = f.processNarrowNumber(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage)
+
+	case t == byteSliceType && hasEncodingTag:
+		err = f.processBytes(fieldRef, encodingName, hasDefaultTag, tagDefault, target, renamed, usage)
+
+	case t == stringSliceType, fieldType == "stringSlice", parsedSliceTypes[t]:
+		var parsing valueParsing
+		parsing, err = f.valueParsing(tag, path)
+		if err != nil {
+			return err
+		}
 		var override bool
 		if overrideValue, exists := tag.Lookup("override-value"); exists {
 			if value, err := strconv.ParseBool(overrideValue); err == nil {
 				override = value
 			}
 		}
-		f.processStringSlice(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, override, aliases)
+		var maxOccurs int
+		if maxOccursValue, exists := tag.Lookup("max-occurs"); exists {
+			maxOccurs, err = strconv.Atoi(maxOccursValue)
+			if err != nil || maxOccurs < 1 {
+				return fmt.Errorf("invalid max-occurs tag %q of field %s", maxOccursValue, path)
+			}
+		}
+		if parsedSliceTypes[t] {
+			err = f.processParsedSlice(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage, parsing, override, maxOccurs)
+		} else {
+			err = f.processStringSlice(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage, parsing, override, maxOccurs)
+		}
 
 	case t == stringToStringMapType, fieldType == "stringMap":
-		f.processStringToStringMap(fieldRef, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+		var parsing valueParsing
+		parsing, err = f.valueParsing(tag, path)
+		if err != nil {
+			return err
+		}
+		err = f.processStringToStringMap(fieldRef, hasDefaultTag, tagDefault, target, renamed, usage, parsing)
+
+	case f.isParsedMapType(t):
+		var parsing valueParsing
+		parsing, err = f.valueParsing(tag, path)
+		if err != nil {
+			return err
+		}
+		err = f.processParsedMap(fieldRef, tag, hasDefaultTag, tagDefault, target, renamed, usage, parsing)
 
 		// ignore any other types
 	}
 
 	if err != nil {
+		if hasDefaultTag && isSensitive(tag) {
+			return redactError(err, tagDefault)
+		}
 		return err
 	}
 
-	if !f.options.noSetFromEnv && envName != "" {
-		if val, exists := os.LookupEnv(envName); exists {
-			err := flagSet.Lookup(renamed).Value.Set(val)
+	primary := target.Lookup(renamed)
+	if primary == nil {
+		// unsupported type
+		return nil
+	}
+	f.fieldDefaults[path] = formatValue(reflect.ValueOf(fieldRef).Elem())
+	err = wrapConstraints(primary, path, fieldRef, field, hasDefaultTag)
+	if err != nil {
+		return err
+	}
+	wrapDeprecated(primary, flagSet, tag)
+	f.wrapFieldValue(primary, path, fieldRef, tag)
+	redactDefault(primary, tag)
+	// aliases share the primary flag's value so that their state and default rendering stay
+	// consistent
+	for _, alias := range aliasNames {
+		if deprecated[alias] {
+			message := fmt.Sprintf("use -%s instead", renamed)
+			target.Var(&deprecatedValue{Value: primary.Value, name: alias, message: message, flagSet: flagSet},
+				alias, quotePlaceholder(deprecationNotice(message), primary))
+		} else {
+			target.Var(primary.Value, alias, primary.Usage)
+		}
+		// flag.PrintDefaults omits a default that matches the String of a zero value of the flag's
+		// type, which is an empty string for the wrappers
+		if isZeroDefault(primary) {
+			target.Lookup(alias).DefValue = zeroString(target.Lookup(alias).Value)
+		} else {
+			// retain a redacted default
+			target.Lookup(alias).DefValue = primary.DefValue
+		}
+	}
+	if target != flagSet {
+		rebindFlags(flagSet, target)
+	}
+	f.flagPaths[renamed] = path
+	f.fieldFlags[path] = renamed
+	f.fieldSources[path] = origin
+	for _, alias := range aliasNames {
+		f.flagPaths[alias] = path
+	}
+	// the field's entries are replaced when filling again, such as with ConflictRebind
+	if f.options.requireUsage && tag.Get("usage") == "" {
+		f.missingUsage = appendPath(f.missingUsage, path)
+	}
+	if required {
+		f.requiredPaths = appendPath(f.requiredPaths, path)
+	}
+	if _, exists := tag.Lookup("validate"); exists {
+		f.validatedPaths = appendPath(f.validatedPaths, path)
+	}
+	if requires := tag.Get("requires"); requires != "" {
+		f.requirements = replaceByPath(f.requirements,
+			requirement{path: path, requires: strings.Split(requires, ",")},
+			func(r requirement) string { return r.path })
+	}
+	if enables := tag.Get("enables"); enables != "" {
+		if _, isBool := fieldRef.(*bool); !isBool {
+			return fmt.Errorf("enables tag of field %s is only supported on bool fields", path)
+		}
+		f.gates = replaceByPath(f.gates, gate{path: path, enables: strings.Split(enables, ",")},
+			func(g gate) string { return g.path })
+	}
+	if isSensitive(tag) {
+		if f.sensitiveFlags == nil {
+			f.sensitiveFlags = make(map[string]bool)
+		}
+		f.sensitiveFlags[renamed] = true
+	}
+	if advanced, _ := strconv.ParseBool(tag.Get("advanced")); advanced {
+		if f.advancedFlags == nil {
+			f.advancedFlags = make(map[string]bool)
+		}
+		f.advancedFlags[renamed] = true
+		for _, alias := range aliasNames {
+			f.advancedFlags[alias] = true
+		}
+	}
+	if defaultIf := tag.Get("default-if"); defaultIf != "" {
+		conditional, err := parseDefaultIf(path, defaultIf)
+		if err != nil {
+			return err
+		}
+		f.conditionalDefaults = replaceByPath(f.conditionalDefaults, conditional,
+			func(c conditionalDefault) string { return c.path })
+	}
+
+	if !f.options.deferSources {
+		err = f.applyConfigFiles(flagSet, path, renamed)
+		if err != nil {
+			return err
+		}
+		err = f.applyRemoteSources(flagSet, path, renamed)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(envNames) == 0 && len(envFileNames) == 0 {
+		return nil
+	}
+	if len(envNames) > 0 {
+		f.fieldEnvs[path] = envNames
+	}
+	binding := envBinding{flagName: renamed, envNames: envNames, envFileNames: envFileNames, fieldRef: fieldRef}
+	if f.options.deferSources {
+		f.envBindings = replaceByPath(f.envBindings, binding,
+			func(b envBinding) string { return b.flagName })
+		return nil
+	}
+	return f.applyEnv(flagSet, binding)
+}
+
+// appendPath appends path to paths unless it was appended by filling before
+func appendPath(paths []string, path string) []string {
+	if slices.Contains(paths, path) {
+		return paths
+	}
+	return append(paths, path)
+}
+
+// replaceByPath replaces the entry of items that has the same path as item, which was appended
+// by filling before, or otherwise appends item
+func replaceByPath[T any](items []T, item T, pathOf func(T) string) []T {
+	for i := range items {
+		if pathOf(items[i]) == pathOf(item) {
+			items[i] = item
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// rebindFlags declares the flags of from in flagSet, where flags that are already defined in
+// flagSet are updated to use the value and usage of the flag from the other flag set
+func rebindFlags(flagSet *flag.FlagSet, from *flag.FlagSet) {
+	from.VisitAll(func(declared *flag.Flag) {
+		existing := flagSet.Lookup(declared.Name)
+		if existing == nil {
+			flagSet.Var(declared.Value, declared.Name, declared.Usage)
+			return
+		}
+		existing.Value = declared.Value
+		existing.Usage = declared.Usage
+		existing.DefValue = declared.DefValue
+	})
+}
+
+// checkFlagName ensures the flag name or alias is not already defined in the flagSet since
+// flag.FlagSet would otherwise panic
+func (f *FlagSetFiller) checkFlagName(flagSet *flag.FlagSet, kind string, name string, path string) error {
+	if flagSet.Lookup(name) == nil {
+		return nil
+	}
+	if existingPath, exists := f.flagPaths[name]; exists {
+		return fmt.Errorf("%s %s of field %s is already defined by field %s", kind, name, path, existingPath)
+	}
+	return fmt.Errorf("%s %s of field %s is already defined", kind, name, path)
+}
+
+// lookupConverter resolves the converter declared for a field by the WithFieldConverter option
+// or the converter tag, or its convert form. It returns nil if neither was declared.
+func (f *FlagSetFiller) lookupConverter(path string, tag reflect.StructTag) (func(s string) (interface{}, error), error) {
+	if converter, exists := f.options.fieldConverters[path]; exists {
+		return converter, nil
+	}
+	if name, exists := converterName(tag); exists {
+		converter, registered := namedConverter(name)
+		if !registered {
+			return nil, fmt.Errorf("converter %s is not registered", name)
+		}
+		return converter, nil
+	}
+	return nil, nil
+}
+
+// envBinding associates a flag with the environment variable names mapped to it
+type envBinding struct {
+	flagName string
+	envNames []string
+	// envFileNames are the environment variables giving the path of a file with the value
+	envFileNames []string
+	// fieldRef is the pointer to the field, which is restored when the value is rejected
+	fieldRef interface{}
+}
+
+func (f *FlagSetFiller) applyEnv(flagSet *flag.FlagSet, binding envBinding) error {
+	if f.options.noSetFromEnv {
+		return nil
+	}
+	val, source, exists, err := f.lookupBinding(binding)
+	if !exists {
+		return nil
+	}
+	f.source = SourceEnv
+	defer func() {
+		f.source = SourceArgs
+	}()
+	value := flagSet.Lookup(binding.flagName).Value
+	field := reflect.ValueOf(binding.fieldRef).Elem()
+	previous := snapshotField(field)
+	if err == nil {
+		err = value.Set(val)
+		if err != nil {
+			err = fmt.Errorf("failed to set from %s: %w", source, f.redactError(binding.flagName, err, val))
+		}
+	}
+	if err != nil {
+		if f.options.envErrorHandler == nil {
+			return err
+		}
+		// report and restore the field since some flag types, such as int, assign a zero value
+		// on failure. It is assigned directly since setting the flag would add to slices and maps.
+		f.options.envErrorHandler(err)
+		restoreField(field, previous)
+	} else {
+		endSource(value)
+		f.fieldSources[f.flagPaths[binding.flagName]] = SourceEnv
+	}
+	return nil
+}
+
+// snapshotField copies the value of field, including the elements of slices and maps, which
+// flag values modify in place
+func snapshotField(field reflect.Value) reflect.Value {
+	snapshot := reflect.New(field.Type()).Elem()
+	switch {
+	case field.Kind() == reflect.Slice && !field.IsNil():
+		snapshot.Set(reflect.MakeSlice(field.Type(), field.Len(), field.Len()))
+		reflect.Copy(snapshot, field)
+	case field.Kind() == reflect.Map && !field.IsNil():
+		snapshot.Set(reflect.MakeMapWithSize(field.Type(), field.Len()))
+		iter := field.MapRange()
+		for iter.Next() {
+			snapshot.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		snapshot.Set(field)
+	}
+	return snapshot
+}
+
+// restoreField assigns the snapshot of snapshotField to field, where maps are restored in place
+// since the flag values of some maps, such as strToStrMapVar, hold the map itself
+func restoreField(field reflect.Value, snapshot reflect.Value) {
+	if field.Kind() != reflect.Map || field.IsNil() || snapshot.IsNil() {
+		field.Set(snapshot)
+		return
+	}
+	clearMap(field)
+	iter := snapshot.MapRange()
+	for iter.Next() {
+		field.SetMapIndex(iter.Key(), iter.Value())
+	}
+}
+
+// clearMap removes the entries of the map field in place
+func clearMap(field reflect.Value) {
+	for _, key := range field.MapKeys() {
+		field.SetMapIndex(key, reflect.Value{})
+	}
+}
+
+// lookupBinding returns the value of the first environment variable of the binding that is set,
+// followed by the contents of the file named by the first of its file variables that is set,
+// along with a description of where the value came from
+func (f *FlagSetFiller) lookupBinding(binding envBinding) (val string, source string, exists bool, err error) {
+	for _, envName := range binding.envNames {
+		if val, exists := f.options.lookupEnv(envName); exists {
+			return val, "environment variable " + envName, true, nil
+		}
+	}
+	for _, envName := range binding.envFileNames {
+		if path, exists := f.options.lookupEnv(envName); exists {
+			content, err := os.ReadFile(path)
 			if err != nil {
-				return fmt.Errorf("failed to set from environment variable %s: %w",
-					envName, err)
+				return "", "", true, fmt.Errorf("failed to read file of environment variable %s: %w", envName, err)
 			}
+			// files typically end with a newline, which isn't part of the value
+			val := strings.TrimSuffix(string(content), "\n")
+			val = strings.TrimSuffix(val, "\r")
+			return val, fmt.Sprintf("file %s of environment variable %s", path, envName), true, nil
 		}
 	}
+	return "", "", false, nil
+}
 
+// SetFromMap sets the flags named by the keys of values, such as "remote-host", from the
+// corresponding values. The values are converted and applied the same way as arguments passed
+// on the command-line, which is useful for tests and for bridging other config systems.
+// The flags are set in order of their names and the first failure is returned.
+func (f *FlagSetFiller) SetFromMap(flagSet *flag.FlagSet, values map[string]string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f.source = SourceMap
+	defer func() {
+		f.source = SourceArgs
+	}()
+	for _, name := range names {
+		err := flagSet.Set(name, values[name])
+		if err != nil {
+			return fmt.Errorf("failed to set flag %s: %w", name, err)
+		}
+		if path, exists := f.flagPaths[name]; exists {
+			f.fieldSources[path] = SourceMap
+		}
+	}
 	return nil
 }
 
-func (f *FlagSetFiller) processStringToStringMap(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) {
+// ParseWithSources applies the sources deferred by the WithDeferredSources option to the
+// flagSet and then parses the given args. The resulting precedence, from lowest to highest, is
+// default values, config files, remote sources, environment variables, and then command-line
+// arguments. With the WithArgFiles option, @path arguments are expanded before parsing.
+// After parsing, the defaults declared by default-if tags are applied with ApplyConditionalDefaults
+// and the constraints declared by the fields' tags are checked with Validate.
+func (f *FlagSetFiller) ParseWithSources(flagSet *flag.FlagSet, args []string) error {
+	if f.options.deferSources {
+		for _, path := range f.paths {
+			if flagSet.Lookup(f.fieldFlags[path]) == nil {
+				continue
+			}
+			err := f.applyConfigFiles(flagSet, path, f.fieldFlags[path])
+			if err != nil {
+				return err
+			}
+			err = f.applyRemoteSources(flagSet, path, f.fieldFlags[path])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, binding := range f.envBindings {
+		if flagSet.Lookup(binding.flagName) == nil {
+			continue
+		}
+		err := f.applyEnv(flagSet, binding)
+		if err != nil {
+			return err
+		}
+	}
+	if f.options.argFiles {
+		expanded, err := expandArgFiles(args)
+		if err != nil {
+			return err
+		}
+		args = expanded
+	}
+	if len(f.sensitiveFlags) > 0 {
+		output := flagSet.Output()
+		flagSet.SetOutput(&redactingWriter{filler: f, out: output})
+		defer flagSet.SetOutput(output)
+	}
+	f.helpAll = false
+	err := flagSet.Parse(args)
+	if err != nil {
+		return f.redactParseError(err)
+	}
+	if f.helpAll {
+		// like -help, the usage is shown rather than applying and validating the values
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	err = f.ApplyConditionalDefaults(flagSet)
+	if err != nil {
+		return err
+	}
+	return f.Validate(flagSet)
+}
+
+// valueParsing resolves how the values of a slice or map field are parsed from the options
+// and the field's tags
+func (f *FlagSetFiller) valueParsing(tag reflect.StructTag, path string) (valueParsing, error) {
+	parsing := valueParsing{
+		splitter:  f.options.valueSplitter,
+		trimSpace: !f.options.preserveSpace,
+		keepEmpty: f.options.keepEmpty,
+	}
+	if trimValue, exists := tag.Lookup("trim"); exists {
+		trim, err := strconv.ParseBool(trimValue)
+		if err != nil {
+			return parsing, fmt.Errorf("invalid trim tag %q of field %s", trimValue, path)
+		}
+		parsing.trimSpace = trim
+	}
+	if keepEmptyValue, exists := tag.Lookup("keep-empty"); exists {
+		keepEmpty, err := strconv.ParseBool(keepEmptyValue)
+		if err != nil {
+			return parsing, fmt.Errorf("invalid keep-empty tag %q of field %s", keepEmptyValue, path)
+		}
+		parsing.keepEmpty = keepEmpty
+	}
+	return parsing, nil
+}
+
+func (f *FlagSetFiller) processStringToStringMap(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, parsing valueParsing) error {
 	casted, ok := fieldRef.(*map[string]string)
 	if !ok {
-		_ = f.processCustom(
+		return f.processCustom(
 			fieldRef,
 			func(s string) (interface{}, error) {
-				return parseStringToStringMap(s), nil
+				return parseStringToStringMap(s, parsing), nil
 			},
 			hasDefaultTag,
 			tagDefault,
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
-		return
 	}
 	var val map[string]string
 	if hasDefaultTag {
-		val = parseStringToStringMap(tagDefault)
+		val = parseStringToStringMap(tagDefault, parsing)
 		*casted = val
 	} else if *casted == nil {
 		val = make(map[string]string)
@@ -288,51 +1113,41 @@ func (f *FlagSetFiller) processStringToStringMap(fieldRef interface{}, hasDefaul
 	} else {
 		val = *casted
 	}
-	flagSet.Var(&strToStrMapVar{val: val}, renamed, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Var(&strToStrMapVar{val: val}, alias, usage)
-		}
-	}
+	flagSet.Var(&strToStrMapVar{val: val, parsing: parsing}, renamed, usage)
+	return nil
 }
 
-func (f *FlagSetFiller) processStringSlice(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, override bool, aliases string) {
+func (f *FlagSetFiller) processStringSlice(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, parsing valueParsing, override bool, maxOccurs int) error {
 	casted, ok := fieldRef.(*[]string)
 	if !ok {
-		_ = f.processCustom(
+		return f.processCustom(
 			fieldRef,
 			func(s string) (interface{}, error) {
-				return parseStringSlice(s, f.options.valueSplitPattern), nil
+				return parseStringSlice(s, parsing), nil
 			},
 			hasDefaultTag,
 			tagDefault,
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
-		return
 	}
 	if hasDefaultTag {
-		*casted = parseStringSlice(tagDefault, f.options.valueSplitPattern)
+		*casted = parseStringSlice(tagDefault, parsing)
+	} else if *casted == nil && f.options.emptySlices {
+		*casted = []string{}
 	}
 	flagSet.Var(&strSliceVar{
-		ref:               casted,
-		override:          override,
-		valueSplitPattern: f.options.valueSplitPattern,
+		ref:         casted,
+		override:    override,
+		parsing:     parsing,
+		emptySlices: f.options.emptySlices,
+		maxOccurs:   maxOccurs,
 	}, renamed, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Var(&strSliceVar{
-				ref:               casted,
-				override:          override,
-				valueSplitPattern: f.options.valueSplitPattern,
-			}, alias, usage)
-		}
-	}
+	return nil
 }
 
-func (f *FlagSetFiller) processUint(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processUint(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*uint)
 	if !ok {
 		return f.processCustom(
@@ -346,7 +1161,6 @@ func (f *FlagSetFiller) processUint(fieldRef interface{}, hasDefaultTag bool, ta
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal uint
@@ -361,15 +1175,10 @@ func (f *FlagSetFiller) processUint(fieldRef interface{}, hasDefaultTag bool, ta
 		defaultVal = *casted
 	}
 	flagSet.UintVar(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.UintVar(casted, alias, defaultVal, usage)
-		}
-	}
 	return err
 }
 
-func (f *FlagSetFiller) processUint64(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processUint64(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*uint64)
 	if !ok {
 		return f.processCustom(
@@ -383,7 +1192,6 @@ func (f *FlagSetFiller) processUint64(fieldRef interface{}, hasDefaultTag bool,
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal uint64
@@ -396,15 +1204,10 @@ func (f *FlagSetFiller) processUint64(fieldRef interface{}, hasDefaultTag bool,
 		defaultVal = *casted
 	}
 	flagSet.Uint64Var(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Uint64Var(casted, alias, defaultVal, usage)
-		}
-	}
 	return err
 }
 
-func (f *FlagSetFiller) processInt(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processInt(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*int)
 	if !ok {
 		return f.processCustom(
@@ -418,7 +1221,6 @@ func (f *FlagSetFiller) processInt(fieldRef interface{}, hasDefaultTag bool, tag
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal int
@@ -431,15 +1233,10 @@ func (f *FlagSetFiller) processInt(fieldRef interface{}, hasDefaultTag bool, tag
 		defaultVal = *casted
 	}
 	flagSet.IntVar(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.IntVar(casted, alias, defaultVal, usage)
-		}
-	}
 	return err
 }
 
-func (f *FlagSetFiller) processInt64(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processInt64(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*int64)
 	if !ok {
 		return f.processCustom(
@@ -453,7 +1250,6 @@ func (f *FlagSetFiller) processInt64(fieldRef interface{}, hasDefaultTag bool, t
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal int64
@@ -466,15 +1262,10 @@ func (f *FlagSetFiller) processInt64(fieldRef interface{}, hasDefaultTag bool, t
 		defaultVal = *casted
 	}
 	flagSet.Int64Var(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Int64Var(casted, alias, defaultVal, usage)
-		}
-	}
 	return nil
 }
 
-func (f *FlagSetFiller) processDuration(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processDuration(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*time.Duration)
 	if !ok {
 		return f.processCustom(
@@ -488,7 +1279,6 @@ func (f *FlagSetFiller) processDuration(fieldRef interface{}, hasDefaultTag bool
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal time.Duration
@@ -501,15 +1291,10 @@ func (f *FlagSetFiller) processDuration(fieldRef interface{}, hasDefaultTag bool
 		defaultVal = *casted
 	}
 	flagSet.DurationVar(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.DurationVar(casted, alias, defaultVal, usage)
-		}
-	}
 	return nil
 }
 
-func (f *FlagSetFiller) processFloat64(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processFloat64(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*float64)
 	if !ok {
 		return f.processCustom(
@@ -523,7 +1308,6 @@ func (f *FlagSetFiller) processFloat64(fieldRef interface{}, hasDefaultTag bool,
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal float64
@@ -536,15 +1320,10 @@ func (f *FlagSetFiller) processFloat64(fieldRef interface{}, hasDefaultTag bool,
 		defaultVal = *casted
 	}
 	flagSet.Float64Var(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Float64Var(casted, alias, defaultVal, usage)
-		}
-	}
 	return nil
 }
 
-func (f *FlagSetFiller) processBool(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) (err error) {
+func (f *FlagSetFiller) processBool(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) (err error) {
 	casted, ok := fieldRef.(*bool)
 	if !ok {
 		return f.processCustom(
@@ -558,7 +1337,6 @@ func (f *FlagSetFiller) processBool(fieldRef interface{}, hasDefaultTag bool, ta
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
 	}
 	var defaultVal bool
@@ -571,18 +1349,13 @@ func (f *FlagSetFiller) processBool(fieldRef interface{}, hasDefaultTag bool, ta
 		defaultVal = *casted
 	}
 	flagSet.BoolVar(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.BoolVar(casted, alias, defaultVal, usage)
-		}
-	}
 	return nil
 }
 
-func (f *FlagSetFiller) processString(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) {
+func (f *FlagSetFiller) processString(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) error {
 	casted, ok := fieldRef.(*string)
 	if !ok {
-		_ = f.processCustom(
+		return f.processCustom(
 			fieldRef,
 			func(s string) (interface{}, error) {
 				return s, nil
@@ -592,9 +1365,7 @@ func (f *FlagSetFiller) processString(fieldRef interface{}, hasDefaultTag bool,
 			flagSet,
 			renamed,
 			usage,
-			aliases,
 		)
-		return
 	}
 	var defaultVal string
 	if hasDefaultTag {
@@ -603,48 +1374,109 @@ func (f *FlagSetFiller) processString(fieldRef interface{}, hasDefaultTag bool,
 		defaultVal = *casted
 	}
 	flagSet.StringVar(casted, renamed, defaultVal, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.StringVar(casted, alias, defaultVal, usage)
-		}
-	}
+	return nil
 }
 
-func (f *FlagSetFiller) processCustom(fieldRef interface{}, converter func(string) (interface{}, error), hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, aliases string) error {
+func (f *FlagSetFiller) processCustom(fieldRef interface{}, converter func(string) (interface{}, error), hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) error {
+	assigner := newConvertedAssigner(fieldRef)
 	if hasDefaultTag {
 		value, err := converter(tagDefault)
 		if err != nil {
 			return fmt.Errorf("failed to parse default into custom type: %w", err)
 		}
-		reflect.ValueOf(fieldRef).Elem().Set(reflect.ValueOf(value).Convert(reflect.TypeOf(fieldRef).Elem()))
+		err = assigner.assign(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into custom type: %w", err)
+		}
 	}
 	flagSet.Func(renamed, usage, func(s string) error {
 		value, err := converter(s)
 		if err != nil {
 			return err
 		}
-		reflect.ValueOf(fieldRef).Elem().Set(reflect.ValueOf(value).Convert(reflect.TypeOf(fieldRef).Elem()))
-		return nil
+		return assigner.assign(value)
 	})
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Func(alias, usage, func(s string) error {
-				value, err := converter(s)
-				if err != nil {
-					return err
-				}
-				reflect.ValueOf(fieldRef).Elem().Set(reflect.ValueOf(value).Convert(reflect.TypeOf(fieldRef).Elem()))
-				return nil
-			})
+	return nil
+}
+
+// convertedAssigner sets a field to the values returned by a converter, which must be
+// convertible to the field's type. The field is resolved once and the conversion is only
+// checked again when a converter returns a different type, since a flag can be set many times.
+type convertedAssigner struct {
+	target     reflect.Value
+	targetType reflect.Type
+	// sourceType is the type of the most recently assigned value
+	sourceType reflect.Type
+	// direct indicates sourceType is the field's type, so no conversion is needed
+	direct bool
+}
+
+func newConvertedAssigner(fieldRef interface{}) *convertedAssigner {
+	target := reflect.ValueOf(fieldRef).Elem()
+	return &convertedAssigner{target: target, targetType: target.Type()}
+}
+
+func (a *convertedAssigner) assign(value interface{}) (err error) {
+	converted := reflect.ValueOf(value)
+	if !converted.IsValid() {
+		return fmt.Errorf("converter returned nil, but %s was expected", a.targetType)
+	}
+	if converted.Type() != a.sourceType {
+		if !converted.Type().ConvertibleTo(a.targetType) {
+			return fmt.Errorf("converter returned %s, which is not convertible to %s",
+				converted.Type(), a.targetType)
 		}
+		a.sourceType = converted.Type()
+		a.direct = a.sourceType == a.targetType
 	}
+	if a.direct {
+		a.target.Set(converted)
+		return nil
+	}
+	defer func() {
+		// some conversions, such as slice to array, can still panic depending on the value
+		if r := recover(); r != nil {
+			err = fmt.Errorf("converter returned %s, which could not be converted to %s: %v",
+				converted.Type(), a.targetType, r)
+		}
+	}()
+	a.target.Set(converted.Convert(a.targetType))
 	return nil
 }
 
+// valueParsing declares how the value of a slice or map flag is split into elements
+type valueParsing struct {
+	// splitter splits a value into elements, when not nil
+	splitter *regexp.Regexp
+	// trimSpace indicates leading and trailing whitespace of elements is removed
+	trimSpace bool
+	// keepEmpty indicates blank slice elements are kept rather than dropped
+	keepEmpty bool
+}
+
 type strSliceVar struct {
-	ref               *[]string
-	override          bool
-	valueSplitPattern string
+	ref      *[]string
+	override bool
+	parsing  valueParsing
+	// emptySlices indicates an empty value sets the field to an empty slice
+	emptySlices bool
+	// maxOccurs limits the number of times the flag can be given and the number of values given,
+	// when not zero
+	maxOccurs int
+	occurs    int
+	given     int
+	// listing indicates each value is an element of a list, between beginList and endList
+	listing bool
+}
+
+func (s *strSliceVar) beginList() {
+	*s.ref = []string{}
+	s.occurs++
+	s.listing = true
+}
+
+func (s *strSliceVar) endList() {
+	s.listing = false
 }
 
 func (s *strSliceVar) String() string {
@@ -655,7 +1487,26 @@ func (s *strSliceVar) String() string {
 }
 
 func (s *strSliceVar) Set(val string) error {
-	parts := parseStringSlice(val, s.valueSplitPattern)
+	if s.listing {
+		return s.setElement(val)
+	}
+	parts := parseStringSlice(val, s.parsing)
+
+	if s.maxOccurs > 0 {
+		s.occurs++
+		s.given += len(parts)
+		if s.occurs > s.maxOccurs {
+			return fmt.Errorf("can be given at most %d times", s.maxOccurs)
+		}
+		if s.given > s.maxOccurs {
+			return fmt.Errorf("can have at most %d values", s.maxOccurs)
+		}
+	}
+
+	if s.emptySlices && val == "" {
+		*s.ref = []string{}
+		return nil
+	}
 
 	if s.override {
 		*s.ref = parts
@@ -667,19 +1518,38 @@ func (s *strSliceVar) Set(val string) error {
 	return nil
 }
 
-func parseStringSlice(val string, valueSplitPattern string) []string {
-	if valueSplitPattern == "" {
+// setElement appends val as one element of a list
+func (s *strSliceVar) setElement(val string) error {
+	if s.parsing.trimSpace {
+		val = strings.TrimSpace(val)
+	}
+	if s.maxOccurs > 0 {
+		s.given++
+		if s.occurs > s.maxOccurs {
+			return fmt.Errorf("can be given at most %d times", s.maxOccurs)
+		}
+		if s.given > s.maxOccurs {
+			return fmt.Errorf("can have at most %d values", s.maxOccurs)
+		}
+	}
+	*s.ref = append(*s.ref, val)
+	return nil
+}
+
+func parseStringSlice(val string, parsing valueParsing) []string {
+	if parsing.splitter == nil {
 		return []string{val}
 	}
 
-	splitter := regexp.MustCompile(valueSplitPattern)
-	parts := splitter.Split(val, -1)
+	parts := parsing.splitter.Split(val, -1)
 
-	// trim out blank parts
+	// trim out blank parts, unless kept
 	result := make([]string, 0, len(parts))
 	for _, s := range parts {
-		s = strings.TrimSpace(s)
-		if s != "" {
+		if parsing.trimSpace {
+			s = strings.TrimSpace(s)
+		}
+		if parsing.keepEmpty || strings.TrimSpace(s) != "" {
 			result = append(result, s)
 		}
 	}
@@ -687,7 +1557,8 @@ func parseStringSlice(val string, valueSplitPattern string) []string {
 }
 
 type strToStrMapVar struct {
-	val map[string]string
+	val     map[string]string
+	parsing valueParsing
 }
 
 func (s strToStrMapVar) String() string {
@@ -711,23 +1582,24 @@ func (s strToStrMapVar) String() string {
 }
 
 func (s strToStrMapVar) Set(val string) error {
-	content := parseStringToStringMap(val)
+	content := parseStringToStringMap(val, s.parsing)
 	for k, v := range content {
 		s.val[k] = v
 	}
 	return nil
 }
 
-func parseStringToStringMap(val string) map[string]string {
+func parseStringToStringMap(val string, parsing valueParsing) map[string]string {
 	result := make(map[string]string)
 
-	splitter := regexp.MustCompile("[\n,]")
-
-	pairs := splitter.Split(val, -1)
+	// entries are always split, regardless of the value split pattern
+	pairs := defaultValueSplitter.Split(val, -1)
 	for _, pair := range pairs {
-		pair = strings.TrimSpace(pair)
+		if parsing.trimSpace {
+			pair = strings.TrimSpace(pair)
+		}
 
-		if pair != "" {
+		if strings.TrimSpace(pair) != "" {
 			kv := strings.SplitN(pair, "=", 2)
 			if len(kv) == 2 {
 				result[kv[0]] = kv[1]
@@ -740,6 +1612,52 @@ func parseStringToStringMap(val string) map[string]string {
 	return result
 }
 
+// addDashUnderscoreAliases returns the given comma-separated aliases along with the dash/underscore
+// swapped variants of the flag name and of each alias
+func addDashUnderscoreAliases(name string, aliases string) string {
+	names := []string{name}
+	if aliases != "" {
+		names = append(names, strings.Split(aliases, ",")...)
+	}
+	seen := make(map[string]bool, len(names))
+	for _, n := range names {
+		seen[n] = true
+	}
+
+	result := names[1:]
+	for _, n := range names {
+		for _, variant := range []string{
+			strings.ReplaceAll(n, "-", "_"),
+			strings.ReplaceAll(n, "_", "-"),
+		} {
+			if !seen[variant] {
+				seen[variant] = true
+				result = append(result, variant)
+			}
+		}
+	}
+	return strings.Join(result, ",")
+}
+
+// quotePlaceholder back quotes the first word of usage that is the placeholder name that
+// flag.UnquoteUsage derives from the given flag, such as duration for a standard flag.Value, so
+// that the name is retained when the flag's value is wrapped. Usage that declares a placeholder
+// or doesn't mention the name is returned as is.
+func quotePlaceholder(usage string, declared *flag.Flag) string {
+	if strings.Contains(usage, "`") {
+		return usage
+	}
+	name, _ := flag.UnquoteUsage(declared)
+	if name == "" || name == "value" {
+		return usage
+	}
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`).FindStringIndex(usage)
+	if word == nil {
+		return usage
+	}
+	return usage[:word[0]] + "`" + name + "`" + usage[word[1]:]
+}
+
 // requoteUsage converts a [name] quoted usage string into the back quote form processed by flag.UnquoteUsage
 func requoteUsage(usage string) string {
 	return strings.Map(func(r rune) rune {
@@ -4,7 +4,13 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -133,6 +139,119 @@ func TestCustomFields(t *testing.T) {
 	})
 }
 
+func TestWithFieldConverter(t *testing.T) {
+	type Config struct {
+		Remote struct {
+			// seconds rather than a duration string
+			Timeout time.Duration `default:"5"`
+		}
+		Timeout time.Duration
+	}
+
+	var config Config
+
+	filler := flagsfiller.New(
+		flagsfiller.WithFieldConverter("Remote.Timeout", func(s string) (interface{}, error) {
+			seconds, err := strconv.Atoi(s)
+			return time.Duration(seconds) * time.Second, err
+		}),
+	)
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, config.Remote.Timeout)
+
+	err = flagset.Parse([]string{"--remote-timeout", "10", "--timeout", "10s"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, config.Remote.Timeout)
+	assert.Equal(t, 10*time.Second, config.Timeout)
+
+	flagset.SetOutput(io.Discard)
+	err = flagset.Parse([]string{"--remote-timeout", "10s"})
+	assert.Error(t, err)
+}
+
+func TestWithFieldConverterVaryingTypes(t *testing.T) {
+	type Config struct {
+		Size int64
+	}
+
+	// returns the field's type or another convertible type depending on the value
+	converter := func(s string) (interface{}, error) {
+		switch s {
+		case "small":
+			return int8(1), nil
+		case "large":
+			return int64(1 << 40), nil
+		default:
+			return s, nil
+		}
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithFieldConverter("Size", converter))
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	require.NoError(t, flagset.Set("size", "large"))
+	assert.Equal(t, int64(1<<40), config.Size)
+	require.NoError(t, flagset.Set("size", "small"))
+	assert.Equal(t, int64(1), config.Size)
+	require.NoError(t, flagset.Set("size", "large"))
+	assert.Equal(t, int64(1<<40), config.Size)
+
+	err = flagset.Set("size", "other")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not convertible to int64")
+	assert.Equal(t, int64(1<<40), config.Size)
+}
+
+func TestWithFieldConverterMismatchedType(t *testing.T) {
+	type Config struct {
+		Remote struct {
+			Port int `default:"8080"`
+		}
+	}
+
+	toString := func(s string) (interface{}, error) {
+		return s, nil
+	}
+
+	t.Run("default", func(t *testing.T) {
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithFieldConverter("Remote.Port", toString))
+
+		var flagset flag.FlagSet
+		err := filler.Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Remote")
+		assert.Contains(t, err.Error(), "not convertible to int")
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		type NoDefault struct {
+			Port int
+		}
+		var config NoDefault
+		filler := flagsfiller.New(flagsfiller.WithFieldConverter("Port", toString))
+
+		var flagset flag.FlagSet
+		flagset.SetOutput(io.Discard)
+		err := filler.Fill(&flagset, &config)
+		require.NoError(t, err)
+
+		err = flagset.Parse([]string{"--port", "9090"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not convertible to int")
+	})
+}
+
 func TestUsage(t *testing.T) {
 	type Config struct {
 		MultiWordName string `usage:"usage goes here"`
@@ -175,6 +294,96 @@ func TestRenamerOption(t *testing.T) {
 	assert.Equal(t, "val1", config.MultiWordName)
 }
 
+func TestDotRenamerOption(t *testing.T) {
+	type Config struct {
+		Host   string
+		Remote struct {
+			Auth struct {
+				Username string
+			}
+			MaxTimeout time.Duration
+		}
+	}
+
+	var config Config
+
+	filler := flagsfiller.New(flagsfiller.WithFieldRenamer(flagsfiller.DotRenamer()))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--host", "h1", "--remote.auth.username", "user1", "--remote.max-timeout", "5s"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "h1", config.Host)
+	assert.Equal(t, "user1", config.Remote.Auth.Username)
+	assert.Equal(t, 5*time.Second, config.Remote.MaxTimeout)
+}
+
+func TestDashUnderscoreAliases(t *testing.T) {
+	type Config struct {
+		Host          string `aliases:"server-host"`
+		MultiWordName string
+	}
+
+	var config Config
+
+	filler := flagsfiller.New(flagsfiller.WithDashUnderscoreAliases())
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.NotNil(t, flagset.Lookup("multi-word-name"))
+	assert.NotNil(t, flagset.Lookup("multi_word_name"))
+	assert.NotNil(t, flagset.Lookup("server_host"))
+
+	err = flagset.Parse([]string{"--server_host", "h1", "--multi_word_name", "val1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "h1", config.Host)
+	assert.Equal(t, "val1", config.MultiWordName)
+}
+
+func TestAliasesShareValue(t *testing.T) {
+	type Config struct {
+		Host    string            `default:"localhost" aliases:"h"`
+		Tags    []string          `aliases:"t"`
+		Labels  map[string]string `aliases:"l"`
+		Address net.IP            `default:"127.0.0.1" aliases:"a"`
+		Port    int               `converter:"port" aliases:"p"`
+	}
+
+	flagsfiller.RegisterNamedConverter("port", func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	})
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	for _, names := range [][2]string{{"host", "h"}, {"tags", "t"}, {"labels", "l"}, {"address", "a"}, {"port", "p"}} {
+		primary, alias := flagset.Lookup(names[0]), flagset.Lookup(names[1])
+		require.NotNil(t, alias, names[1])
+		// compared by pointer since some values, such as those declared by flag.Func, are funcs
+		assert.Equal(t, reflect.ValueOf(primary.Value).Pointer(), reflect.ValueOf(alias.Value).Pointer(), names[1])
+		assert.Equal(t, primary.DefValue, alias.DefValue, names[1])
+	}
+
+	err = flagset.Parse([]string{"-t", "one", "--tags", "two", "-l", "k=v", "-a", "10.0.0.1", "-p", "80"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"one", "two"}, config.Tags)
+	assert.Equal(t, map[string]string{"k": "v"}, config.Labels)
+	assert.Equal(t, "10.0.0.1", config.Address.String())
+	assert.Equal(t, 80, config.Port)
+	assert.Equal(t, "one,two", flagset.Lookup("t").Value.String())
+}
+
 func TestNestedFields(t *testing.T) {
 	type Config struct {
 		Host         string
@@ -505,6 +714,93 @@ func TestBadFieldErrorMessage(t *testing.T) {
 
 }
 
+func TestDuplicateFlagNames(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		type Config struct {
+			RemoteHost string
+			Remote     struct {
+				Host string
+			}
+		}
+
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"flag remote-host of field Remote.Host is already defined by field RemoteHost")
+	})
+
+	t.Run("flag tag", func(t *testing.T) {
+		type Config struct {
+			Host    string
+			Address string `flag:"host"`
+		}
+
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		assert.ErrorContains(t, err, "flag host of field Address is already defined by field Host")
+	})
+
+	t.Run("defined outside", func(t *testing.T) {
+		type Config struct {
+			Host string
+		}
+
+		var config Config
+
+		var flagset flag.FlagSet
+		flagset.String("host", "", "")
+		err := flagsfiller.New().Fill(&flagset, &config)
+		assert.ErrorContains(t, err, "flag host of field Host is already defined")
+	})
+}
+
+func TestAliasCollisions(t *testing.T) {
+	t.Run("with flag", func(t *testing.T) {
+		type Config struct {
+			Host    string
+			Address string `aliases:"a,host"`
+		}
+
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		assert.ErrorContains(t, err, "alias host of field Address is already defined by field Host")
+	})
+
+	t.Run("with alias", func(t *testing.T) {
+		type Config struct {
+			Timeout time.Duration `aliases:"t"`
+			Remote  struct {
+				Tries int `aliases:"t"`
+			}
+		}
+
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		assert.ErrorContains(t, err, "alias t of field Remote.Tries is already defined by field Timeout")
+	})
+
+	t.Run("within field", func(t *testing.T) {
+		type Config struct {
+			Timeout time.Duration `aliases:"t,t"`
+		}
+
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		assert.ErrorContains(t, err, "alias t of field Timeout is declared more than once")
+	})
+}
+
 func TestHiddenFields(t *testing.T) {
 	type Config struct {
 		hidden string
@@ -526,6 +822,85 @@ func TestHiddenFields(t *testing.T) {
 	assert.Equal(t, "", buf.String())
 }
 
+func TestDeprecatedAliases(t *testing.T) {
+	type Config struct {
+		Host    string `deprecated-aliases:"server,addr"`
+		Verbose bool   `aliases:"v" deprecated-aliases:"debug"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var output bytes.Buffer
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(&output)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--server", "one", "--server", "two", "--debug"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "two", config.Host)
+	assert.True(t, config.Verbose)
+	assert.Equal(t, "flag -server is deprecated, use -host instead\n"+
+		"flag -debug is deprecated, use -verbose instead\n", output.String())
+
+	name, ok := filler.FlagNameFor("Host")
+	require.True(t, ok)
+	assert.Equal(t, "host", name)
+	field, ok := filler.FieldFor("addr")
+	require.True(t, ok)
+	assert.Equal(t, "Host", field.Path)
+
+	output.Reset()
+	flagset.PrintDefaults()
+	assert.Contains(t, output.String(), "-server value\n    \tdeprecated, use -host instead\n")
+	assert.Contains(t, output.String(), "-debug\n    \tdeprecated, use -verbose instead\n")
+}
+
+func TestDeprecated(t *testing.T) {
+	type Config struct {
+		Host    string `deprecated:"use -address instead" usage:"the host"`
+		Address string
+		Legacy  bool   `aliases:"l" deprecated:""`
+		Format  string `deprecated:"will be removed" choices:"json,text" default:"text"`
+	}
+
+	t.Setenv("DEPRECATED_FORMAT", "json")
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("Deprecated"))
+
+	var output bytes.Buffer
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(&output)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--host", "one", "--host", "two", "-l"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "two", config.Host)
+	assert.True(t, config.Legacy)
+	assert.Equal(t, "json", config.Format)
+	assert.Equal(t, "flag -format is deprecated, will be removed\n"+
+		"flag -host is deprecated, use -address instead\n"+
+		"flag -legacy is deprecated\n", output.String())
+
+	output.Reset()
+	flagset.PrintDefaults()
+	assert.Equal(t, `  -address string
+    	 (env DEPRECATED_ADDRESS)
+  -format value
+    	 (env DEPRECATED_FORMAT) (one of json, text) (deprecated, will be removed) (default text)
+  -host value
+    	the host (env DEPRECATED_HOST) (deprecated, use -address instead)
+  -l	 (env DEPRECATED_LEGACY) (deprecated)
+  -legacy
+    	 (env DEPRECATED_LEGACY) (deprecated)
+`, output.String())
+}
+
 func TestStringSlice(t *testing.T) {
 	type Config struct {
 		NoDefault       []string
@@ -595,90 +970,754 @@ func TestStringSliceWithEmptyValuePattern(t *testing.T) {
 	assert.Equal(t, []string{"one,two"}, config.TagDefault)
 }
 
-func TestStringToStringMap(t *testing.T) {
+func TestStringSliceWithCustomValuePattern(t *testing.T) {
 	type Config struct {
-		NoDefault       map[string]string
-		InstanceDefault map[string]string
-		TagDefault      map[string]string `default:"fruit=apple,veggie=carrot"`
+		Tags []string `default:"one;two"`
 	}
 
 	var config Config
-	config.InstanceDefault = map[string]string{"fruit": "orange"}
-
-	filler := flagsfiller.New()
+	filler := flagsfiller.New(flagsfiller.WithValueSplitPattern(";"))
 
 	var flagset flag.FlagSet
 	err := filler.Fill(&flagset, &config)
 	require.NoError(t, err)
 
-	buf := grabUsage(flagset)
-
-	// using regexp assertion since -tag-default's map entries can be either order
-	assert.Regexp(t, `
-  -instance-default value
-    	 \(default fruit=orange\)
-  -no-default value
-    	
-  -tag-default value
-    	 \(default (veggie=carrot,fruit=apple|fruit=apple,veggie=carrot)\)
-`, buf.String())
-
-	err = flagset.Parse([]string{"--no-default",
-		"k1=v1",
-		"--no-default",
-		"k2=v2,k3=v3\nk4=v4\n",
-	})
+	err = flagset.Parse([]string{"--tags", "three;four,five"})
 	require.NoError(t, err)
 
-	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2", "k3": "v3", "k4": "v4"}, config.NoDefault)
-	assert.Equal(t, map[string]string{"fruit": "apple", "veggie": "carrot"}, config.TagDefault)
+	assert.Equal(t, []string{"one", "two", "three", "four,five"}, config.Tags)
 }
 
-func TestUsagePlaceholders(t *testing.T) {
+func TestStringSliceWithInvalidValuePattern(t *testing.T) {
 	type Config struct {
-		SomeUrl string `usage:"a [URL] to configure"`
+		Tags []string
 	}
 
 	var config Config
-
-	filler := flagsfiller.New()
+	filler := flagsfiller.New(flagsfiller.WithValueSplitPattern("[,"))
 
 	var flagset flag.FlagSet
 	err := filler.Fill(&flagset, &config)
-	require.NoError(t, err)
-
-	buf := grabUsage(flagset)
-
-	assert.Equal(t, `
-  -some-url URL
-    	a URL to configure
-`, buf.String())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value split pattern")
 }
 
-func TestParse(t *testing.T) {
+func TestStringSliceEmptySemantics(t *testing.T) {
 	type Config struct {
-		Host string
+		Unset    []string
+		Cleared  []string `default:"one,two"`
+		Appended []string `default:"one,two"`
 	}
 
-	var config Config
-	os.Args = []string{"app", "--host", "host-a"}
+	t.Run("default", func(t *testing.T) {
+		var config Config
+		filler := flagsfiller.New()
 
-	err := flagsfiller.Parse(&config)
-	assert.NoError(t, err)
+		var flagset flag.FlagSet
+		err := filler.Fill(&flagset, &config)
+		require.NoError(t, err)
 
-	require.Equal(t, "host-a", config.Host)
+		err = flagset.Parse([]string{"--cleared", ""})
+		require.NoError(t, err)
+
+		assert.Nil(t, config.Unset)
+		assert.Equal(t, []string{"one", "two"}, config.Cleared)
+	})
+
+	t.Run("with empty slices", func(t *testing.T) {
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithEmptySlices())
+
+		var flagset flag.FlagSet
+		err := filler.Fill(&flagset, &config)
+		require.NoError(t, err)
+
+		err = flagset.Parse([]string{"--cleared", "", "--appended", "three"})
+		require.NoError(t, err)
+
+		assert.NotNil(t, config.Unset)
+		assert.Empty(t, config.Unset)
+		assert.NotNil(t, config.Cleared)
+		assert.Empty(t, config.Cleared)
+		assert.Equal(t, []string{"one", "two", "three"}, config.Appended)
+	})
 }
 
-func TestParseError(t *testing.T) {
+func TestPreserveWhitespace(t *testing.T) {
 	type Config struct {
-		BadDefault int `default:"not an int"`
+		Prefixes []string
+		Trimmed  []string `trim:"true"`
+		Padding  map[string]string
 	}
 
 	var config Config
-	os.Args = []string{"app", "--bad-default", "5"}
+	filler := flagsfiller.New(flagsfiller.WithPreserveWhitespace())
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{
+		"--prefixes", "> , # ",
+		"--trimmed", " a , b ",
+		"--padding", "left= x,right=y ",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"> ", " # "}, config.Prefixes)
+	assert.Equal(t, []string{"a", "b"}, config.Trimmed)
+	assert.Equal(t, map[string]string{"left": " x", "right": "y "}, config.Padding)
+}
+
+func TestStringSliceTrimTag(t *testing.T) {
+	type Config struct {
+		Prefixes []string `trim:"false"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--prefixes", "> ,  ,# "})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"> ", "# "}, config.Prefixes)
+}
+
+func TestStringSliceKeepEmpty(t *testing.T) {
+	type Config struct {
+		Cols    []string `keep-empty:"true"`
+		Dropped []string
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--cols", "a,,c", "--dropped", "a,,c"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "", "c"}, config.Cols)
+	assert.Equal(t, []string{"a", "c"}, config.Dropped)
+}
+
+func TestWithEmptyElements(t *testing.T) {
+	type Config struct {
+		Cols    []string `default:"a,,c"`
+		Dropped []string `keep-empty:"false"`
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEmptyElements())
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--dropped", "x, ,y"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "", "c"}, config.Cols)
+	assert.Equal(t, []string{"x", "y"}, config.Dropped)
+}
+
+func TestStringSliceMaxOccurs(t *testing.T) {
+	type Config struct {
+		Peers []string `max-occurs:"2" default:"a,b,c"`
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "default", args: nil, want: []string{"a", "b", "c"}},
+		{name: "within", args: []string{"--peers", "x", "--peers", "y"}, want: []string{"a", "b", "c", "x", "y"}},
+		{name: "too many times", args: []string{"--peers", "x", "--peers", "y", "--peers", "z"}, wantErr: "at most 2 times"},
+		{name: "too many values", args: []string{"--peers", "x,y,z"}, wantErr: "at most 2 values"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			filler := flagsfiller.New()
+
+			flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+			flagset.SetOutput(io.Discard)
+			err := filler.Fill(flagset, &config)
+			require.NoError(t, err)
+
+			err = flagset.Parse(tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, config.Peers)
+			}
+		})
+	}
+}
+
+func TestStringSliceInvalidMaxOccurs(t *testing.T) {
+	type Config struct {
+		Peers []string `max-occurs:"none"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid max-occurs tag")
+}
+
+func TestMaxOccursUnsupportedType(t *testing.T) {
+	t.Run("not a slice", func(t *testing.T) {
+		type Config struct {
+			Port int `max-occurs:"2"`
+		}
+
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max-occurs tag of field Port only applies to")
+	})
+
+	t.Run("converted slice", func(t *testing.T) {
+		type Config struct {
+			Peers []string `max-occurs:"2"`
+		}
+
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New(flagsfiller.WithFieldConverter("Peers", func(s string) (interface{}, error) {
+			return strings.Split(strings.ToUpper(s), ","), nil
+		})).Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max-occurs tag of field Peers only applies to")
+	})
+}
+
+func TestNarrowNumbers(t *testing.T) {
+	type Level int8
+	type Config struct {
+		Offset int8 `default:"-5"`
+		Delta  int16
+		Count  int32
+		Ttl    uint8
+		Port   uint16 `default:"0x10"`
+		Size   uint32
+		Ratio  float32 `default:"1.5"`
+		Level  Level
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, int8(-5), config.Offset)
+	assert.Equal(t, uint16(16), config.Port)
+	assert.Equal(t, float32(1.5), config.Ratio)
+
+	var buf bytes.Buffer
+	flagset.SetOutput(&buf)
+	flagset.PrintDefaults()
+	assert.Equal(t, `  -count value
+    	
+  -delta value
+    	
+  -level value
+    	
+  -offset value
+    	 (default -5)
+  -port value
+    	 (default 16)
+  -ratio value
+    	 (default 1.5)
+  -size value
+    	
+  -ttl value
+    	
+`, buf.String())
+	flagset.SetOutput(io.Discard)
+
+	err = flagset.Parse([]string{
+		"--delta", "-300",
+		"--count", "70000",
+		"--ttl", "255",
+		"--size", "4000000000",
+		"--ratio", "0.25",
+		"--level", "3",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int16(-300), config.Delta)
+	assert.Equal(t, int32(70000), config.Count)
+	assert.Equal(t, uint8(255), config.Ttl)
+	assert.Equal(t, uint32(4000000000), config.Size)
+	assert.Equal(t, float32(0.25), config.Ratio)
+	assert.Equal(t, Level(3), config.Level)
+
+	err = flagset.Set("ttl", "256")
+	assert.EqualError(t, err, "256 is out of range of uint8, 0 to 255")
+	assert.Equal(t, uint8(255), config.Ttl)
+
+	err = flagset.Set("offset", "-129")
+	assert.EqualError(t, err, "-129 is out of range of int8, -128 to 127")
+
+	err = flagset.Set("port", "-1")
+	assert.EqualError(t, err, "-1 is not a valid uint16")
+}
+
+func TestNarrowNumberInvalidDefault(t *testing.T) {
+	type Config struct {
+		Port uint16 `default:"70000"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse default into uint16: 70000 is out of range of uint16, 0 to 65535")
+}
+
+func TestIntSlice(t *testing.T) {
+	type Config struct {
+		Ports           []int
+		InstanceDefault []int
+		TagDefault      []int64 `default:"1, 2"`
+		TagOverride     []int   `default:"1,2" override-value:"true"`
+	}
+
+	var config Config
+	config.InstanceDefault = []int{80, 443}
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	assert.Equal(t, `
+  -instance-default value
+    	 (default 80,443)
+  -ports value
+    	
+  -tag-default value
+    	 (default 1,2)
+  -tag-override value
+    	 (default 1,2)
+`, buf.String())
+
+	err = flagset.Parse([]string{
+		"--ports", "80,443",
+		"--ports", "8080",
+		"--tag-default", "3",
+		"--tag-override", "3",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{80, 443, 8080}, config.Ports)
+	assert.Equal(t, []int{80, 443}, config.InstanceDefault)
+	assert.Equal(t, []int64{1, 2, 3}, config.TagDefault)
+	assert.Equal(t, []int{3}, config.TagOverride)
+}
+
+func TestIntSliceInvalid(t *testing.T) {
+	type Config struct {
+		Ports []int
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--ports", "80,http"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `parsing "http": invalid syntax`)
+	assert.Empty(t, config.Ports)
+}
+
+func TestIntSliceInvalidDefault(t *testing.T) {
+	type Config struct {
+		Ports []int `default:"80,http"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse default into []int")
+}
+
+func TestDurationSlice(t *testing.T) {
+	type Config struct {
+		Backoff  []time.Duration `default:"1s,5s,30s"`
+		Override []time.Duration `default:"1s" override-value:"true"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	assert.Equal(t, `
+  -backoff value
+    	 (default 1s,5s,30s)
+  -override value
+    	 (default 1s)
+`, buf.String())
+
+	err = flagset.Parse([]string{
+		"--backoff", "1m",
+		"--override", "100ms,2s",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{time.Second, 5 * time.Second, 30 * time.Second, time.Minute}, config.Backoff)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 2 * time.Second}, config.Override)
+
+	err = flagset.Set("backoff", "5")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing unit in duration "5"`)
+}
+
+func TestStringToStringMap(t *testing.T) {
+	type Config struct {
+		NoDefault       map[string]string
+		InstanceDefault map[string]string
+		TagDefault      map[string]string `default:"fruit=apple,veggie=carrot"`
+	}
+
+	var config Config
+	config.InstanceDefault = map[string]string{"fruit": "orange"}
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	// using regexp assertion since -tag-default's map entries can be either order
+	assert.Regexp(t, `
+  -instance-default value
+    	 \(default fruit=orange\)
+  -no-default value
+    	
+  -tag-default value
+    	 \(default (veggie=carrot,fruit=apple|fruit=apple,veggie=carrot)\)
+`, buf.String())
+
+	err = flagset.Parse([]string{"--no-default",
+		"k1=v1",
+		"--no-default",
+		"k2=v2,k3=v3\nk4=v4\n",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2", "k3": "v3", "k4": "v4"}, config.NoDefault)
+	assert.Equal(t, map[string]string{"fruit": "apple", "veggie": "carrot"}, config.TagDefault)
+}
+
+func TestParsedMaps(t *testing.T) {
+	type Config struct {
+		Limits   map[string]int
+		Timeouts map[string]time.Duration `default:"read=5s,write=10s"`
+		Weights  map[string]float64
+		Colors   map[string]enumColor
+		Instance map[string]uint8
+	}
+
+	var config Config
+	config.Instance = map[string]uint8{"a": 1}
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	assert.Equal(t, `
+  -colors value
+    	
+  -instance value
+    	 (default a=1)
+  -limits value
+    	
+  -timeouts value
+    	 (default read=5s,write=10s)
+  -weights value
+    	
+`, buf.String())
+
+	err = flagset.Parse([]string{
+		"--limits", "read=100,write=50",
+		"--limits", "delete=1",
+		"--timeouts", "write=1m",
+		"--weights", "a=0.5",
+		"--colors", "primary=red",
+		"--instance", "b=2",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"read": 100, "write": 50, "delete": 1}, config.Limits)
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second, "write": time.Minute}, config.Timeouts)
+	assert.Equal(t, map[string]float64{"a": 0.5}, config.Weights)
+	assert.Equal(t, map[string]enumColor{"primary": enumRed}, config.Colors)
+	assert.Equal(t, map[string]uint8{"a": 1, "b": 2}, config.Instance)
+
+	err = flagset.Set("limits", "read=1,write=lots")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value of key write")
+	assert.Equal(t, 100, config.Limits["read"], "no entries are set on failure")
+
+	err = flagset.Set("instance", "c=300")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value out of range")
+}
+
+func TestParsedMapInvalidDefault(t *testing.T) {
+	type Config struct {
+		Limits map[string]int `default:"read=many"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse default into map[string]int")
+}
+
+func TestUsagePlaceholders(t *testing.T) {
+	type Config struct {
+		SomeUrl string `usage:"a [URL] to configure"`
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	assert.Equal(t, `
+  -some-url URL
+    	a URL to configure
+`, buf.String())
+}
+
+func TestParse(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	var config Config
+	os.Args = []string{"app", "--host", "host-a"}
 
 	err := flagsfiller.Parse(&config)
+	assert.NoError(t, err)
+
+	require.Equal(t, "host-a", config.Host)
+}
+
+func TestParseError(t *testing.T) {
+	type Config struct {
+		BadDefault int `default:"not an int"`
+	}
+
+	var config Config
+	os.Args = []string{"app", "--bad-default", "5"}
+
+	err := flagsfiller.Parse(&config)
+	assert.Error(t, err)
+}
+
+func TestSetFromMap(t *testing.T) {
+	type Config struct {
+		Host    string
+		Timeout time.Duration
+		Tags    []string
+		Remote  struct {
+			Port int
+		}
+	}
+
+	var config Config
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = filler.SetFromMap(&flagset, map[string]string{
+		"host":        "h1",
+		"timeout":     "5s",
+		"tags":        "one,two",
+		"remote-port": "8080",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "h1", config.Host)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.Equal(t, []string{"one", "two"}, config.Tags)
+	assert.Equal(t, 8080, config.Remote.Port)
+
+	err = filler.SetFromMap(&flagset, map[string]string{"remote-port": "not a number"})
+	assert.ErrorContains(t, err, "failed to set flag remote-port")
+
+	err = filler.SetFromMap(&flagset, map[string]string{"unknown": "value"})
+	assert.ErrorContains(t, err, "failed to set flag unknown")
+}
+
+func TestParseArgs(t *testing.T) {
+	type Config struct {
+		Host    string
+		Timeout time.Duration `default:"5s"`
+	}
+
+	var config Config
+
+	flagset, err := flagsfiller.ParseArgs(&config, []string{"--host", "host-a", "extra"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "host-a", config.Host)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.Equal(t, []string{"extra"}, flagset.Args())
+	assert.Equal(t, 1, flagset.NFlag())
+}
+
+func TestParseArgsError(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	var config Config
+	var output bytes.Buffer
+
+	flagset, err := flagsfiller.ParseArgs(&config, []string{"--port", "not a number"},
+		flagsfiller.WithOutput(&output))
 	assert.Error(t, err)
+	assert.NotNil(t, flagset)
+	assert.Contains(t, output.String(), "invalid value")
+}
+
+// setOSArgs replaces os.Args for the duration of the test
+func setOSArgs(t *testing.T, args ...string) {
+	saved := os.Args
+	t.Cleanup(func() {
+		os.Args = saved
+	})
+	os.Args = args
+}
+
+func TestParseWithErrorHandling(t *testing.T) {
+	type Config struct {
+		ErrorHandlingPort int
+	}
+
+	var config Config
+	setOSArgs(t, "app", "--error-handling-port", "not a number")
+	defer flag.CommandLine.Init(os.Args[0], flag.ExitOnError)
+	defer flag.CommandLine.SetOutput(nil)
+
+	var output bytes.Buffer
+	err := flagsfiller.Parse(&config,
+		flagsfiller.WithErrorHandling(flag.ContinueOnError),
+		flagsfiller.WithOutput(&output))
+	assert.Error(t, err)
+	assert.Contains(t, output.String(), "invalid value")
+}
+
+func TestParseFlagSet(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	var config Config
+	setOSArgs(t, "app", "--host", "host-a", "extra")
+
+	flagset, err := flagsfiller.ParseFlagSet(&config, flagsfiller.WithErrorHandling(flag.ContinueOnError))
+	require.NoError(t, err)
+
+	assert.Equal(t, "host-a", config.Host)
+	assert.Equal(t, []string{"extra"}, flagset.Args())
+	assert.Equal(t, flag.ContinueOnError, flagset.ErrorHandling())
+}
+
+func TestMustParse(t *testing.T) {
+	type Config struct {
+		MustParseHost string
+	}
+
+	var config Config
+	setOSArgs(t, "app", "--must-parse-host", "host-a")
+
+	flagsfiller.MustParse(&config)
+
+	assert.Equal(t, "host-a", config.MustParseHost)
+}
+
+func TestMustParseFailure(t *testing.T) {
+	if os.Getenv("MUST_PARSE_FAILURE") == "1" {
+		type Config struct {
+			BadDefault int `default:"not an int"`
+		}
+		var config Config
+		setOSArgs(t, "app")
+		flagsfiller.MustParse(&config)
+		return
+	}
+
+	// os.Args is replaced by other tests, so locate the test binary directly
+	testBinary, err := os.Executable()
+	require.NoError(t, err)
+
+	cmd := exec.Command(testBinary, "-test.run=^TestMustParseFailure$")
+	cmd.Env = append(os.Environ(), "MUST_PARSE_FAILURE=1")
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Contains(t, string(output), "failed to parse default into int")
+	assert.Contains(t, string(output), "Usage of app")
 }
 
 func TestIgnoreNonExportedFields(t *testing.T) {
@@ -779,6 +1818,143 @@ func TestWithEnvOverride(t *testing.T) {
 `, buf.String())
 }
 
+func TestWithEnvFallbackNames(t *testing.T) {
+	type Config struct {
+		Host string `env:"NEW_HOST,OLD_HOST"`
+		Port string `env:"NEW_PORT,OLD_PORT"`
+	}
+
+	var config Config
+
+	t.Setenv("OLD_HOST", "old host")
+	t.Setenv("NEW_PORT", "new port")
+	t.Setenv("OLD_PORT", "old port")
+
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(flagset)
+
+	assert.Equal(t, `
+  -host string
+    	 (env NEW_HOST, OLD_HOST)
+  -port string
+    	 (env NEW_PORT, OLD_PORT)
+`, buf.String())
+
+	assert.Equal(t, "old host", config.Host)
+	assert.Equal(t, "new port", config.Port)
+}
+
+func TestWithStrictEnv(t *testing.T) {
+	type Config struct {
+		Timeout time.Duration
+		Remote  struct {
+			Host string
+		}
+		Legacy string `env:"STRICT_LEGACY_NAME"`
+	}
+
+	t.Run("all known", func(t *testing.T) {
+		t.Setenv("STRICT_TIMEOUT", "5s")
+		t.Setenv("STRICT_REMOTE_HOST", "h1")
+		t.Setenv("STRICT_LEGACY_NAME", "legacy")
+		t.Setenv("STRICTER_TIMEOUT", "ignored since no prefix")
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithEnv("Strict"), flagsfiller.WithStrictEnv())
+
+		var flagset flag.FlagSet
+		err := filler.Fill(&flagset, &config)
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, config.Timeout)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Setenv("STRICT_TIMEOUTT", "5s")
+		t.Setenv("STRICT_REMOTE_HOTS", "h1")
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithEnv("Strict"), flagsfiller.WithStrictEnv())
+
+		var flagset flag.FlagSet
+		err := filler.Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Equal(t, "unknown environment variables with prefix STRICT_: STRICT_REMOTE_HOTS, STRICT_TIMEOUTT", err.Error())
+	})
+}
+
+func TestWithEnvPrefixTag(t *testing.T) {
+	type Database struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Database Database  `envPrefix:"DB"`
+		Replica  *Database `envPrefix:"DbReplica"`
+		Cache    struct {
+			Host string
+		}
+	}
+
+	var config Config
+
+	t.Setenv("NESTED_DB_HOST", "db host")
+	t.Setenv("NESTED_DB_PORT", "5432")
+	t.Setenv("NESTED_DB_REPLICA_HOST", "replica host")
+	t.Setenv("NESTED_CACHE_HOST", "cache host")
+
+	filler := flagsfiller.New(flagsfiller.WithEnv("Nested"))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "db host", config.Database.Host)
+	assert.Equal(t, 5432, config.Database.Port)
+	assert.Equal(t, "replica host", config.Replica.Host)
+	assert.Equal(t, "cache host", config.Cache.Host)
+	assert.Equal(t, " (env NESTED_DB_HOST)", flagset.Lookup("database-host").Usage)
+}
+
+func TestWithEnvNameMapper(t *testing.T) {
+	type Config struct {
+		Database struct {
+			Host string
+			Port int
+		}
+		Debug bool
+	}
+
+	var config Config
+
+	t.Setenv("PGHOST", "pg host")
+	t.Setenv("PGPORT", "5432")
+	t.Setenv("MAPPER_DEBUG", "true")
+
+	filler := flagsfiller.New(
+		flagsfiller.WithEnv("Mapper"),
+		flagsfiller.WithEnvNameMapper(func(fieldPath, defaultName string) string {
+			if strings.HasPrefix(fieldPath, "Database.") {
+				return "PG" + strings.ToUpper(strings.TrimPrefix(fieldPath, "Database."))
+			}
+			return defaultName
+		}),
+	)
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "pg host", config.Database.Host)
+	assert.Equal(t, 5432, config.Database.Port)
+	assert.True(t, config.Debug)
+	assert.Equal(t, " (env PGPORT)", flagset.Lookup("database-port").Usage)
+}
+
 func TestWithEnvOverrideDisable(t *testing.T) {
 	type Config struct {
 		Host string `env:"" usage:"arg only"`
@@ -800,6 +1976,124 @@ func TestWithEnvOverrideDisable(t *testing.T) {
 `, buf.String())
 }
 
+func TestWithDeferredSources(t *testing.T) {
+	type Config struct {
+		Host    string `default:"localhost"`
+		Port    int
+		Timeout time.Duration
+	}
+
+	var config Config
+
+	t.Setenv("DEFER_HOST", "host from env")
+	t.Setenv("DEFER_PORT", "8080")
+
+	filler := flagsfiller.New(flagsfiller.WithEnv("Defer"), flagsfiller.WithDeferredSources())
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	// not applied during Fill
+	assert.Equal(t, "localhost", config.Host)
+	assert.Equal(t, 0, config.Port)
+
+	// programmatic changes after Fill do not replace environment values
+	config.Host = "programmatic"
+	config.Timeout = 5 * time.Second
+
+	err = filler.ParseWithSources(&flagset, []string{"--port", "9090"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "host from env", config.Host)
+	assert.Equal(t, 9090, config.Port)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+}
+
+func TestWithDeferredSourcesBadValue(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	var config Config
+
+	t.Setenv("DEFER_PORT", "not a number")
+
+	filler := flagsfiller.New(flagsfiller.WithEnv("Defer"), flagsfiller.WithDeferredSources())
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = filler.ParseWithSources(&flagset, []string{})
+	assert.ErrorContains(t, err, "failed to set from environment variable DEFER_PORT")
+}
+
+func TestWithEnvErrorHandler(t *testing.T) {
+	type Config struct {
+		Port    int `default:"8080"`
+		Timeout time.Duration
+		Host    string
+	}
+
+	var config Config
+
+	t.Setenv("WARN_PORT", "not a number")
+	t.Setenv("WARN_TIMEOUT", "forever")
+	t.Setenv("WARN_HOST", "h1")
+
+	var envErrs []error
+	filler := flagsfiller.New(
+		flagsfiller.WithEnv("Warn"),
+		flagsfiller.WithEnvErrorHandler(func(err error) {
+			envErrs = append(envErrs, err)
+		}),
+	)
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	require.Len(t, envErrs, 2)
+	assert.ErrorContains(t, envErrs[0], "WARN_PORT")
+	assert.ErrorContains(t, envErrs[1], "WARN_TIMEOUT")
+
+	assert.Equal(t, 8080, config.Port)
+	assert.Equal(t, time.Duration(0), config.Timeout)
+	assert.Equal(t, "h1", config.Host)
+}
+
+func TestWithEnvErrorHandlerRestoresCollections(t *testing.T) {
+	type Config struct {
+		Ids    []int          `default:"1,2" max-occurs:"2"`
+		Limits map[string]int `default:"a=1"`
+	}
+
+	t.Setenv("RESTORE_IDS", "3,x")
+	t.Setenv("RESTORE_LIMITS", "b=2,c=x")
+
+	var config Config
+	var envErrs []error
+	filler := flagsfiller.New(
+		flagsfiller.WithEnv("Restore"),
+		flagsfiller.WithEnvErrorHandler(func(err error) {
+			envErrs = append(envErrs, err)
+		}),
+	)
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	require.Len(t, envErrs, 2)
+
+	assert.Equal(t, []int{1, 2}, config.Ids)
+	assert.Equal(t, map[string]int{"a": 1}, config.Limits)
+
+	// the restore doesn't count towards the max occurrences
+	err = flagset.Parse([]string{"--ids", "5,6"})
+	require.NoError(t, err)
+}
+
 func TestNoSetFromEnv(t *testing.T) {
 	type Config struct {
 		Host string `usage:"arg only"`
@@ -881,3 +2175,335 @@ func ExampleWithEnv() {
 	// Output:
 	// from env
 }
+
+func TestWithRequiredUsage(t *testing.T) {
+	type Config struct {
+		Host   string `usage:"the host"`
+		Port   int
+		Remote struct {
+			Timeout time.Duration
+			Retries int `usage:"retry attempts"`
+		}
+		Ignored string `flag:""`
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithRequiredUsage())
+	err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	require.Error(t, err)
+	assert.Equal(t, "fields are missing a usage tag: Port, Remote.Timeout", err.Error())
+
+	filler = flagsfiller.New()
+	err = filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	require.NoError(t, err)
+}
+
+func TestFlagNameFor(t *testing.T) {
+	type Config struct {
+		Host   string `flag:"server"`
+		Remote struct {
+			Auth struct {
+				Username string
+			}
+		}
+		Ignored string `flag:""`
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithFieldRenamer(flagsfiller.DotRenamer()))
+	err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	require.NoError(t, err)
+
+	name, ok := filler.FlagNameFor("Remote.Auth.Username")
+	assert.True(t, ok)
+	assert.Equal(t, "remote.auth.username", name)
+
+	name, ok = filler.FlagNameFor("Host")
+	assert.True(t, ok)
+	assert.Equal(t, "server", name)
+
+	_, ok = filler.FlagNameFor("Ignored")
+	assert.False(t, ok)
+	_, ok = filler.FlagNameFor("Remote.Auth")
+	assert.False(t, ok)
+}
+
+func TestFieldFor(t *testing.T) {
+	type Config struct {
+		Remote struct {
+			Auth struct {
+				Username string `aliases:"u"`
+			}
+		}
+		Port int
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+	err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	require.NoError(t, err)
+
+	field, ok := filler.FieldFor("remote-auth-username")
+	require.True(t, ok)
+	assert.Equal(t, "Remote.Auth.Username", field.Path)
+	assert.Equal(t, "Username", field.StructField.Name)
+	assert.Equal(t, "u", field.StructField.Tag.Get("aliases"))
+
+	*field.Value.(*string) = "admin"
+	assert.Equal(t, "admin", config.Remote.Auth.Username)
+
+	field, ok = filler.FieldFor("u")
+	require.True(t, ok)
+	assert.Equal(t, "Remote.Auth.Username", field.Path)
+
+	field, ok = filler.FieldFor("port")
+	require.True(t, ok)
+	assert.Same(t, &config.Port, field.Value)
+
+	_, ok = filler.FieldFor("unknown")
+	assert.False(t, ok)
+}
+
+func TestFillSameTypeRepeatedly(t *testing.T) {
+	type Config struct {
+		Host   string `default:"localhost"`
+		Remote struct {
+			Port    int `default:"8080"`
+			Ignored int `flag:""`
+		} `envPrefix:"Upstream"`
+	}
+
+	t.Setenv("REPEAT_UPSTREAM_PORT", "9090")
+
+	for i := 0; i < 3; i++ {
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithEnv("Repeat"))
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		err := filler.Fill(flagset, &config)
+		require.NoError(t, err)
+
+		err = flagset.Parse([]string{"--host", "example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", config.Host)
+		assert.Equal(t, 9090, config.Remote.Port)
+		assert.Nil(t, flagset.Lookup("remote-ignored"))
+	}
+}
+
+func TestWithConflictStrategy(t *testing.T) {
+	type Base struct {
+		Host    string `default:"localhost" aliases:"h"`
+		Verbose bool
+	}
+	type Extended struct {
+		Host string `default:"example.com" aliases:"h,H"`
+		Port int    `default:"8080"`
+	}
+
+	t.Run("error", func(t *testing.T) {
+		var base Base
+		var extended Extended
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, flagsfiller.New().Fill(flagset, &base))
+
+		err := flagsfiller.New().Fill(flagset, &extended)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "flag host of field Host is already defined")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		var base Base
+		var extended Extended
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		filler := flagsfiller.New(flagsfiller.WithConflictStrategy(flagsfiller.ConflictSkip))
+		require.NoError(t, filler.Fill(flagset, &base))
+		require.NoError(t, filler.Fill(flagset, &extended))
+
+		err := flagset.Parse([]string{"--host", "h1", "--port", "9090"})
+		require.NoError(t, err)
+		assert.Equal(t, "h1", base.Host)
+		assert.Equal(t, "", extended.Host)
+		assert.Equal(t, 9090, extended.Port)
+		assert.Nil(t, flagset.Lookup("H"))
+	})
+
+	t.Run("rebind", func(t *testing.T) {
+		var base Base
+		var extended Extended
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		filler := flagsfiller.New(flagsfiller.WithConflictStrategy(flagsfiller.ConflictRebind))
+		require.NoError(t, filler.Fill(flagset, &base))
+		require.NoError(t, filler.Fill(flagset, &extended))
+
+		assert.Equal(t, "example.com", flagset.Lookup("h").DefValue)
+
+		err := flagset.Parse([]string{"-h", "h1", "--verbose"})
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", base.Host)
+		assert.True(t, base.Verbose)
+		assert.Equal(t, "h1", extended.Host)
+		assert.Equal(t, "h1", flagset.Lookup("H").Value.String())
+	})
+
+	t.Run("refill same struct", func(t *testing.T) {
+		var base Base
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		filler := flagsfiller.New(flagsfiller.WithConflictStrategy(flagsfiller.ConflictRebind))
+		require.NoError(t, filler.Fill(flagset, &base))
+		require.NoError(t, filler.Fill(flagset, &base))
+
+		err := flagset.Parse([]string{"--host", "h1"})
+		require.NoError(t, err)
+		assert.Equal(t, "h1", base.Host)
+	})
+
+	t.Run("refill is idempotent", func(t *testing.T) {
+		type Config struct {
+			Host    string            `required:"true"`
+			Token   string            `validate:"nonempty"`
+			Port    int               `requires:"Host"`
+			Tls     bool              `enables:"Cert"`
+			Cert    string            `default-if:"Tls=true:cert.pem"`
+			Tags    []string          `default:"a"`
+			Labels  map[string]string `usage:"the labels"`
+			Comment string
+		}
+
+		t.Setenv("REFILL_TAGS", "b")
+
+		var config Config
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		filler := flagsfiller.New(flagsfiller.WithConflictStrategy(flagsfiller.ConflictRebind),
+			flagsfiller.WithEnv("Refill"), flagsfiller.WithDeferredSources())
+		require.NoError(t, filler.Fill(flagset, &config))
+		require.NoError(t, filler.Fill(flagset, &config))
+
+		err := filler.ParseWithSources(flagset, []string{"--port", "1", "--tls"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required flags are not set: -host")
+		assert.NotContains(t, err.Error(), "-host, -host")
+		assert.Equal(t, 1, strings.Count(err.Error(), "-token"), err.Error())
+		assert.Equal(t, 1, strings.Count(err.Error(), "requires"), err.Error())
+		// the environment variable is applied once
+		assert.Equal(t, []string{"a", "b"}, config.Tags)
+		assert.Equal(t, "cert.pem", config.Cert)
+
+		filler = flagsfiller.New(flagsfiller.WithConflictStrategy(flagsfiller.ConflictRebind),
+			flagsfiller.WithRequiredUsage())
+		flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+		require.Error(t, filler.Fill(flagset, &config))
+		err = filler.Fill(flagset, &config)
+		assert.ErrorContains(t, err, "fields are missing a usage tag: Host, Token, Port, Tls, Cert, Tags, Comment")
+	})
+}
+
+func TestRecursiveStructPointers(t *testing.T) {
+	type Node struct {
+		Name string
+		Next *Node
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		var config Node
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field Next recursively refers to flagsfiller_test.Node")
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		type Config struct {
+			Name     string
+			Override *Config `flag:""`
+		}
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+		assert.Nil(t, config.Override)
+		assert.NotNil(t, flagset.Lookup("name"))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		type Config struct {
+			Name string
+			Self *Config
+		}
+		var config Config
+		config.Self = &config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field Self points at a flagsfiller_test.Config that contains it")
+	})
+}
+
+func TestNestedNameSeparator(t *testing.T) {
+	type Config struct {
+		Remote struct {
+			MaxTimeout time.Duration
+			Auth       struct {
+				Username string
+			}
+		}
+		TopLevel string
+	}
+
+	tests := []struct {
+		name    string
+		options []flagsfiller.FillerOption
+		flags   []string
+		envs    []string
+	}{
+		{
+			name:    "dot",
+			options: []flagsfiller.FillerOption{flagsfiller.WithNestedNameSeparator(".")},
+			flags:   []string{"remote.max-timeout", "remote.auth.username", "top-level"},
+		},
+		{
+			name: "camel case",
+			options: []flagsfiller.FillerOption{
+				flagsfiller.WithFieldRenamer(flagsfiller.LowerCamelRenamer()),
+				flagsfiller.WithNestedNameSeparator("."),
+			},
+			flags: []string{"remote.maxTimeout", "remote.auth.username", "topLevel"},
+		},
+		{
+			name: "suffix renamer",
+			options: []flagsfiller.FillerOption{
+				flagsfiller.WithFieldRenamer(flagsfiller.CompositeRenamer(
+					flagsfiller.KebabRenamer(),
+					func(name string) string { return name + "-x" },
+				)),
+				flagsfiller.WithNestedNameSeparator("."),
+			},
+			flags: []string{"remote-x.max-timeout-x", "remote-x.auth-x.username-x", "top-level-x"},
+		},
+		{
+			name: "env",
+			options: []flagsfiller.FillerOption{
+				flagsfiller.WithEnv("App"),
+				flagsfiller.WithEnvNestedNameSeparator("__"),
+			},
+			flags: []string{"remote-max-timeout", "remote-auth-username", "top-level"},
+			envs:  []string{"APP_REMOTE__MAX_TIMEOUT", "APP_REMOTE__AUTH__USERNAME", "APP_TOP_LEVEL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			filler := flagsfiller.New(tt.options...)
+
+			var flagset flag.FlagSet
+			err := filler.Fill(&flagset, &config)
+			require.NoError(t, err)
+
+			for _, name := range tt.flags {
+				assert.NotNil(t, flagset.Lookup(name), name)
+			}
+			for _, env := range tt.envs {
+				assert.Contains(t, grabUsage(flagset).String(), env)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package flagsfillertest provides helpers for testing config structs that are filled by
+// flagsfiller, much like net/http/httptest does for HTTP handlers.
+//
+// A typical test parses arguments and environment variables into a config struct and asserts on
+// the resulting values by field path:
+//
+//	func TestConfig(t *testing.T) {
+//		var config Config
+//		flagsfillertest.Parse(t, &config,
+//			map[string]string{"APP_HOST": "example.com"},
+//			[]string{"--port", "9090"},
+//			flagsfiller.WithEnv("App"))
+//
+//		flagsfillertest.AssertValues(t, &config, map[string]string{
+//			"Host": "example.com",
+//			"Port": "9090",
+//		})
+//	}
+package flagsfillertest
+
+import (
+	"flag"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+)
+
+// Fill fills a new flag.FlagSet from the given struct reference with the given options and
+// returns it. The test fails immediately when the struct could not be filled.
+func Fill(t testing.TB, from interface{}, options ...flagsfiller.FillerOption) *flag.FlagSet {
+	t.Helper()
+
+	flagSet := newFlagSet()
+	err := flagsfiller.New(options...).Fill(flagSet, from)
+	if err != nil {
+		t.Fatalf("failed to fill flags: %v", err)
+	}
+	return flagSet
+}
+
+// Parse sets the given environment variables for the duration of the test, fills a new
+// flag.FlagSet from the given struct reference with the given options, and parses the given args.
+// The test fails immediately when the struct could not be filled or the args could not be parsed.
+func Parse(t testing.TB, from interface{}, env map[string]string, args []string, options ...flagsfiller.FillerOption) *flag.FlagSet {
+	t.Helper()
+
+	flagSet, err := TryParse(t, from, env, args, options...)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	return flagSet
+}
+
+// TryParse is like Parse, but returns the error from filling or parsing, which allows for
+// testing invalid arguments and environment variables.
+func TryParse(t testing.TB, from interface{}, env map[string]string, args []string, options ...flagsfiller.FillerOption) (*flag.FlagSet, error) {
+	t.Helper()
+
+	SetEnv(t, env)
+	filler := flagsfiller.New(options...)
+	flagSet := newFlagSet()
+	err := filler.Fill(flagSet, from)
+	if err != nil {
+		return flagSet, err
+	}
+	return flagSet, filler.ParseWithSources(flagSet, args)
+}
+
+// SetEnv sets the given environment variables for the duration of the test, after which the
+// previous values are restored. Like testing.T.Setenv, it cannot be used in parallel tests.
+func SetEnv(t testing.TB, env map[string]string) {
+	t.Helper()
+
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+// Usage returns the usage of the flags in flagSet as rendered by flag.PrintDefaults.
+func Usage(flagSet *flag.FlagSet) string {
+	var sb strings.Builder
+	output := flagSet.Output()
+	flagSet.SetOutput(&sb)
+	defer flagSet.SetOutput(output)
+
+	flagSet.PrintDefaults()
+	return sb.String()
+}
+
+// AssertValues checks the values of the fields of the given struct reference against expected,
+// which is keyed by field path, such as "Remote.Auth.Username". The values are compared in the
+// form accepted by the fields' flags, such as "5s" for a time.Duration or "a,b" for a []string,
+// where the values of fields declared with `sensitive:"true"` are redacted. Fields that are not
+// in expected are not checked.
+func AssertValues(t testing.TB, from interface{}, expected map[string]string) bool {
+	t.Helper()
+
+	actual := flagsfiller.EffectiveConfig(from)
+	paths := make([]string, 0, len(expected))
+	for path := range expected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ok := true
+	for _, path := range paths {
+		value, exists := actual[path]
+		if !exists {
+			t.Errorf("field %s does not exist", path)
+			ok = false
+		} else if value != expected[path] {
+			t.Errorf("field %s is %q, expected %q", path, value, expected[path])
+			ok = false
+		}
+	}
+	return ok
+}
+
+// newFlagSet creates a flag set that returns parsing errors rather than exiting
+func newFlagSet() *flag.FlagSet {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	return flagSet
+}
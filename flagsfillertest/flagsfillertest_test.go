@@ -0,0 +1,90 @@
+package flagsfillertest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/itzg/go-flagsfiller/flagsfillertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Host    string `default:"localhost" usage:"the host to access"`
+	Port    int
+	Timeout time.Duration `default:"5s"`
+	Tags    []string
+	Remote  struct {
+		Password string `sensitive:"true"`
+	}
+}
+
+func TestParse(t *testing.T) {
+	var cfg config
+	flagsfillertest.Parse(t, &cfg,
+		map[string]string{"FFT_HOST": "example.com"},
+		[]string{"--port", "9090", "--tags", "a,b", "--remote-password", "secret"},
+		flagsfiller.WithEnv("Fft"))
+
+	flagsfillertest.AssertValues(t, &cfg, map[string]string{
+		"Host":            "example.com",
+		"Port":            "9090",
+		"Timeout":         "5s",
+		"Tags":            "a,b",
+		"Remote.Password": "*****",
+	})
+}
+
+func TestTryParse(t *testing.T) {
+	var cfg config
+	_, err := flagsfillertest.TryParse(t, &cfg,
+		map[string]string{"FFT_TRY_PORT": "not a number"},
+		nil,
+		flagsfiller.WithEnv("FftTry"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FFT_TRY_PORT")
+
+	_, err = flagsfillertest.TryParse(t, &cfg, nil, []string{"--unknown"})
+	require.Error(t, err)
+}
+
+func TestUsage(t *testing.T) {
+	var cfg config
+	flagSet := flagsfillertest.Fill(t, &cfg)
+
+	usage := flagsfillertest.Usage(flagSet)
+	assert.Contains(t, usage, "  -host string\n    \tthe host to access (default \"localhost\")\n")
+	assert.Equal(t, 5, strings.Count(usage, "  -"))
+}
+
+func TestAssertValuesReportsMismatches(t *testing.T) {
+	var cfg config
+	flagsfillertest.Fill(t, &cfg)
+
+	recorder := &recordingT{TB: t}
+	ok := flagsfillertest.AssertValues(recorder, &cfg, map[string]string{
+		"Host":    "example.com",
+		"Missing": "",
+		"Timeout": "5s",
+	})
+	assert.False(t, ok)
+	assert.Equal(t, []string{
+		`field Host is "localhost", expected "example.com"`,
+		"field Missing does not exist",
+	}, recorder.errors)
+}
+
+// recordingT captures the errors reported by an assertion rather than failing the test
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
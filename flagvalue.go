@@ -0,0 +1,43 @@
+// This file implements support for all types that already implement flag.Value
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+var flagValueIface = reflect.TypeOf((*flag.Value)(nil)).Elem()
+
+// processFlagValue declares the field itself as the flag's value, where the default is applied
+// by calling its Set method
+func processFlagValue(_ reflect.StructTag, fieldRef interface{},
+	hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string,
+	usage string) error {
+	v, ok := fieldRef.(flag.Value)
+	if !ok {
+		return fmt.Errorf("can't cast %v into flag.Value", fieldRef)
+	}
+	if hasDefaultTag {
+		err := v.Set(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default value into %v: %w", reflect.TypeOf(fieldRef), err)
+		}
+	}
+	flagSet.Var(v, renamed, usage)
+	return nil
+}
+
+// RegisterFlagValue registers fields of type T to be declared with the flag.Value returned by
+// newValue for each field. Unlike RegisterSimpleType, which replaces the field with each parsed
+// value, the flag.Value can update the field in place, such as for types that share state with
+// the values copied from them.
+func RegisterFlagValue[T any](newValue func(field *T) flag.Value) {
+	registerHandler(getTypeName(reflect.TypeOf(*new(T))), func(_ reflect.StructTag, fieldRef interface{},
+		hasDefaultTag bool, tagDefault string,
+		flagSet *flag.FlagSet, renamed string,
+		usage string) error {
+		return processFlagValue("", newValue(fieldRef.(*T)), hasDefaultTag, tagDefault, flagSet, renamed, usage)
+	})
+}
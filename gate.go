@@ -0,0 +1,157 @@
+package flagsfiller
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// gate is declared by the enables tag of the bool field at path, where enables holds the names or
+// paths of the fields or nested structs that are only used when the field is true
+type gate struct {
+	path    string
+	enables []string
+	// resolved are the paths of the enabled fields and nested structs
+	resolved []string
+}
+
+// resolveGates resolves the fields and nested structs named by enables tags, which are either the
+// names of siblings or full paths, and reports the ones that did not declare any flag
+func (f *FlagSetFiller) resolveGates() error {
+	var errs []error
+	for i := range f.gates {
+		g := &f.gates[i]
+		g.resolved = g.resolved[:0]
+		for _, name := range g.enables {
+			resolved, exists := f.resolveGroupPath(g.path, name)
+			if !exists {
+				errs = append(errs, fmt.Errorf("field %s enables unknown field %s", g.path, name))
+				continue
+			}
+			g.resolved = append(g.resolved, resolved)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveGroupPath is like resolveFieldPath, but also resolves the names of nested structs
+func (f *FlagSetFiller) resolveGroupPath(path string, name string) (string, bool) {
+	if resolved, exists := f.resolveFieldPath(path, name); exists {
+		return resolved, true
+	}
+	candidates := []string{name}
+	if dot := strings.LastIndex(path, "."); dot >= 0 {
+		candidates = append([]string{path[:dot+1] + name}, candidates...)
+	}
+	for _, candidate := range candidates {
+		for _, declared := range f.paths {
+			if strings.HasPrefix(declared, candidate+".") {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// gateOf returns the index of the gate that enables the field at path, if any
+func (f *FlagSetFiller) gateOf(path string) (int, bool) {
+	for i, g := range f.gates {
+		if g.path == path {
+			continue
+		}
+		for _, enabled := range g.resolved {
+			if path == enabled || strings.HasPrefix(path, enabled+".") {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// gateEnabled reports the current value of the bool field of the gate
+func (f *FlagSetFiller) gateEnabled(g gate) bool {
+	enabled, _ := f.fields[g.path].Value.(*bool)
+	return enabled != nil && *enabled
+}
+
+// validateGates reports the fields that were set while the field enabling them is false
+func (f *FlagSetFiller) validateGates(set map[string]bool) []error {
+	var errs []error
+	for _, path := range f.paths {
+		if !set[path] {
+			continue
+		}
+		if i, gated := f.gateOf(path); gated && !f.gateEnabled(f.gates[i]) {
+			errs = append(errs, fmt.Errorf("flag -%s is only used when -%s is enabled",
+				f.fieldFlags[path], f.fieldFlags[f.gates[i].path]))
+		}
+	}
+	return errs
+}
+
+// printGroupedDefaults prints the defaults of the flags of flagSet like flag.FlagSet.PrintDefaults,
+// where the flags enabled by a gate are listed in a group after the other flags. With the
+// WithGroupedUsage option, the other flags of nested structs are also grouped under headings
+// derived from the structs' field names, such as "Remote Auth:". The flags for which hide
+// returns true are omitted.
+func (f *FlagSetFiller) printGroupedDefaults(flagSet *flag.FlagSet, hide func(name string) bool) {
+	newGroup := func() *flag.FlagSet {
+		group := flag.NewFlagSet(flagSet.Name(), flag.ContinueOnError)
+		group.SetOutput(flagSet.Output())
+		return group
+	}
+	ungrouped := newGroup()
+	gates := make([]*flag.FlagSet, len(f.gates))
+	sections := make(map[string]*flag.FlagSet)
+	flagSet.VisitAll(func(declared *flag.Flag) {
+		if hide(declared.Name) {
+			return
+		}
+		target := ungrouped
+		path := f.flagPaths[declared.Name]
+		if i, gated := f.gateOf(path); gated {
+			if gates[i] == nil {
+				gates[i] = newGroup()
+			}
+			target = gates[i]
+		} else if section := usageSection(path); f.options.groupedUsage && section != "" {
+			if sections[section] == nil {
+				sections[section] = newGroup()
+			}
+			target = sections[section]
+		}
+		target.Var(declared.Value, declared.Name, declared.Usage)
+		target.Lookup(declared.Name).DefValue = declared.DefValue
+	})
+
+	ungrouped.PrintDefaults()
+	// the sections are listed in the order their structs were declared
+	printed := make(map[string]bool)
+	for _, path := range f.paths {
+		section := usageSection(path)
+		if sections[section] == nil || printed[section] {
+			continue
+		}
+		printed[section] = true
+		_, _ = fmt.Fprintf(flagSet.Output(), "\n%s:\n", section)
+		sections[section].PrintDefaults()
+	}
+	for i, group := range gates {
+		if group == nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(flagSet.Output(), "\nFlags enabled by -%s:\n", f.fieldFlags[f.gates[i].path])
+		group.PrintDefaults()
+	}
+}
+
+// usageSection returns the heading of the nested struct declaring the field at the given path,
+// such as "Remote Auth" for "Remote.Auth.Username", or an empty string for top-level fields
+func usageSection(path string) string {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return ""
+	}
+	return strings.ReplaceAll(path[:i], ".", " ")
+}
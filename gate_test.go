@@ -0,0 +1,99 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type gatedConfig struct {
+	Host    string `usage:"the host to access"`
+	Tracing bool   `usage:"enable tracing" enables:"Trace"`
+	Trace   struct {
+		Endpoint string `usage:"the collector endpoint"`
+	}
+	Metrics struct {
+		Enabled bool   `usage:"enable metrics" enables:"Metrics"`
+		Port    int    `default:"9100" usage:"the metrics port"`
+		Path    string `usage:"the metrics path"`
+	}
+}
+
+func TestEnablesTag(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		var config gatedConfig
+		_, err := flagsfiller.ParseArgs(&config, []string{"--metrics-enabled", "--metrics-port", "9200",
+			"--tracing", "--trace-endpoint", "collector:4317"})
+		require.NoError(t, err)
+		assert.Equal(t, 9200, config.Metrics.Port)
+		assert.Equal(t, "collector:4317", config.Trace.Endpoint)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		var config gatedConfig
+		_, err := flagsfiller.ParseArgs(&config, []string{"--metrics-port", "9200", "--trace-endpoint", "collector:4317"})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "flag -metrics-port is only used when -metrics-enabled is enabled")
+		assert.ErrorContains(t, err, "flag -trace-endpoint is only used when -tracing is enabled")
+	})
+
+	t.Run("enabled by env", func(t *testing.T) {
+		t.Setenv("GATE_METRICS_ENABLED", "true")
+		var config gatedConfig
+		_, err := flagsfiller.ParseArgs(&config, []string{"--metrics-path", "/m"}, flagsfiller.WithEnv("Gate"))
+		require.NoError(t, err)
+		assert.Equal(t, "/m", config.Metrics.Path)
+	})
+
+	t.Run("help", func(t *testing.T) {
+		var config gatedConfig
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseArgs(&config, []string{"--help"}, flagsfiller.WithOutput(&output))
+		require.ErrorIs(t, err, flag.ErrHelp)
+
+		assert.Equal(t, "Usage of "+os.Args[0]+`:
+  -host string
+    	the host to access
+  -metrics-enabled
+    	enable metrics
+  -tracing
+    	enable tracing
+
+Flags enabled by -tracing:
+  -trace-endpoint string
+    	the collector endpoint
+
+Flags enabled by -metrics-enabled:
+  -metrics-path string
+    	the metrics path
+  -metrics-port int
+    	the metrics port (default 9100)
+`, output.String())
+	})
+}
+
+func TestEnablesTagInvalid(t *testing.T) {
+	t.Run("unknown", func(t *testing.T) {
+		type Config struct {
+			Metrics bool `enables:"Metric"`
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.EqualError(t, err, "field Metrics enables unknown field Metric")
+	})
+
+	t.Run("not bool", func(t *testing.T) {
+		type Config struct {
+			Mode string `enables:"Port"`
+			Port int
+		}
+		var config Config
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "enables tag of field Mode is only supported on bool fields")
+	})
+}
@@ -8,7 +8,7 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
-	"strings"
+	"sync"
 )
 
 // this is a list of additional supported types(include struct), like time.Time, that walkFields() won't walk into,
@@ -16,10 +16,78 @@ import (
 // each supported type need to be added in this map in init()
 var extendedTypes = make(map[string]handlerFunc)
 
+// extendedTypesMu guards extendedTypes, since types can be registered while filling
+var extendedTypesMu sync.RWMutex
+
+// typeHandlers caches the handlers resolved by handlerFor, keyed by the pointer type of a field,
+// where unsupported types map to a nil handler
+var typeHandlers sync.Map
+
+// registerHandler adds the handler of the named type to extendedTypes and discards the
+// previously resolved handlers
+func registerHandler(typeName string, handler handlerFunc) {
+	extendedTypesMu.Lock()
+	defer extendedTypesMu.Unlock()
+	extendedTypes[typeName] = handler
+	typeHandlers.Range(func(key, _ any) bool {
+		typeHandlers.Delete(key)
+		return true
+	})
+}
+
+// handlerFor resolves the handler of fields with the given pointer type from extendedTypes,
+// falling back to processFlagValue for types implementing flag.Value and then to
+// processTextUnmarshaler for types implementing encoding.TextUnmarshaler.
+// Returns nil if the type is not supported.
+func handlerFor(ptrType reflect.Type) handlerFunc {
+	if cached, ok := typeHandlers.Load(ptrType); ok {
+		return cached.(handlerFunc)
+	}
+	// hold the lock until the handler is cached, so a concurrent registerHandler can't be missed
+	extendedTypesMu.RLock()
+	defer extendedTypesMu.RUnlock()
+	handler, registered := extendedTypes[getTypeName(ptrType)]
+	switch {
+	case registered:
+	case ptrType.Implements(flagValueIface):
+		handler = processFlagValue
+	case ptrType.Implements(textUnmarshalerIface):
+		handler = processTextUnmarshaler
+	}
+	typeHandlers.Store(ptrType, handler)
+	return handler
+}
+
+// RegisterType registers the type of sample, such as a custom struct type, for the fields filled
+// by this FlagSetFiller only, where the values are parsed by converter, whose result must be
+// convertible to the type. Types registered this way take precedence over the ones registered
+// package-wide, such as by RegisterSimpleType, which remain the defaults of every filler.
+// Like the options, this should be called before Fill.
+func (f *FlagSetFiller) RegisterType(sample interface{}, converter func(s string) (interface{}, error)) {
+	if f.types == nil {
+		f.types = make(map[string]handlerFunc)
+	}
+	f.types[getTypeName(reflect.TypeOf(sample))] = func(_ reflect.StructTag, fieldRef interface{},
+		hasDefaultTag bool, tagDefault string,
+		flagSet *flag.FlagSet, renamed string,
+		usage string) error {
+		return f.processCustom(fieldRef, converter, hasDefaultTag, tagDefault, flagSet, renamed, usage)
+	}
+}
+
+// handlerFor resolves the handler of fields with the given pointer type from the types registered
+// with RegisterType, falling back to the package-level handlerFor
+func (f *FlagSetFiller) handlerFor(ptrType reflect.Type) handlerFunc {
+	if handler, registered := f.types[getTypeName(ptrType)]; registered {
+		return handler
+	}
+	return handlerFor(ptrType)
+}
+
 type handlerFunc func(tag reflect.StructTag, fieldRef interface{},
 	hasDefaultTag bool, tagDefault string,
 	flagSet *flag.FlagSet, renamed string,
-	usage string, aliases string) error
+	usage string) error
 
 type flagVal[T any] interface {
 	flag.Value
@@ -30,7 +98,7 @@ type flagVal[T any] interface {
 func processGeneral[T any](fieldRef interface{}, val flagVal[T],
 	hasDefaultTag bool, tagDefault string,
 	flagSet *flag.FlagSet, renamed string,
-	usage string, aliases string) (err error) {
+	usage string) (err error) {
 	casted := fieldRef.(*T)
 	if hasDefaultTag {
 		*casted, err = val.StrConverter(tagDefault)
@@ -40,11 +108,6 @@ func processGeneral[T any](fieldRef interface{}, val flagVal[T],
 	}
 	val.SetRef(casted)
 	flagSet.Var(val, renamed, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Var(val, alias, usage)
-		}
-	}
 	return nil
 
 }
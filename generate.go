@@ -0,0 +1,305 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+// declaredField is a field that declared a flag along with what is needed to document it
+type declaredField struct {
+	Field
+	flag      *flag.Flag
+	envNames  []string
+	usage     string
+	sensitive bool
+}
+
+// declaredFields returns the fields that declared a flag in flagSet, in declaration order
+func (f *FlagSetFiller) declaredFields(flagSet *flag.FlagSet) []declaredField {
+	unbracket := strings.NewReplacer("[", "", "]", "")
+	result := make([]declaredField, 0, len(f.paths))
+	for _, path := range f.paths {
+		declared := flagSet.Lookup(f.fieldFlags[path])
+		if declared == nil {
+			continue
+		}
+		field := f.fields[path]
+		result = append(result, declaredField{
+			Field:     field,
+			flag:      declared,
+			envNames:  f.fieldEnvs[path],
+			usage:     unbracket.Replace(field.StructField.Tag.Get("usage")),
+			sensitive: isSensitive(field.StructField.Tag),
+		})
+	}
+	return result
+}
+
+// flagAliases returns the aliases declared in flagSet keyed by the paths of their fields, where
+// deprecated aliases are omitted
+func (f *FlagSetFiller) flagAliases(flagSet *flag.FlagSet) map[string][]string {
+	aliases := make(map[string][]string)
+	flagSet.VisitAll(func(declared *flag.Flag) {
+		path, exists := f.flagPaths[declared.Name]
+		if !exists || declared.Name == f.fieldFlags[path] {
+			return
+		}
+		if _, deprecated := declared.Value.(*deprecatedValue); deprecated {
+			return
+		}
+		aliases[path] = append(aliases[path], declared.Name)
+	})
+	return aliases
+}
+
+// flagNames returns the name of the field's flag along with its aliases, where the single letter
+// names are listed first, like "-t, --timeout"
+func flagNames(field declaredField, aliases map[string][]string) []string {
+	names := append([]string{field.flag.Name}, aliases[field.Path]...)
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(names[i]) == 1 && len(names[j]) > 1
+	})
+	return names
+}
+
+// helmPath returns the dot-separated, lowerCamelCase path of the field's key in values.yaml
+func helmPath(fieldPath string) []string {
+	segments := strings.Split(fieldPath, ".")
+	for i, segment := range segments {
+		segments[i] = strcase.ToLowerCamel(segment)
+	}
+	return segments
+}
+
+// WriteHelmValues writes a values.yaml skeleton for a Helm chart to w with the fields that
+// declared a flag in flagSet, which needs to have been filled by this FlagSetFiller. Nested
+// structs become nested keys named in lowerCamelCase, the values are the flag defaults, and each
+// key is commented with its field's usage. The defaults of sensitive fields are left empty.
+func (f *FlagSetFiller) WriteHelmValues(w io.Writer, flagSet *flag.FlagSet) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range f.declaredFields(flagSet) {
+		parent := root
+		segments := helmPath(field.Path)
+		for _, segment := range segments[:len(segments)-1] {
+			parent = childMapping(parent, segment)
+		}
+		parent.Content = append(parent.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: segments[len(segments)-1], HeadComment: field.usage},
+			f.helmValueNode(field),
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}})
+	if err != nil {
+		return fmt.Errorf("failed to write values: %w", err)
+	}
+	return encoder.Close()
+}
+
+// childMapping locates or adds the mapping node under the given key of parent
+func childMapping(parent *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key && parent.Content[i+1].Kind == yaml.MappingNode {
+			return parent.Content[i+1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+	return child
+}
+
+// helmValueNode renders the field's default as a YAML node of the corresponding type
+func (f *FlagSetFiller) helmValueNode(field declaredField) *yaml.Node {
+	defValue := field.flag.DefValue
+	if field.sensitive {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+	}
+
+	fieldType := field.StructField.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	switch {
+	case fieldType == stringSliceType, parsedSliceTypes[fieldType]:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, element := range parseStringSlice(defValue, valueParsing{splitter: defaultValueSplitter, trimSpace: true}) {
+			node.Content = append(node.Content, helmScalarNode(fieldType.Elem(), element))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+
+	case fieldType == stringToStringMapType, f.isParsedMapType(fieldType):
+		entries := parseStringToStringMap(defValue, valueParsing{trimSpace: true})
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+				helmScalarNode(fieldType.Elem(), entries[key]),
+			)
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	}
+
+	return helmScalarNode(fieldType, defValue)
+}
+
+// helmScalarNode renders the given value as a YAML scalar node of the corresponding type
+func helmScalarNode(fieldType reflect.Type, defValue string) *yaml.Node {
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(defValue); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: defValue}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// types such as time.Duration are rendered as strings
+		if _, err := strconv.ParseInt(defValue, 10, 64); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: defValue}
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(defValue, 64); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: defValue}
+		}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: defValue}
+}
+
+// WriteHelmEnv writes the env section of a Deployment's container to w, which maps the values
+// written by WriteHelmValues to the environment variables of the fields, such as the entry
+//
+//	name: APP_REMOTE_HOST
+//	value: {{ .Values.remote.host | quote }}
+//
+// The flagSet needs to have been filled by this FlagSetFiller and fields without an environment
+// variable are skipped.
+func (f *FlagSetFiller) WriteHelmEnv(w io.Writer, flagSet *flag.FlagSet) error {
+	for _, field := range f.declaredFields(flagSet) {
+		if len(field.envNames) == 0 {
+			continue
+		}
+		ref := ".Values." + strings.Join(helmPath(field.Path), ".")
+
+		var value string
+		fieldType := field.StructField.Type
+		switch {
+		case fieldType == stringSliceType, parsedSliceTypes[fieldType]:
+			value = fmt.Sprintf(`{{ join "," %s | quote }}`, ref)
+		case fieldType == stringToStringMapType, f.isParsedMapType(fieldType):
+			value = fmt.Sprintf(`"{{ range $k, $v := %s }}{{ $k }}={{ $v }},{{ end }}"`, ref)
+		default:
+			value = fmt.Sprintf(`{{ %s | quote }}`, ref)
+		}
+
+		_, err := fmt.Fprintf(w, "- name: %s\n  value: %s\n", field.envNames[0], value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteComposeEnvironment writes the environment block of a docker-compose service to w, which
+// maps the environment variables of the fields that declared a flag in flagSet to their defaults.
+// Each variable is commented with its field's usage. The flagSet needs to have been filled by this
+// FlagSetFiller, fields without an environment variable are skipped, and the defaults of sensitive
+// fields are left empty. A $ in a default is written as $$ so that compose doesn't interpolate it.
+func (f *FlagSetFiller) WriteComposeEnvironment(w io.Writer, flagSet *flag.FlagSet) error {
+	environment := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range f.declaredFields(flagSet) {
+		if len(field.envNames) == 0 {
+			continue
+		}
+		value := field.flag.DefValue
+		if field.sensitive {
+			value = ""
+		}
+		// compose interpolates variables in values, where $$ is a literal $
+		value = strings.ReplaceAll(value, "$", "$$")
+		environment.Content = append(environment.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: field.envNames[0], HeadComment: field.usage},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+		)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "environment"},
+		environment,
+	}}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}})
+	if err != nil {
+		return fmt.Errorf("failed to write environment: %w", err)
+	}
+	return encoder.Close()
+}
+
+// WriteEnvFile writes the environment variables of the fields that declared a flag in flagSet
+// to w as NAME=default lines, which is compatible with systemd's EnvironmentFile. Each line is
+// preceded by comments from the field's usage. The flagSet needs to have been filled by this
+// FlagSetFiller, fields without an environment variable are skipped, and the defaults of
+// sensitive fields are left empty.
+func (f *FlagSetFiller) WriteEnvFile(w io.Writer, flagSet *flag.FlagSet) error {
+	first := true
+	for _, field := range f.declaredFields(flagSet) {
+		if len(field.envNames) == 0 {
+			continue
+		}
+
+		var sb strings.Builder
+		if !first {
+			sb.WriteString("\n")
+		}
+		first = false
+		if field.usage != "" {
+			for _, line := range strings.Split(field.usage, "\n") {
+				sb.WriteString(strings.TrimRight("# "+line, " "))
+				sb.WriteString("\n")
+			}
+		}
+		value := field.flag.DefValue
+		if field.sensitive {
+			value = ""
+		}
+		sb.WriteString(field.envNames[0])
+		sb.WriteString("=")
+		sb.WriteString(quoteEnvValue(value))
+		sb.WriteString("\n")
+
+		_, err := io.WriteString(w, sb.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quoteEnvValue double quotes the value when it contains whitespace or characters that are
+// otherwise interpreted by systemd when parsing an environment file, or by a shell sourcing it,
+// such as the $ of variable references
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n\"'\\#;$`") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(value) + `"`
+}
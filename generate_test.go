@@ -0,0 +1,230 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type generateConfig struct {
+	Host    string        `default:"localhost" usage:"the [host] to access"`
+	Port    int           `default:"8080"`
+	Debug   bool          `usage:"enables debug logging"`
+	Timeout time.Duration `default:"5s"`
+	Tags    []string      `default:"a,b"`
+	Labels  map[string]string
+	Remote  struct {
+		AuthToken string `default:"secret" sensitive:"true"`
+	}
+	Ignored string `flag:""`
+}
+
+func TestWriteHelmValues(t *testing.T) {
+	var config generateConfig
+	filler := flagsfiller.New(flagsfiller.WithEnv("App"))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteHelmValues(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# the host to access
+host: localhost
+port: 8080
+# enables debug logging
+debug: false
+timeout: 5s
+tags:
+  - a
+  - b
+labels: {}
+remote:
+  authToken: ""
+`, buf.String())
+}
+
+func TestWriteHelmValuesParsedTypes(t *testing.T) {
+	type Config struct {
+		Ports []int `default:"80,443"`
+		Ids   []int64
+		Limit map[string]int `default:"read=100"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteHelmValues(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `ports:
+  - 80
+  - 443
+ids: []
+limit:
+  read: 100
+`, buf.String())
+}
+
+func TestWriteHelmEnv(t *testing.T) {
+	var config generateConfig
+	filler := flagsfiller.New(flagsfiller.WithEnv("App"))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteHelmEnv(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `- name: APP_HOST
+  value: {{ .Values.host | quote }}
+- name: APP_PORT
+  value: {{ .Values.port | quote }}
+- name: APP_DEBUG
+  value: {{ .Values.debug | quote }}
+- name: APP_TIMEOUT
+  value: {{ .Values.timeout | quote }}
+- name: APP_TAGS
+  value: {{ join "," .Values.tags | quote }}
+- name: APP_LABELS
+  value: "{{ range $k, $v := .Values.labels }}{{ $k }}={{ $v }},{{ end }}"
+- name: APP_REMOTE_AUTH_TOKEN
+  value: {{ .Values.remote.authToken | quote }}
+`, buf.String())
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	type Config struct {
+		Host     string        `default:"localhost" usage:"the [host] to access"`
+		Timeout  time.Duration `default:"5s"`
+		Tags     []string      `default:"a,b"`
+		Token    string        `default:"secret" sensitive:"true"`
+		Greeting string        `default:"hello \"world\"" usage:"the greeting\nto use"`
+		Price    string        `default:"$5"`
+		NoEnv    string        `env:""`
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("App"))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteEnvFile(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# the host to access
+APP_HOST=localhost
+
+APP_TIMEOUT=5s
+
+APP_TAGS=a,b
+
+APP_TOKEN=
+
+# the greeting
+# to use
+APP_GREETING="hello \"world\""
+
+APP_PRICE="\$5"
+`, buf.String())
+}
+
+func TestWriteComposeEnvironment(t *testing.T) {
+	var config generateConfig
+	filler := flagsfiller.New(flagsfiller.WithEnv("App"))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteComposeEnvironment(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `environment:
+  # the host to access
+  APP_HOST: localhost
+  APP_PORT: "8080"
+  # enables debug logging
+  APP_DEBUG: "false"
+  APP_TIMEOUT: 5s
+  APP_TAGS: a,b
+  APP_LABELS: ""
+  APP_REMOTE_AUTH_TOKEN: ""
+`, buf.String())
+}
+
+func TestWriteComposeEnvironmentEscapesDollar(t *testing.T) {
+	type Config struct {
+		Price string `default:"$5"`
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("App"))
+
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteComposeEnvironment(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `environment:
+  APP_PRICE: $$5
+`, buf.String())
+}
+
+func TestWriteCompletionSpec(t *testing.T) {
+	type Config struct {
+		Verbose  bool           `aliases:"v" usage:"enables verbose output"`
+		Config   string         `complete:"file" usage:"the config file"`
+		Color    enumColor      `usage:"the color"`
+		Features bitmaskFeature `usage:"the features"`
+		Timeout  time.Duration  `aliases:"t" deprecated-aliases:"wait"`
+		Format   string         `choices:"json,text"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+	flagset := flag.NewFlagSet("/usr/bin/app", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteCompletionSpec(&buf, flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `name: app
+flags:
+  -v, --verbose: enables verbose output
+  --config=: the config file
+  --color=: the color
+  --features=: the features
+  -t, --timeout=: ""
+  --format=: ""
+completion:
+  flag:
+    config: [$files]
+    color: [blue, green, red]
+    features: [metrics, profiling, tracing, '$uniquelist(,)']
+    format: [json, text]
+`, buf.String())
+}
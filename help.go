@@ -0,0 +1,70 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// helpAllFlag is the name of the flag that shows the usage including the advanced flags
+const helpAllFlag = "help-all"
+
+// declareHelpAll declares the help-all flag and replaces the usage of flagSet to hide the
+// advanced flags, when any field declared the advanced tag. The usage is also replaced to group
+// the flags enabled by another flag, when any field declared the enables tag, and to group the
+// flags of nested structs with the WithGroupedUsage option. With the WithUsageTemplate option,
+// the usage executes the template instead, which can check the Advanced field of the flags.
+func (f *FlagSetFiller) declareHelpAll(flagSet *flag.FlagSet) {
+	if f.options.usageTemplate != nil {
+		flagSet.Usage = f.templateUsage(flagSet, f.options.usageTemplate)
+		return
+	}
+	if flagSet.Lookup(helpAllFlag) != nil {
+		return
+	}
+	if len(f.advancedFlags) > 0 {
+		flagSet.BoolFunc(helpAllFlag, "show the usage including advanced flags", func(s string) error {
+			show, err := strconv.ParseBool(s)
+			if err != nil || !show {
+				return err
+			}
+			return f.handleHelpAll(flagSet)
+		})
+	} else if len(f.gates) == 0 && !f.options.groupedUsage {
+		return
+	}
+	flagSet.Usage = func() {
+		printUsageHeader(flagSet)
+		f.printGroupedDefaults(flagSet, func(name string) bool {
+			return !f.helpAll && f.advancedFlags[name]
+		})
+	}
+}
+
+// handleHelpAll is called when the help-all flag is set. Like flag.FlagSet does for -help, it
+// prints the usage including the advanced flags and exits with status 0 or panics with
+// flag.ErrHelp according to the error handling of flagSet. With flag.ContinueOnError, it only
+// records the flag, since flag.FlagSet would report an error returned by the flag as invalid, and
+// ParseWithSources prints the usage and returns flag.ErrHelp after parsing.
+func (f *FlagSetFiller) handleHelpAll(flagSet *flag.FlagSet) error {
+	f.helpAll = true
+	switch flagSet.ErrorHandling() {
+	case flag.ExitOnError:
+		flagSet.Usage()
+		os.Exit(0)
+	case flag.PanicOnError:
+		flagSet.Usage()
+		panic(flag.ErrHelp)
+	}
+	return nil
+}
+
+// printUsageHeader prints the same header as the default usage of a flag.FlagSet
+func printUsageHeader(flagSet *flag.FlagSet) {
+	if flagSet.Name() == "" {
+		_, _ = fmt.Fprintf(flagSet.Output(), "Usage:\n")
+	} else {
+		_, _ = fmt.Fprintf(flagSet.Output(), "Usage of %s:\n", flagSet.Name())
+	}
+}
@@ -0,0 +1,153 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvancedFlags(t *testing.T) {
+	type Config struct {
+		Host        string `usage:"the host to access"`
+		BufferSize  int    `default:"4096" usage:"the size of buffers" advanced:"true" aliases:"bs"`
+		Concurrency int    `usage:"the number of workers" advanced:"true"`
+	}
+
+	t.Run("help", func(t *testing.T) {
+		var config Config
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseArgs(&config, []string{"--help"}, flagsfiller.WithOutput(&output))
+		require.ErrorIs(t, err, flag.ErrHelp)
+
+		assert.Equal(t, "Usage of "+os.Args[0]+`:
+  -help-all
+    	show the usage including advanced flags
+  -host string
+    	the host to access
+`, output.String())
+	})
+
+	t.Run("help-all", func(t *testing.T) {
+		var config Config
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseArgs(&config, []string{"--help-all"}, flagsfiller.WithOutput(&output))
+		require.ErrorIs(t, err, flag.ErrHelp)
+
+		assert.Equal(t, "Usage of "+os.Args[0]+`:
+  -bs int
+    	the size of buffers (default 4096)
+  -buffer-size int
+    	the size of buffers (default 4096)
+  -concurrency int
+    	the number of workers
+  -help-all
+    	show the usage including advanced flags
+  -host string
+    	the host to access
+`, output.String())
+	})
+
+	t.Run("help-all with required flags", func(t *testing.T) {
+		type Required struct {
+			Host  string `required:"true"`
+			Debug bool   `advanced:"true"`
+		}
+		var config Required
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseArgs(&config, []string{"--help-all"}, flagsfiller.WithOutput(&output))
+		require.ErrorIs(t, err, flag.ErrHelp)
+		assert.Contains(t, output.String(), "  -debug\n")
+	})
+
+	t.Run("help-all without ParseWithSources", func(t *testing.T) {
+		var config Config
+		var output bytes.Buffer
+		flagset := flag.NewFlagSet("test", flag.PanicOnError)
+		flagset.SetOutput(&output)
+		require.NoError(t, flagsfiller.New().Fill(flagset, &config))
+
+		assert.PanicsWithValue(t, flag.ErrHelp, func() {
+			_ = flagset.Parse([]string{"--help-all"})
+		})
+		assert.True(t, strings.HasPrefix(output.String(), "Usage of test:\n"), output.String())
+		assert.Contains(t, output.String(), "  -concurrency int\n")
+
+		// an explicit false value is accepted without showing the usage
+		output.Reset()
+		require.NoError(t, flagset.Parse([]string{"--help-all=false"}))
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("set advanced", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--bs", "10"})
+		require.NoError(t, err)
+		assert.Equal(t, 10, config.BufferSize)
+	})
+}
+
+func TestNoAdvancedFlags(t *testing.T) {
+	type Config struct {
+		Host string
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New().Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Nil(t, flagset.Lookup("help-all"))
+}
+
+func TestGroupedUsage(t *testing.T) {
+	type Config struct {
+		Verbose bool `usage:"enables verbose output"`
+		Remote  struct {
+			Host string `usage:"the remote host"`
+			Auth struct {
+				Username string `usage:"the user"`
+				Password string `usage:"the password"`
+			}
+			Timeout int `default:"5" usage:"the timeout"`
+		}
+		Debug bool `usage:"enables debugging" advanced:"true"`
+		Cache struct {
+			Size int `usage:"the cache size"`
+		}
+	}
+
+	var config Config
+	var output bytes.Buffer
+	_, err := flagsfiller.ParseArgs(&config, []string{"--help"},
+		flagsfiller.WithGroupedUsage(), flagsfiller.WithOutput(&output))
+	require.ErrorIs(t, err, flag.ErrHelp)
+
+	assert.Equal(t, "Usage of "+os.Args[0]+`:
+  -help-all
+    	show the usage including advanced flags
+  -verbose
+    	enables verbose output
+
+Remote:
+  -remote-host string
+    	the remote host
+  -remote-timeout int
+    	the timeout (default 5)
+
+Remote Auth:
+  -remote-auth-password string
+    	the password
+  -remote-auth-username string
+    	the user
+
+Cache:
+  -cache-size int
+    	the cache size
+`, output.String())
+}
@@ -0,0 +1,146 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// ValueResolver is called with a raw value, such as a reference to a secret, and returns the value
+// to convert, which is typically the raw value itself when it isn't a reference.
+type ValueResolver func(raw string) (string, error)
+
+// BeforeSetInterceptor is called with the path of a field, such as "Remote.Auth.Username", and
+// the string being set into its flag. It returns the string to set, which allows for normalizing
+// values, or an error to reject the value.
+type BeforeSetInterceptor func(path string, raw string) (string, error)
+
+// AfterSetInterceptor is called with the path of a field, the string that was set into its flag,
+// and the field's resulting typed value.
+type AfterSetInterceptor func(path string, raw string, value interface{})
+
+// fieldValue wraps the flag.Value declared for a field to apply the behavior that is common
+// across all field types
+type fieldValue struct {
+	flag.Value
+	path      string
+	fieldRef  interface{}
+	sensitive bool
+	filler    *FlagSetFiller
+}
+
+func (v *fieldValue) unwrap() flag.Value {
+	return v.Value
+}
+
+// wrapFieldValue replaces the value of the given flag with a fieldValue, when any of the options
+// require it
+func (f *FlagSetFiller) wrapFieldValue(declared *flag.Flag, path string, fieldRef interface{}, tag reflect.StructTag) {
+	if len(f.options.valueResolvers) == 0 && len(f.options.beforeSet) == 0 && len(f.options.afterSet) == 0 &&
+		f.options.auditor == nil {
+		return
+	}
+	if isZeroDefault(declared) {
+		declared.DefValue = ""
+	}
+	declared.Value = &fieldValue{
+		Value:     declared.Value,
+		path:      path,
+		fieldRef:  fieldRef,
+		sensitive: isSensitive(tag),
+		filler:    f,
+	}
+}
+
+// resolveValue passes the raw value through the resolvers declared by WithValueResolver
+func (f *FlagSetFiller) resolveValue(raw string) (string, error) {
+	for _, resolver := range f.options.valueResolvers {
+		resolved, err := resolver(raw)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve value: %w", err)
+		}
+		raw = resolved
+	}
+	return raw, nil
+}
+
+// isZeroDefault reports if the default of the flag is the String of a zero value of its type.
+// Since flag.PrintDefaults omits such defaults by comparing them to the String of a zero value,
+// which is an empty string for the wrapping values, a wrapper clears the default of the flag it
+// wraps when it is a zero default.
+func isZeroDefault(declared *flag.Flag) (zero bool) {
+	defer func() {
+		// some String methods do not handle zero values
+		if recover() != nil {
+			zero = false
+		}
+	}()
+	return declared.DefValue == zeroString(declared.Value)
+}
+
+// zeroString returns the String of a zero value of the given value's type, which
+// flag.PrintDefaults compares to the default of a flag to omit zero defaults. For that reason,
+// the String methods of the flag values in this package return an empty string when called on
+// a zero value, rather than dereferencing their nil field.
+func zeroString(value flag.Value) string {
+	t := reflect.TypeOf(value)
+	var z reflect.Value
+	if t.Kind() == reflect.Pointer {
+		z = reflect.New(t.Elem())
+	} else {
+		z = reflect.Zero(t)
+	}
+	return z.Interface().(flag.Value).String()
+}
+
+func (v *fieldValue) String() string {
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *fieldValue) Set(s string) error {
+	options := v.filler.options
+	s, err := v.filler.resolveValue(s)
+	if err != nil {
+		return err
+	}
+	for _, interceptor := range options.beforeSet {
+		s, err = interceptor(v.path, s)
+		if err != nil {
+			return err
+		}
+	}
+
+	previous := v.Value.String()
+	err = v.Value.Set(s)
+	if err != nil {
+		return err
+	}
+
+	if len(options.afterSet) > 0 {
+		typed := reflect.ValueOf(v.fieldRef).Elem().Interface()
+		for _, interceptor := range options.afterSet {
+			interceptor(v.path, s, typed)
+		}
+	}
+	if options.auditor != nil {
+		v.audit(previous, v.Value.String())
+	}
+	return nil
+}
+
+// IsBoolFlag retains the handling of boolean flags without a value, such as -verbose
+func (v *fieldValue) IsBoolFlag() bool {
+	boolFlag, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// Get implements flag.Getter when the wrapped value does
+func (v *fieldValue) Get() interface{} {
+	if getter, ok := v.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return reflect.ValueOf(v.fieldRef).Elem().Interface()
+}
@@ -0,0 +1,164 @@
+package flagsfiller_test
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetInterceptors(t *testing.T) {
+	type Config struct {
+		Mode    string `default:"fast"`
+		Verbose bool
+		Remote  struct {
+			Timeout time.Duration `aliases:"t"`
+		}
+	}
+
+	type setEvent struct {
+		path  string
+		raw   string
+		value interface{}
+	}
+	var events []setEvent
+
+	t.Setenv("INTERCEPT_MODE", " SLOW ")
+
+	var config Config
+	filler := flagsfiller.New(
+		flagsfiller.WithEnv("Intercept"),
+		flagsfiller.WithBeforeSet(func(path string, raw string) (string, error) {
+			return strings.TrimSpace(raw), nil
+		}),
+		flagsfiller.WithBeforeSet(func(path string, raw string) (string, error) {
+			if path == "Mode" {
+				return strings.ToLower(raw), nil
+			}
+			return raw, nil
+		}),
+		flagsfiller.WithAfterSet(func(path string, raw string, value interface{}) {
+			events = append(events, setEvent{path: path, raw: raw, value: value})
+		}),
+	)
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "slow", config.Mode)
+
+	err = flagset.Parse([]string{"--verbose", "-t", " 5s"})
+	require.NoError(t, err)
+
+	assert.True(t, config.Verbose)
+	assert.Equal(t, 5*time.Second, config.Remote.Timeout)
+	assert.Equal(t, []setEvent{
+		{path: "Mode", raw: "slow", value: "slow"},
+		{path: "Verbose", raw: "true", value: true},
+		{path: "Remote.Timeout", raw: "5s", value: 5 * time.Second},
+	}, events)
+}
+
+func TestBeforeSetRejects(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	var config Config
+	filler := flagsfiller.New(
+		flagsfiller.WithBeforeSet(func(path string, raw string) (string, error) {
+			if raw == "0" {
+				return "", errors.New("port must not be zero")
+			}
+			return raw, nil
+		}),
+	)
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	buf := grabUsage(*flagset)
+	assert.Contains(t, buf.String(), "(default 8080)")
+
+	var zeroConfig struct {
+		Retries int
+		Verbose bool
+	}
+	zeroFlags := flag.NewFlagSet("test", flag.ContinueOnError)
+	err = filler.Fill(zeroFlags, &zeroConfig)
+	require.NoError(t, err)
+	assert.Equal(t, `
+  -retries value
+    	
+  -verbose
+    	
+`, grabUsage(*zeroFlags).String())
+
+	err = flagset.Parse([]string{"--port", "0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port must not be zero")
+	assert.Equal(t, 8080, config.Port)
+}
+
+func TestValueResolver(t *testing.T) {
+	type Config struct {
+		Password string        `default:"vault:db#password" sensitive:"true"`
+		Token    string        `default:"plain"`
+		Timeout  time.Duration `default:"vault:app#timeout"`
+		Host     string
+	}
+
+	secrets := map[string]string{
+		"db#password": "s3cr3t",
+		"app#timeout": "5s",
+		"app#host":    "db.internal",
+		"app#token":   "t0ken",
+	}
+	resolver := func(raw string) (string, error) {
+		ref, isRef := strings.CutPrefix(raw, "vault:")
+		if !isRef {
+			return raw, nil
+		}
+		secret, exists := secrets[ref]
+		if !exists {
+			return "", errors.New("secret " + ref + " not found")
+		}
+		return secret, nil
+	}
+
+	t.Setenv("RESOLVE_HOST", "vault:app#host")
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New(flagsfiller.WithEnv("Resolve"), flagsfiller.WithValueResolver(resolver)).
+		Fill(flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", config.Password)
+	assert.Equal(t, "plain", config.Token)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.Equal(t, "db.internal", config.Host)
+	assert.Equal(t, "*****", flagset.Lookup("password").DefValue)
+
+	err = flagset.Parse([]string{"--token", "vault:app#token"})
+	require.NoError(t, err)
+	assert.Equal(t, "t0ken", config.Token)
+
+	flagset.SetOutput(io.Discard)
+	err = flagset.Parse([]string{"--token", "vault:app#missing"})
+	assert.ErrorContains(t, err, "failed to resolve value: secret app#missing not found")
+
+	type Missing struct {
+		Password string `default:"vault:db#missing"`
+	}
+	var missing Missing
+	err = flagsfiller.New(flagsfiller.WithValueResolver(resolver)).
+		Fill(flag.NewFlagSet("test", flag.ContinueOnError), &missing)
+	assert.ErrorContains(t, err, "failed to resolve default of field Password")
+}
@@ -0,0 +1,108 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// interfaceFactories maps interface types to the named factories registered by
+// RegisterInterfaceFactories
+var interfaceFactories = make(map[reflect.Type]map[string]func() interface{})
+
+// interfaceFactoriesMu guards interfaceFactories, since factories can be registered while filling
+var interfaceFactoriesMu sync.RWMutex
+
+// RegisterInterfaceFactories registers named factories that create implementations of the
+// interface type T. A field of type T is then mapped to a flag that selects the implementation
+// by name, such as --storage cloud for a field named Storage.
+//
+// If an implementation created by a factory is a pointer to a struct, its fields are also
+// mapped to flags prefixed by the field and implementation names, such as --storage-cloud-bucket.
+// Those flags only take effect when that implementation is selected.
+// Like RegisterSimpleType, this should be called in init().
+func RegisterInterfaceFactories[T any](factories map[string]func() T) {
+	registered := make(map[string]func() interface{}, len(factories))
+	for name, factory := range factories {
+		factory := factory
+		registered[name] = func() interface{} {
+			return factory()
+		}
+	}
+	interfaceFactoriesMu.Lock()
+	defer interfaceFactoriesMu.Unlock()
+	interfaceFactories[reflect.TypeOf((*T)(nil)).Elem()] = registered
+}
+
+// factoriesFor returns the factories registered for the interface type t, if any
+func factoriesFor(t reflect.Type) map[string]func() interface{} {
+	interfaceFactoriesMu.RLock()
+	defer interfaceFactoriesMu.RUnlock()
+	return interfaceFactories[t]
+}
+
+type interfaceVar struct {
+	ref       reflect.Value
+	instances map[string]reflect.Value
+	choices   []string
+	selected  string
+}
+
+func (v *interfaceVar) String() string {
+	return v.selected
+}
+
+func (v *interfaceVar) Set(s string) error {
+	instance, exists := v.instances[s]
+	if !exists {
+		return fmt.Errorf("must be one of %s", strings.Join(v.choices, ", "))
+	}
+	v.ref.Set(instance)
+	v.selected = s
+	return nil
+}
+
+func (f *FlagSetFiller) processInterface(fieldRef interface{}, hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string, usage string,
+	levels []string, envLevels []string, path string, t reflect.Type) error {
+
+	factories := factoriesFor(t)
+	val := &interfaceVar{
+		ref:       reflect.ValueOf(fieldRef).Elem(),
+		instances: make(map[string]reflect.Value, len(factories)),
+	}
+	for choice := range factories {
+		val.choices = append(val.choices, choice)
+	}
+	sort.Strings(val.choices)
+
+	for _, choice := range val.choices {
+		instance := reflect.ValueOf(factories[choice]())
+		if !instance.IsValid() || !instance.Type().Implements(t) {
+			return fmt.Errorf("factory %s did not create an implementation of %s", choice, t)
+		}
+		val.instances[choice] = instance
+
+		if instance.Kind() == reflect.Ptr && instance.Elem().Kind() == reflect.Struct {
+			err := f.walkFields(flagSet, appendLevel(levels, choice), appendLevel(envLevels, choice), path+"."+choice,
+				instance.Elem(), instance.Elem().Type())
+			if err != nil {
+				return fmt.Errorf("failed to process %s implementation: %w", choice, err)
+			}
+		}
+	}
+
+	if hasDefaultTag {
+		err := val.Set(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into %s: %w", t, err)
+		}
+	}
+
+	usage = fmt.Sprintf("%s (one of %s)", usage, strings.Join(val.choices, ", "))
+	flagSet.Var(val, renamed, usage)
+	return nil
+}
@@ -0,0 +1,144 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type StorageBackend interface {
+	Location() string
+}
+
+type cloudStorage struct {
+	Bucket string `usage:"the bucket to use"`
+	Region string `default:"us-east-1"`
+}
+
+func (s *cloudStorage) Location() string {
+	return "s3://" + s.Region + "/" + s.Bucket
+}
+
+type localStorage struct {
+	Path string `default:"/tmp"`
+}
+
+func (l *localStorage) Location() string {
+	return "file://" + l.Path
+}
+
+func init() {
+	flagsfiller.RegisterInterfaceFactories(map[string]func() StorageBackend{
+		"cloud": func() StorageBackend {
+			return &cloudStorage{}
+		},
+		"local": func() StorageBackend {
+			return &localStorage{}
+		},
+	})
+}
+
+func TestInterfaceFactories(t *testing.T) {
+	type Config struct {
+		Storage StorageBackend `default:"local" usage:"where to store"`
+	}
+
+	t.Run("default", func(t *testing.T) {
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+
+		err = flagset.Parse([]string{"--storage-local-path", "/data"})
+		require.NoError(t, err)
+
+		require.NotNil(t, config.Storage)
+		assert.Equal(t, "file:///data", config.Storage.Location())
+	})
+
+	t.Run("selected", func(t *testing.T) {
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+
+		err = flagset.Parse([]string{"--storage", "cloud", "--storage-cloud-bucket", "b1"})
+		require.NoError(t, err)
+
+		require.NotNil(t, config.Storage)
+		assert.Equal(t, "s3://us-east-1/b1", config.Storage.Location())
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		var config Config
+
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+
+		buf := grabUsage(flagset)
+
+		assert.Equal(t, `
+  -storage value
+    	where to store (one of cloud, local) (default local)
+  -storage-cloud-bucket string
+    	the bucket to use
+  -storage-cloud-region string
+    	 (default "us-east-1")
+  -storage-local-path string
+    	 (default "/tmp")
+`, buf.String())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		var config Config
+
+		var flagset flag.FlagSet
+		flagset.SetOutput(io.Discard)
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+
+		err = flagset.Parse([]string{"--storage", "gcs"})
+		assert.ErrorContains(t, err, "must be one of cloud, local")
+	})
+}
+
+type queueBackend interface {
+	Queue() string
+}
+
+type memoryQueue struct{}
+
+func (memoryQueue) Queue() string {
+	return "memory"
+}
+
+func TestInterfaceFactoriesConcurrentFill(t *testing.T) {
+	type Config struct {
+		Storage StorageBackend `default:"cloud"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			flagsfiller.RegisterInterfaceFactories(map[string]func() queueBackend{
+				"memory": func() queueBackend {
+					return memoryQueue{}
+				},
+			})
+			var config Config
+			assert.NoError(t, flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config))
+			assert.Equal(t, "s3://us-east-1/", config.Storage.Location())
+		}()
+	}
+	wg.Wait()
+}
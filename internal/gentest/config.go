@@ -0,0 +1,37 @@
+// Package gentest declares a config struct used to verify the code generated by flagsfiller-gen
+// behaves the same as flagsfiller.
+package gentest
+
+import "time"
+
+//go:generate go run ../../cmd/flagsfiller-gen -type Config -env App
+
+type Config struct {
+	Logging
+	Host    string        `default:"localhost" usage:"the [host] to use" aliases:"H"`
+	Port    int           `default:"8080" min:"1" max:"65535"`
+	Debug   bool          `usage:"enable debug logging"`
+	Timeout time.Duration `default:"5s"`
+	Ratio   float64       `default:"0.5"`
+	Tags    []string      `default:"one,two"`
+	Remote  Remote        `envPrefix:"Upstream"`
+	Auth    struct {
+		Username string `env:"APP_USER,USER_NAME"`
+		Password string `env:""`
+	}
+	Ignored string `flag:""`
+	Name    string `flag:"display_name"`
+	Backup  *Remote
+	Region  string `required:"true"`
+}
+
+type Logging struct {
+	Level string        `default:"info" choices:"debug,info,warn"`
+	Flush time.Duration `max:"1m"`
+}
+
+type Remote struct {
+	Address string
+	Retries uint     `default:"3"`
+	Scopes  []string `override-value:"true"`
+}
@@ -0,0 +1,280 @@
+// Code generated by flagsfiller-gen. DO NOT EDIT.
+
+package gentest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// FillConfig declares a flag in flagSet for each field of c
+func FillConfig(flagSet *flag.FlagSet, c *Config) error {
+	// Logging.Level
+	flagSet.StringVar(&c.Logging.Level, "logging-level", "info", " (env APP_LOGGING_LEVEL) (one of debug, info, warn)")
+	for _, name := range []string{"APP_LOGGING_LEVEL"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("logging-level", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Logging.Flush
+	flagSet.DurationVar(&c.Logging.Flush, "logging-flush", c.Logging.Flush, " (env APP_LOGGING_FLUSH)")
+	for _, name := range []string{"APP_LOGGING_FLUSH"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("logging-flush", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Host
+	{
+		defaultValue := "localhost"
+		flagSet.StringVar(&c.Host, "host", defaultValue, "the `host` to use (env APP_HOST)")
+		flagSet.StringVar(&c.Host, "H", defaultValue, "the `host` to use (env APP_HOST)")
+	}
+	for _, name := range []string{"APP_HOST"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("host", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Port
+	flagSet.IntVar(&c.Port, "port", 8080, " (env APP_PORT)")
+	for _, name := range []string{"APP_PORT"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("port", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Debug
+	flagSet.BoolVar(&c.Debug, "debug", c.Debug, "enable debug logging (env APP_DEBUG)")
+	for _, name := range []string{"APP_DEBUG"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("debug", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Timeout
+	flagSet.DurationVar(&c.Timeout, "timeout", time.Duration(5000000000) /* 5s */, " (env APP_TIMEOUT)")
+	for _, name := range []string{"APP_TIMEOUT"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("timeout", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Ratio
+	flagSet.Float64Var(&c.Ratio, "ratio", 0.5, " (env APP_RATIO)")
+	for _, name := range []string{"APP_RATIO"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("ratio", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Tags
+	c.Tags = []string{"one", "two"}
+	flagSet.Func("tags", " (env APP_TAGS)", func(s string) error {
+		parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' })
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		c.Tags = append(c.Tags, values...)
+		return nil
+	})
+	for _, name := range []string{"APP_TAGS"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("tags", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Remote.Address
+	flagSet.StringVar(&c.Remote.Address, "remote-address", c.Remote.Address, " (env APP_UPSTREAM_ADDRESS)")
+	for _, name := range []string{"APP_UPSTREAM_ADDRESS"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("remote-address", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Remote.Retries
+	flagSet.UintVar(&c.Remote.Retries, "remote-retries", 3, " (env APP_UPSTREAM_RETRIES)")
+	for _, name := range []string{"APP_UPSTREAM_RETRIES"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("remote-retries", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Remote.Scopes
+	flagSet.Func("remote-scopes", " (env APP_UPSTREAM_SCOPES)", func(s string) error {
+		parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' })
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		c.Remote.Scopes = values
+		return nil
+	})
+	for _, name := range []string{"APP_UPSTREAM_SCOPES"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("remote-scopes", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Auth.Username
+	flagSet.StringVar(&c.Auth.Username, "auth-username", c.Auth.Username, " (env APP_USER, USER_NAME)")
+	for _, name := range []string{"APP_USER", "USER_NAME"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("auth-username", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Auth.Password
+	flagSet.StringVar(&c.Auth.Password, "auth-password", c.Auth.Password, "")
+
+	// Name
+	flagSet.StringVar(&c.Name, "display_name", c.Name, " (env APP_NAME)")
+	for _, name := range []string{"APP_NAME"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("display_name", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Backup
+	if c.Backup == nil {
+		c.Backup = new(Remote)
+	}
+
+	// Backup.Address
+	flagSet.StringVar(&c.Backup.Address, "backup-address", c.Backup.Address, " (env APP_BACKUP_ADDRESS)")
+	for _, name := range []string{"APP_BACKUP_ADDRESS"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("backup-address", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Backup.Retries
+	flagSet.UintVar(&c.Backup.Retries, "backup-retries", 3, " (env APP_BACKUP_RETRIES)")
+	for _, name := range []string{"APP_BACKUP_RETRIES"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("backup-retries", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Backup.Scopes
+	flagSet.Func("backup-scopes", " (env APP_BACKUP_SCOPES)", func(s string) error {
+		parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' })
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		c.Backup.Scopes = values
+		return nil
+	})
+	for _, name := range []string{"APP_BACKUP_SCOPES"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("backup-scopes", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+
+	// Region
+	flagSet.StringVar(&c.Region, "region", c.Region, " (env APP_REGION) (required)")
+	for _, name := range []string{"APP_REGION"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := flagSet.Set("region", value); err != nil {
+				return fmt.Errorf("failed to set from environment variable %s: %w", name, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// ValidateConfig checks the constraints declared by the tags of the fields of c after parsing,
+// like flagsfiller.FlagSetFiller.Validate and the min, max, and choices tags do
+func ValidateConfig(flagSet *flag.FlagSet, c *Config) error {
+	set := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	var errs []error
+	var missing []string
+	if !set["region"] {
+		missing = append(missing, "-region")
+	}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("required flags are not set: %s", strings.Join(missing, ", ")))
+	}
+	if set["logging-level"] || c.Logging.Level != "" {
+		switch c.Logging.Level {
+		case "debug", "info", "warn":
+		default:
+			errs = append(errs, fmt.Errorf("invalid value \"%v\" for flag -logging-level: must be one of debug, info, warn", c.Logging.Level))
+		}
+	}
+	if (set["logging-flush"] || c.Logging.Flush != 0) && c.Logging.Flush > time.Duration(60000000000) {
+		errs = append(errs, fmt.Errorf("invalid value \"%v\" for flag -logging-flush: must be at most 1m", c.Logging.Flush))
+	}
+	if (set["port"] || c.Port != 0) && c.Port < 1 {
+		errs = append(errs, fmt.Errorf("invalid value \"%v\" for flag -port: must be at least 1", c.Port))
+	}
+	if (set["port"] || c.Port != 0) && c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid value \"%v\" for flag -port: must be at most 65535", c.Port))
+	}
+	return errors.Join(errs...)
+}
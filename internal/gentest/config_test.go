@@ -0,0 +1,116 @@
+package gentest
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedMatchesFiller(t *testing.T) {
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("APP_UPSTREAM_ADDRESS", "upstream:443")
+	t.Setenv("USER_NAME", "admin")
+	t.Setenv("APP_AUTH_PASSWORD", "ignored")
+
+	args := []string{
+		"-H", "example.com",
+		"--debug",
+		"--tags", "three",
+		"--remote-scopes", "read,write",
+		"--remote-scopes", "admin",
+		"--display_name", "Example",
+		"--logging-level", "warn",
+		"--backup-retries", "5",
+		"--region", "eu",
+	}
+
+	var generated Config
+	generatedFlags := flag.NewFlagSet("generated", flag.ContinueOnError)
+	generatedFlags.SetOutput(io.Discard)
+	err := FillConfig(generatedFlags, &generated)
+	require.NoError(t, err)
+	require.NoError(t, generatedFlags.Parse(args))
+
+	var filled Config
+	filledFlags := flag.NewFlagSet("filled", flag.ContinueOnError)
+	filledFlags.SetOutput(io.Discard)
+	err = flagsfiller.New(flagsfiller.WithEnv("App")).Fill(filledFlags, &filled)
+	require.NoError(t, err)
+	require.NoError(t, filledFlags.Parse(args))
+
+	assert.Equal(t, filled, generated)
+	assert.Equal(t, Config{
+		Logging: Logging{Level: "warn"},
+		Host:    "example.com",
+		Port:    9090,
+		Debug:   true,
+		Timeout: 5 * time.Second,
+		Ratio:   0.5,
+		Tags:    []string{"one", "two", "three"},
+		Remote: Remote{
+			Address: "upstream:443",
+			Retries: 3,
+			Scopes:  []string{"admin"},
+		},
+		Name:   "Example",
+		Backup: &Remote{Retries: 5},
+		Region: "eu",
+		Auth: struct {
+			Username string `env:"APP_USER,USER_NAME"`
+			Password string `env:""`
+		}{Username: "admin"},
+	}, generated)
+
+	var generatedNames, filledNames []string
+	generatedFlags.VisitAll(func(f *flag.Flag) {
+		generatedNames = append(generatedNames, f.Name)
+	})
+	filledFlags.VisitAll(func(f *flag.Flag) {
+		filledNames = append(filledNames, f.Name)
+	})
+	assert.Equal(t, filledNames, generatedNames)
+	assert.NoError(t, ValidateConfig(generatedFlags, &generated))
+}
+
+func TestGeneratedValidateMatchesFiller(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		error string
+	}{
+		{name: "missing required", args: nil, error: "required flags are not set: -region"},
+		{name: "below min", args: []string{"--region", "eu", "--port", "0"},
+			error: `invalid value "0" for flag -port: must be at least 1`},
+		{name: "above max", args: []string{"--region", "eu", "--logging-flush", "2m"},
+			error: `for flag -logging-flush: must be at most 1m`},
+		{name: "not a choice", args: []string{"--region", "eu", "--logging-level", "trace"},
+			error: `invalid value "trace" for flag -logging-level: must be one of debug, info, warn`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var generated Config
+			generatedFlags := flag.NewFlagSet("generated", flag.ContinueOnError)
+			generatedFlags.SetOutput(io.Discard)
+			require.NoError(t, FillConfig(generatedFlags, &generated))
+			require.NoError(t, generatedFlags.Parse(tt.args))
+			assert.ErrorContains(t, ValidateConfig(generatedFlags, &generated), tt.error)
+
+			// flagsfiller rejects the values of constraints when parsing
+			var filled Config
+			filledFlags := flag.NewFlagSet("filled", flag.ContinueOnError)
+			filledFlags.SetOutput(io.Discard)
+			filler := flagsfiller.New()
+			require.NoError(t, filler.Fill(filledFlags, &filled))
+			err := filledFlags.Parse(tt.args)
+			if err == nil {
+				err = filler.Validate(filledFlags)
+			}
+			assert.ErrorContains(t, err, tt.error)
+		})
+	}
+}
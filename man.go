@@ -0,0 +1,74 @@
+package flagsfiller
+
+import (
+	"flag"
+	"io"
+	"strings"
+)
+
+// manEscaper escapes the characters that roff would otherwise interpret
+var manEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+// WriteManOptions writes the OPTIONS section of a man page in roff to w for the fields that
+// declared a flag in flagSet, which needs to have been filled by this FlagSetFiller. Each flag is
+// listed with its aliases and described by its field's usage, default, and environment
+// variables, which allows packaged CLIs to ship man pages derived from the config struct. The
+// defaults of sensitive fields are omitted, as are deprecated aliases.
+func (f *FlagSetFiller) WriteManOptions(w io.Writer, flagSet *flag.FlagSet) error {
+	var sb strings.Builder
+	sb.WriteString(".SH OPTIONS\n")
+	aliases := f.flagAliases(flagSet)
+	for _, field := range f.declaredFields(flagSet) {
+		placeholder, _ := flag.UnquoteUsage(field.flag)
+		var names []string
+		for _, name := range flagNames(field, aliases) {
+			name = `\fB` + manEscaper.Replace(completionFlagName(name)) + `\fR`
+			if placeholder != "" {
+				name += ` \fI` + manEscaper.Replace(placeholder) + `\fR`
+			}
+			names = append(names, name)
+		}
+
+		sb.WriteString(".TP\n")
+		sb.WriteString(strings.Join(names, ", "))
+		sb.WriteString("\n")
+		if field.usage != "" {
+			for _, line := range strings.Split(field.usage, "\n") {
+				if line == "" {
+					// roff outputs a blank line as is, so space the lines with a request instead
+					line = ".sp"
+				} else {
+					line = manLine(line)
+				}
+				sb.WriteString(line)
+				sb.WriteString("\n")
+			}
+		}
+		if field.flag.DefValue != "" && !isZeroDefault(field.flag) && !field.sensitive {
+			sb.WriteString(".br\n")
+			sb.WriteString(`Default: \fI` + manEscaper.Replace(field.flag.DefValue) + `\fR`)
+			sb.WriteString("\n")
+		}
+		if len(field.envNames) > 0 {
+			var envNames []string
+			for _, name := range field.envNames {
+				envNames = append(envNames, `\fB`+manEscaper.Replace(name)+`\fR`)
+			}
+			sb.WriteString(".br\n")
+			sb.WriteString("Environment: " + strings.Join(envNames, ", "))
+			sb.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// manLine escapes a line of text for roff, where a leading period or apostrophe would otherwise
+// start a request
+func manLine(line string) string {
+	line = manEscaper.Replace(line)
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+		line = `\&` + line
+	}
+	return line
+}
@@ -0,0 +1,68 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManOptions(t *testing.T) {
+	type Config struct {
+		Verbose bool          `aliases:"v" usage:"enables verbose output"`
+		Host    string        `default:"localhost" usage:"the [address] to access"`
+		Port    int           `usage:"the port\n.or not"`
+		Timeout time.Duration `default:"5s" deprecated-aliases:"wait"`
+		Token   string        `default:"secret" sensitive:"true" env:""`
+		Path    string        `default:"C:\\data"`
+	}
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("App"))
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = filler.WriteManOptions(&buf, &flagset)
+	require.NoError(t, err)
+
+	assert.Equal(t, `.SH OPTIONS
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+enables verbose output
+.br
+Environment: \fBAPP_VERBOSE\fR
+.TP
+\fB\-\-host\fR \fIaddress\fR
+the address to access
+.br
+Default: \fIlocalhost\fR
+.br
+Environment: \fBAPP_HOST\fR
+.TP
+\fB\-\-port\fR \fIint\fR
+the port
+\&.or not
+.br
+Environment: \fBAPP_PORT\fR
+.TP
+\fB\-\-timeout\fR \fIduration\fR
+.br
+Default: \fI5s\fR
+.br
+Environment: \fBAPP_TIMEOUT\fR
+.TP
+\fB\-\-token\fR \fIstring\fR
+.TP
+\fB\-\-path\fR \fIstring\fR
+.br
+Default: \fIC:\edata\fR
+.br
+Environment: \fBAPP_PATH\fR
+`, buf.String())
+}
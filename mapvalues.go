@@ -0,0 +1,133 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mapVar is the flag.Value of maps with string keys and parsed values, such as map[string]int,
+// which follows the same semantics as strToStrMapVar, where each value is parsed
+type mapVar struct {
+	val     reflect.Value
+	parse   func(s string) (reflect.Value, error)
+	parsing valueParsing
+}
+
+func (m *mapVar) String() string {
+	if !m.val.IsValid() || m.val.IsNil() {
+		return ""
+	}
+	entries := make([]string, 0, m.val.Len())
+	iter := m.val.MapRange()
+	for iter.Next() {
+		entries = append(entries, iter.Key().String()+"="+formatValue(iter.Value()))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func (m *mapVar) Set(val string) error {
+	entries, err := parseMap(val, m.parsing, m.parse)
+	if err != nil {
+		return err
+	}
+	for k, v := range entries {
+		m.val.SetMapIndex(reflect.ValueOf(k).Convert(m.val.Type().Key()), v)
+	}
+	return nil
+}
+
+// parseMap splits val into entries like parseStringToStringMap and parses each of the values
+func parseMap(val string, parsing valueParsing, parse func(s string) (reflect.Value, error)) (map[string]reflect.Value, error) {
+	entries := parseStringToStringMap(val, parsing)
+	result := make(map[string]reflect.Value, len(entries))
+	for k, v := range entries {
+		parsed, err := parse(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of key %s: %w", k, err)
+		}
+		result[k] = parsed
+	}
+	return result, nil
+}
+
+// isParsedMapType reports if t is a map with string keys whose values can be parsed by
+// elementParser, other than map[string]string
+func (f *FlagSetFiller) isParsedMapType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t != stringToStringMapType && t.Key().Kind() == reflect.String &&
+		f.elementParser(t.Elem(), "") != nil
+}
+
+// elementParser returns a function that parses the values of the given type, which are the types
+// of the extended types, such as registered enums and simple types, along with the basic types
+// and time.Duration. Returns nil if the type is not supported.
+func (f *FlagSetFiller) elementParser(t reflect.Type, tag reflect.StructTag) func(s string) (reflect.Value, error) {
+	if handler := f.handlerFor(reflect.PointerTo(t)); handler != nil {
+		return func(s string) (reflect.Value, error) {
+			// parse by declaring a flag of the element type
+			element := reflect.New(t)
+			flagSet := flag.NewFlagSet("", flag.ContinueOnError)
+			err := handler(tag, element.Interface(), false, "", flagSet, "element", "")
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			declared := flagSet.Lookup("element")
+			if declared == nil {
+				return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
+			}
+			err = declared.Value.Set(s)
+			return element.Elem(), err
+		}
+	}
+
+	var parse func(s string) (interface{}, error)
+	switch {
+	case t == durationType:
+		parse = func(s string) (interface{}, error) { return time.ParseDuration(s) }
+	case t.Kind() == reflect.String:
+		parse = func(s string) (interface{}, error) { return s, nil }
+	case t.Kind() == reflect.Bool:
+		parse = func(s string) (interface{}, error) { return strconv.ParseBool(s) }
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		parse = func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, t.Bits()) }
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		parse = func(s string) (interface{}, error) { return strconv.ParseUint(s, 10, t.Bits()) }
+	case t.Kind() == reflect.Float32, t.Kind() == reflect.Float64:
+		parse = func(s string) (interface{}, error) { return strconv.ParseFloat(s, t.Bits()) }
+	default:
+		return nil
+	}
+	return func(s string) (reflect.Value, error) {
+		value, err := parse(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(value).Convert(t), nil
+	}
+}
+
+// processParsedMap declares the flag of a map field whose values are parsed by elementParser
+func (f *FlagSetFiller) processParsedMap(fieldRef interface{}, tag reflect.StructTag, hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string, usage string, parsing valueParsing) error {
+	field := reflect.ValueOf(fieldRef).Elem()
+	parse := f.elementParser(field.Type().Elem(), tag)
+	if hasDefaultTag {
+		entries, err := parseMap(tagDefault, parsing, parse)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into %s: %w", field.Type(), err)
+		}
+		field.Set(reflect.MakeMapWithSize(field.Type(), len(entries)))
+		for k, v := range entries {
+			field.SetMapIndex(reflect.ValueOf(k).Convert(field.Type().Key()), v)
+		}
+	} else if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+	flagSet.Var(&mapVar{val: field, parse: parse, parsing: parsing}, renamed, usage)
+	return nil
+}
@@ -0,0 +1,64 @@
+package flagsfiller
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Merge overlays the fields of src onto dst, which enables layering configs such as a base,
+// per-environment, and per-tenant config. Only the fields of src for which wasSet reports true,
+// given the field's dot-separated path, override the corresponding fields of dst. When wasSet
+// is nil, fields of src that are not the zero value override.
+// Overlaid values are deep copied, as with Clone.
+func Merge[T any](dst, src *T, wasSet func(fieldPath string) bool) {
+	if dst == nil || src == nil {
+		return
+	}
+	c := cloner{visited: make(map[uintptr]reflect.Value)}
+	dstVal := reflect.ValueOf(dst).Elem()
+	visitLeaves(reflect.ValueOf(src).Elem(), "", false, func(leaf leafValue) {
+		if wasSet != nil {
+			if !wasSet(leaf.path) {
+				return
+			}
+		} else if leaf.value.IsZero() {
+			return
+		}
+		target := locateField(dstVal, leaf.path)
+		if target.IsValid() && target.CanSet() {
+			c.copyValue(target, leaf.value)
+		}
+	})
+}
+
+// locateField resolves the field at the given dot-separated path, allocating nil struct pointers
+// along the way. An invalid value is returned if the path cannot be resolved.
+func locateField(structVal reflect.Value, path string) reflect.Value {
+	current := structVal
+	for _, name := range strings.Split(path, ".") {
+		switch current.Kind() {
+		case reflect.Interface:
+			if current.IsNil() {
+				return reflect.Value{}
+			}
+			current = current.Elem()
+		}
+		if current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				if !current.CanSet() {
+					return reflect.Value{}
+				}
+				current.Set(reflect.New(current.Type().Elem()))
+			}
+			current = current.Elem()
+		}
+		if current.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		current = current.FieldByName(name)
+		if !current.IsValid() {
+			return reflect.Value{}
+		}
+	}
+	return current
+}
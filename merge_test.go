@@ -0,0 +1,72 @@
+package flagsfiller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+)
+
+type mergeConfig struct {
+	Host    string
+	Port    int
+	Debug   bool
+	Timeout time.Duration
+	Tags    []string
+	Remote  *struct {
+		Host string
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := mergeConfig{
+		Host:    "base-host",
+		Port:    8080,
+		Debug:   true,
+		Timeout: 5 * time.Second,
+		Tags:    []string{"base"},
+	}
+
+	environment := mergeConfig{
+		Host: "env-host",
+		Tags: []string{"env"},
+	}
+	environment.Remote = &struct{ Host string }{Host: "remote-host"}
+
+	flagsfiller.Merge(&base, &environment, nil)
+
+	assert.Equal(t, "env-host", base.Host)
+	assert.Equal(t, 8080, base.Port)
+	assert.True(t, base.Debug)
+	assert.Equal(t, 5*time.Second, base.Timeout)
+	assert.Equal(t, []string{"env"}, base.Tags)
+	assert.Equal(t, "remote-host", base.Remote.Host)
+
+	// deep copied
+	environment.Tags[0] = "changed"
+	assert.Equal(t, []string{"env"}, base.Tags)
+}
+
+func TestMergeWasSet(t *testing.T) {
+	base := mergeConfig{
+		Host:  "base-host",
+		Port:  8080,
+		Debug: true,
+	}
+
+	// tenant explicitly disables debug and leaves host as zero value
+	tenant := mergeConfig{
+		Host: "ignored",
+		Port: 9090,
+	}
+	explicitlySet := map[string]bool{"Port": true, "Debug": true}
+
+	flagsfiller.Merge(&base, &tenant, func(fieldPath string) bool {
+		return explicitlySet[fieldPath]
+	})
+
+	assert.Equal(t, "base-host", base.Host)
+	assert.Equal(t, 9090, base.Port)
+	assert.False(t, base.Debug)
+}
@@ -0,0 +1,40 @@
+package flagsfiller
+
+import (
+	"reflect"
+	"sync"
+)
+
+// namedConverters are the converters registered by RegisterNamedConverter and selected by
+// fields with the converter tag
+var namedConverters = make(map[string]func(s string) (interface{}, error))
+
+// namedConvertersMu guards namedConverters, since converters can be registered while filling
+var namedConvertersMu sync.RWMutex
+
+// RegisterNamedConverter registers a converter that fields can select by name with the
+// `converter:"name"` tag, or its shorter form `convert:"name"`, which allows fields of the same
+// Go type to opt into different parsing without defining a new type for each one.
+// The converter's result must be convertible to the field's type.
+// Like RegisterSimpleType, this should be called in init().
+func RegisterNamedConverter(name string, converter func(s string) (interface{}, error)) {
+	namedConvertersMu.Lock()
+	defer namedConvertersMu.Unlock()
+	namedConverters[name] = converter
+}
+
+// namedConverter returns the converter registered with the given name, if any
+func namedConverter(name string) (func(s string) (interface{}, error), bool) {
+	namedConvertersMu.RLock()
+	defer namedConvertersMu.RUnlock()
+	converter, registered := namedConverters[name]
+	return converter, registered
+}
+
+// converterName returns the name given by the converter tag, or else by the convert tag
+func converterName(tag reflect.StructTag) (string, bool) {
+	if name, exists := tag.Lookup("converter"); exists {
+		return name, true
+	}
+	return tag.Lookup("convert")
+}
@@ -0,0 +1,85 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	flagsfiller.RegisterNamedConverter("kilobytes", func(s string) (interface{}, error) {
+		value, err := strconv.ParseInt(strings.TrimSuffix(s, "k"), 10, 64)
+		return value * 1024, err
+	})
+	flagsfiller.RegisterNamedConverter("csvset", func(s string) (interface{}, error) {
+		set := make(map[string]bool)
+		for _, item := range strings.Split(s, ",") {
+			set[strings.TrimSpace(item)] = true
+		}
+		return set, nil
+	})
+}
+
+func TestNamedConverter(t *testing.T) {
+	type Config struct {
+		BufferSize int64 `converter:"kilobytes" default:"4k"`
+		Count      int64 `default:"4"`
+	}
+
+	var config Config
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4096), config.BufferSize)
+	assert.Equal(t, int64(4), config.Count)
+
+	err = flagset.Parse([]string{"--buffer-size", "2k", "--count", "2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2048), config.BufferSize)
+	assert.Equal(t, int64(2), config.Count)
+}
+
+func TestNamedConverterNotRegistered(t *testing.T) {
+	type Config struct {
+		BufferSize int64 `converter:"unknown"`
+	}
+
+	var config Config
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	assert.ErrorContains(t, err, "converter unknown is not registered")
+}
+
+func TestNamedConverterConvertTag(t *testing.T) {
+	type Config struct {
+		Features map[string]bool `convert:"csvset" default:"metrics, tracing"`
+		Regions  map[string]bool `convert:"csvset"`
+	}
+
+	var config Config
+
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"metrics": true, "tracing": true}, config.Features)
+
+	err = flagset.Parse([]string{"--regions", "us,eu"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"us": true, "eu": true}, config.Regions)
+
+	type Unknown struct {
+		Features map[string]bool `convert:"unknown"`
+	}
+	var unknown Unknown
+	err = flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &unknown)
+	assert.ErrorContains(t, err, "converter unknown is not registered")
+}
@@ -0,0 +1,113 @@
+package flagsfiller
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// isNarrowNumber reports if the kind is one of the numeric kinds narrower than the ones that
+// flag.FlagSet supports, which are handled by narrowNumberVar
+func isNarrowNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Float32:
+		return true
+	}
+	return false
+}
+
+// narrowNumberVar is the flag.Value of fields of the narrow numeric kinds, such as int8 or
+// float32, which rejects the values that are out of the range of the field's type
+type narrowNumberVar struct {
+	field reflect.Value
+}
+
+func (v *narrowNumberVar) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	switch v.field.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return strconv.FormatUint(v.field.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.field.Float(), 'g', -1, 32)
+	default:
+		return strconv.FormatInt(v.field.Int(), 10)
+	}
+}
+
+func (v *narrowNumberVar) Set(s string) error {
+	return setNarrowNumber(v.field, s)
+}
+
+// Get implements flag.Getter
+func (v *narrowNumberVar) Get() interface{} {
+	return v.field.Interface()
+}
+
+// setNarrowNumber parses s like flag.FlagSet parses the wider kinds, where integers can be given
+// with a base prefix, such as 0x, and sets the field when s is in the range of its type
+func setNarrowNumber(field reflect.Value, s string) error {
+	t := field.Type()
+	var err error
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		var n uint64
+		n, err = strconv.ParseUint(s, 0, t.Bits())
+		if err == nil {
+			field.SetUint(n)
+			return nil
+		}
+	case reflect.Float32:
+		var n float64
+		n, err = strconv.ParseFloat(s, t.Bits())
+		if err == nil {
+			field.SetFloat(n)
+			return nil
+		}
+	default:
+		var n int64
+		n, err = strconv.ParseInt(s, 0, t.Bits())
+		if err == nil {
+			field.SetInt(n)
+			return nil
+		}
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%s is out of range of %s, %s", s, t.Kind(), narrowRange(t))
+	}
+	return fmt.Errorf("%s is not a valid %s", s, t.Kind())
+}
+
+// narrowRange describes the range of values of the given type, such as "-128 to 127"
+func narrowRange(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return fmt.Sprintf("0 to %d", uint64(1)<<t.Bits()-1)
+	case reflect.Float32:
+		return fmt.Sprintf("-%g to %g", math.MaxFloat32, math.MaxFloat32)
+	default:
+		return fmt.Sprintf("%d to %d", -int64(1)<<(t.Bits()-1), int64(1)<<(t.Bits()-1)-1)
+	}
+}
+
+func (f *FlagSetFiller) processNarrowNumber(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string) error {
+	field := reflect.ValueOf(fieldRef).Elem()
+	if hasDefaultTag {
+		err := setNarrowNumber(field, tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into %s: %w", field.Type(), err)
+		}
+	}
+	flagSet.Var(&narrowNumberVar{field: field}, renamed, usage)
+	if field.IsZero() {
+		// like the wider kinds, zero defaults are omitted by flag.PrintDefaults
+		flagSet.Lookup(renamed).DefValue = ""
+	}
+	return nil
+}
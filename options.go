@@ -1,6 +1,21 @@
 package flagsfiller
 
-import "github.com/iancoleman/strcase"
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+)
 
 // Renamer takes a field's name and returns the flag name to be used
 type Renamer func(name string) string
@@ -15,8 +30,41 @@ type FillerOption func(opt *fillerOptions)
 type fillerOptions struct {
 	fieldRenamer      []Renamer
 	envRenamer        []Renamer
+	envLevelRenamer   []Renamer
 	noSetFromEnv      bool
-	valueSplitPattern string
+	valueSplitter     *regexp.Regexp
+	dashUnderscore    bool
+	nestedSeparator   string
+	envNestedSep      string
+	emptySlices       bool
+	preserveSpace     bool
+	keepEmpty         bool
+	envPrefix         string
+	strictEnv         bool
+	envFiles          bool
+	deferSources      bool
+	argFiles          bool
+	envErrorHandler   func(err error)
+	envNameMapper     EnvNameMapper
+	dotEnv            map[string]string
+	fieldConverters   map[string]func(s string) (interface{}, error)
+	defaultsProviders []DefaultsProvider
+	configFiles       []configFile
+	remoteSources     []RemoteSource
+	errorHandling     *flag.ErrorHandling
+	output            io.Writer
+	strictTags        bool
+	allowedTags       map[string]bool
+	requireUsage      bool
+	groupedUsage      bool
+	usageTemplate     *template.Template
+	conflictStrategy  ConflictStrategy
+	valueResolvers    []ValueResolver
+	beforeSet         []BeforeSetInterceptor
+	afterSet          []AfterSetInterceptor
+	auditor           func(record AuditRecord)
+	// err is an error that occurred while applying an option and is returned by Fill
+	err error
 }
 
 // WithFieldRenamer declares an option to customize the Renamer used to convert field names
@@ -31,8 +79,24 @@ func WithFieldRenamer(renamer Renamer) FillerOption {
 // Fields are mapped to environment variables names by prepending the given prefix and
 // converting word-wise to SCREAMING_SNAKE_CASE. The given prefix can be empty.
 func WithEnv(prefix string) FillerOption {
-	return WithEnvRenamer(
-		CompositeRenamer(PrefixRenamer(prefix), ScreamingSnakeRenamer()))
+	return func(opt *fillerOptions) {
+		opt.envRenamer = append(opt.envRenamer,
+			CompositeRenamer(PrefixRenamer(prefix), ScreamingSnakeRenamer()))
+		opt.envLevelRenamer = append(opt.envLevelRenamer, ScreamingSnakeRenamer())
+		if prefix != "" {
+			opt.envPrefix = ScreamingSnakeRenamer()(prefix) + "_"
+		}
+	}
+}
+
+// WithStrictEnv declares an option where Fill returns an error if any environment variable
+// starting with the prefix given to WithEnv does not map to a field. This catches typos, such
+// as APP_TIMEOUTT, that would otherwise be silently ignored. The option has no effect without
+// a non-empty WithEnv prefix or when combined with NoSetFromEnv.
+func WithStrictEnv() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.strictEnv = true
+	}
 }
 
 // WithEnvRenamer activates pre-setting the flag values from environment variables where fields
@@ -40,6 +104,29 @@ func WithEnv(prefix string) FillerOption {
 func WithEnvRenamer(renamer Renamer) FillerOption {
 	return func(opt *fillerOptions) {
 		opt.envRenamer = append(opt.envRenamer, renamer)
+		opt.envLevelRenamer = append(opt.envLevelRenamer, renamer)
+	}
+}
+
+// EnvNameMapper is given the dot-separated path of a field, such as Remote.Auth.Username, and the
+// environment variable name computed by any env renamers, which is empty if none were declared.
+// It returns the environment variable name(s) to use, comma-separated, or an empty string to
+// not map the field to an environment variable.
+type EnvNameMapper func(fieldPath string, defaultName string) string
+
+// WithEnvNameMapper declares an option to programmatically control the environment variable
+// names of fields without an env tag. For example, to map the fields of a Database struct onto
+// the legacy PGHOST and PGPORT variables:
+//
+//	flagsfiller.WithEnvNameMapper(func(fieldPath, defaultName string) string {
+//		if strings.HasPrefix(fieldPath, "Database.") {
+//			return "PG" + strings.ToUpper(strings.TrimPrefix(fieldPath, "Database."))
+//		}
+//		return defaultName
+//	})
+func WithEnvNameMapper(mapper EnvNameMapper) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.envNameMapper = mapper
 	}
 }
 
@@ -52,14 +139,440 @@ func NoSetFromEnv() FillerOption {
 	}
 }
 
+// WithEnvFiles declares an option where each field mapped to environment variables can also be
+// set from the contents of a file whose path is given by the same variable suffixed with _FILE,
+// such as APP_TOKEN_FILE for APP_TOKEN, which follows the convention of Docker secrets. Trailing
+// newlines of the file are trimmed and the variable without the suffix takes precedence.
+func WithEnvFiles() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.envFiles = true
+	}
+}
+
+// WithEnvErrorHandler declares an option where a failure to convert an environment variable's
+// value is passed to the given handler rather than aborting Fill, or ParseWithSources, with an
+// error. The field retains its default value in that case.
+func WithEnvErrorHandler(handler func(err error)) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.envErrorHandler = handler
+	}
+}
+
+// WithDotEnv declares an option that loads the KEY=VALUE entries of the .env files at the given
+// paths, which are used for the environment variables of the fields as if they were set, but
+// without modifying the process environment. The variables that are actually set take precedence,
+// followed by the files in the given order. This composes with the other environment variable
+// options, such as WithEnv. An error loading a file is returned by Fill.
+func WithDotEnv(paths ...string) FillerOption {
+	return func(opt *fillerOptions) {
+		for _, path := range paths {
+			entries, err := loadDotEnv(path)
+			if err != nil {
+				if opt.err == nil {
+					opt.err = fmt.Errorf("failed to load env file %s: %w", path, err)
+				}
+				return
+			}
+			if opt.dotEnv == nil {
+				opt.dotEnv = make(map[string]string)
+			}
+			for key, value := range entries {
+				if _, exists := opt.dotEnv[key]; !exists {
+					opt.dotEnv[key] = value
+				}
+			}
+		}
+	}
+}
+
+// WithDeferredSources declares an option where environment variables and config files are not
+// applied during Fill but instead when calling FlagSetFiller.ParseWithSources. This ensures that
+// defaults assigned to the struct after Fill do not silently replace values from those sources.
+func WithDeferredSources() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.deferSources = true
+	}
+}
+
+// WithFieldConverter declares an option that uses the given converter to parse the values of the
+// field at the given dot-separated path, such as "Remote.Timeout". The converter's result must be
+// convertible to the field's type. This is useful when the field's type cannot be changed, such
+// as with generated code.
+func WithFieldConverter(fieldPath string, converter func(s string) (interface{}, error)) FillerOption {
+	return func(opt *fillerOptions) {
+		if opt.fieldConverters == nil {
+			opt.fieldConverters = make(map[string]func(s string) (interface{}, error))
+		}
+		opt.fieldConverters[fieldPath] = converter
+	}
+}
+
+// DefaultsProvider is given the dot-separated path of a field, such as Remote.Auth.Username, and
+// returns the default value to use as if it was declared with a default tag. The boolean result
+// indicates if a default value was provided.
+type DefaultsProvider func(fieldPath string) (string, bool)
+
+// WithDefaultsProvider declares an option where the given provider is consulted for the default
+// value of fields without a default tag. This allows for defaults sourced from build-time
+// variables, embedded metadata, or a defaults registry. When given multiple times, the providers
+// are consulted in order.
+func WithDefaultsProvider(provider DefaultsProvider) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.defaultsProviders = append(opt.defaultsProviders, provider)
+	}
+}
+
+// WithDefaultsFS declares an option that loads default values from the YAML, or JSON, file at
+// the given path within fsys, such as an embed.FS. The file's nested keys correspond to the
+// nested struct fields, where keys are matched case-insensitively ignoring dashes and
+// underscores. For example, the field Remote.MaxTimeout is given a default by
+//
+//	remote:
+//	  max-timeout: 5s
+//
+// As with WithDefaultsProvider, the values are only used for fields without a default tag.
+// An error loading the file is returned by Fill.
+func WithDefaultsFS(fsys fs.FS, path string) FillerOption {
+	return func(opt *fillerOptions) {
+		tree, err := loadValueTree(fsys, path)
+		if err != nil {
+			if opt.err == nil {
+				opt.err = fmt.Errorf("failed to load defaults from %s: %w", path, err)
+			}
+			return
+		}
+		opt.defaultsProviders = append(opt.defaultsProviders, tree.lookup)
+	}
+}
+
+// WithConfigFile declares an option that loads the YAML, or JSON, file at the given path and sets
+// the fields from its values when filling, where the file's nested keys correspond to the nested
+// struct fields like with WithDefaultsFS. When the file has no value at a field's nested keys, the
+// field's flag name is also looked up as a top-level key. Files with a .toml extension are loaded
+// as TOML. Unlike defaults, the values take precedence over default tags, so the resulting
+// precedence, from lowest to highest, is default values, config files, environment variables, and
+// then command-line arguments. When given multiple times, the files are applied in order. An error
+// loading the file is returned by Fill.
+func WithConfigFile(path string) FillerOption {
+	return func(opt *fillerOptions) {
+		file, err := loadConfigFile(path, nil)
+		if err != nil {
+			if opt.err == nil {
+				opt.err = fmt.Errorf("failed to load config file %s: %w", path, err)
+			}
+			return
+		}
+		opt.configFiles = append(opt.configFiles, file)
+	}
+}
+
+// WithRemoteSources declares an option that sets the fields from the values that the given
+// sources, such as adapters of etcd or Consul, have for their paths. The values take precedence
+// over config files, so the resulting precedence, from lowest to highest, is default values,
+// config files, remote sources, environment variables, and then command-line arguments. When
+// several sources have a value for a field, the last one wins. An error looking up a value is
+// returned by Fill, or ParseWithSources with the WithDeferredSources option.
+func WithRemoteSources(sources ...RemoteSource) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.remoteSources = append(opt.remoteSources, sources...)
+	}
+}
+
+// WithTOML declares an option like WithConfigFile; however, the file at the given path is always
+// loaded as TOML, where tables correspond to the nested struct fields.
+func WithTOML(path string) FillerOption {
+	return func(opt *fillerOptions) {
+		file, err := loadConfigFile(path, decodeTOMLValueTree)
+		if err != nil {
+			if opt.err == nil {
+				opt.err = fmt.Errorf("failed to load TOML config file %s: %w", path, err)
+			}
+			return
+		}
+		opt.configFiles = append(opt.configFiles, file)
+	}
+}
+
+// WithArgFiles enables argument files, where ParseWithSources, as well as the convenience
+// functions such as Parse, replace each argument of the form @path with the arguments read from
+// the file at path, one per line. This allows for command lines that exceed the limits of the OS,
+// such as ones generated by build systems.
+func WithArgFiles() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.argFiles = true
+	}
+}
+
+// WithErrorHandling declares the flag.ErrorHandling to use for parsing errors with the convenience
+// functions, such as Parse and ParseArgs.
+func WithErrorHandling(errorHandling flag.ErrorHandling) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.errorHandling = &errorHandling
+	}
+}
+
+// WithOutput declares the writer where the convenience functions, such as Parse and ParseArgs,
+// write usage and error messages.
+func WithOutput(output io.Writer) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.output = output
+	}
+}
+
+// defaultValueSplitter splits values on newlines and commas
+var defaultValueSplitter = regexp.MustCompile("[\n,]")
+
 // WithValueSplitPattern allows for changing the default value splitting regex pattern from newlines and commas.
 // Any empty string can be provided for pattern to disable value splitting.
+// Fill returns an error if the pattern does not compile.
 func WithValueSplitPattern(pattern string) FillerOption {
 	return func(opt *fillerOptions) {
-		opt.valueSplitPattern = pattern
+		if pattern == "" {
+			opt.valueSplitter = nil
+			return
+		}
+		splitter, err := regexp.Compile(pattern)
+		if err != nil {
+			if opt.err == nil {
+				opt.err = fmt.Errorf("invalid value split pattern: %w", err)
+			}
+			return
+		}
+		opt.valueSplitter = splitter
 	}
 }
 
+// WithEmptySlices declares an option that distinguishes empty from nil []string fields. Fields
+// that are nil and have no default tag are set to an empty, non-nil slice, and giving an empty
+// value, such as --list "", sets the field to an empty slice, replacing any default values.
+func WithEmptySlices() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.emptySlices = true
+	}
+}
+
+// WithPreserveWhitespace declares an option that keeps the leading and trailing whitespace of
+// []string elements and map[string]string entries, which are otherwise trimmed. A field can
+// override this option with the `trim:"true"` or `trim:"false"` tag.
+func WithPreserveWhitespace() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.preserveSpace = true
+	}
+}
+
+// WithEmptyElements declares an option that keeps the blank elements of []string values, which
+// are otherwise dropped, such that --cols a,,c results in three elements. A field can override
+// this option with the `keep-empty:"true"` or `keep-empty:"false"` tag.
+func WithEmptyElements() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.keepEmpty = true
+	}
+}
+
+// WithNestedNameSeparator declares an option that joins the names of nested struct levels in
+// flag names with the given separator. For example, with WithNestedNameSeparator(".") the
+// nested field Remote.Auth.MaxTimeout is mapped to the flag remote.auth.max-timeout. The field
+// renamers are applied to the name of each level on its own.
+func WithNestedNameSeparator(separator string) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.nestedSeparator = separator
+	}
+}
+
+// WithEnvNestedNameSeparator declares an option that joins the names of nested struct levels in
+// environment variable names with the given separator. For example, with WithEnv("App") and
+// WithEnvNestedNameSeparator("__") the nested field Remote.MaxTimeout is mapped to the
+// environment variable APP_REMOTE__MAX_TIMEOUT. The env renamers are applied to the name of each
+// level on its own, and the prefix given to WithEnv is only placed ahead of the first level.
+func WithEnvNestedNameSeparator(separator string) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.envNestedSep = separator
+	}
+}
+
+// WithDashUnderscoreAliases declares an option that registers an additional alias for each flag
+// name and alias where dashes are swapped for underscores and vice versa. For example, the flag
+// multi-word-name can then also be passed as multi_word_name.
+func WithDashUnderscoreAliases() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.dashUnderscore = true
+	}
+}
+
+// WithStrictTags declares an option where Fill returns an error for struct tags that are malformed,
+// have an unknown key, such as defult, or have an invalid value, such as a layout on a non-time
+// field. The errors include the path of the field. Tags used by other libraries, such as json or
+// yaml, must be given as allowed keys.
+func WithStrictTags(allowed ...string) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.strictTags = true
+		if opt.allowedTags == nil {
+			opt.allowedTags = make(map[string]bool)
+		}
+		for _, key := range allowed {
+			opt.allowedTags[key] = true
+		}
+	}
+}
+
+// WithRequiredUsage declares an option where Fill returns an error listing the paths of any
+// fields that declare a flag but lack a usage tag. Filling a config struct with this option in a
+// unit test ensures that all flags are described in the help output.
+func WithRequiredUsage() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.requireUsage = true
+	}
+}
+
+// WithGroupedUsage declares an option where Fill replaces the usage of the flag set to group the
+// flags of nested structs under headings derived from the structs' field names, such as
+// "Remote Auth:", rather than listing all flags alphabetically. The flags of top-level fields are
+// listed first.
+func WithGroupedUsage() FillerOption {
+	return func(opt *fillerOptions) {
+		opt.groupedUsage = true
+	}
+}
+
+// WithUsageTemplate declares an option where Fill replaces the usage of the flag set with one
+// that executes the given template, which gives applications full control of the help
+// formatting, such as adding examples and footer text. The template is given a UsageData, whose
+// Flags describe each flag's name, aliases, environment variables, default, usage, type, and
+// group, such as
+//
+//	{{range .Flags}}  --{{.Name}}{{if .Type}} {{.Type}}{{end}}	{{.Usage}}
+//	{{end}}
+func WithUsageTemplate(tmpl *template.Template) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.usageTemplate = tmpl
+	}
+}
+
+// ConflictStrategy declares how Fill handles a flag name or alias that is already defined in the
+// flag set, such as when filling the same flag set more than once
+type ConflictStrategy int
+
+const (
+	// ConflictError causes Fill to return an error, which is the default
+	ConflictError ConflictStrategy = iota
+	// ConflictSkip keeps the existing flag and skips the field, or just the alias, that conflicts
+	ConflictSkip
+	// ConflictRebind updates the existing flag to set the field being filled
+	ConflictRebind
+)
+
+// WithConflictStrategy declares an option to choose how Fill handles flag names and aliases that
+// are already defined, such as when calling Fill again after adding fields or when subcommands
+// share a base struct.
+func WithConflictStrategy(strategy ConflictStrategy) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.conflictStrategy = strategy
+	}
+}
+
+// WithValueResolver declares an option that registers a resolver called with every raw value
+// before it is converted, including the defaults and the values from environment variables, such
+// that references like "vault:secret/db#password" can be resolved from a secret manager.
+// Resolvers are called in the order they were registered and before the WithBeforeSet
+// interceptors. As with WithBeforeSet, the help output shows the type of standard flags as "value".
+func WithValueResolver(resolver ValueResolver) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.valueResolvers = append(opt.valueResolvers, resolver)
+	}
+}
+
+// WithBeforeSet declares an option that registers an interceptor called before every flag is
+// set, including from environment variables. Interceptors can normalize the value, such as
+// trimming or lowercasing it, or reject it by returning an error. Interceptors are called in the
+// order they were registered.
+// Since the flag values are wrapped to call the interceptors, the help output shows the type of
+// standard flags, such as int, as "value" unless the usage declares a name, such as "the [port]".
+func WithBeforeSet(interceptor BeforeSetInterceptor) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.beforeSet = append(opt.beforeSet, interceptor)
+	}
+}
+
+// WithAfterSet declares an option that registers an interceptor called after every flag is
+// successfully set, including from environment variables, which is useful for auditing and
+// metrics. Interceptors are called in the order they were registered.
+// As with WithBeforeSet, the help output shows the type of standard flags as "value".
+func WithAfterSet(interceptor AfterSetInterceptor) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.afterSet = append(opt.afterSet, interceptor)
+	}
+}
+
+// WithAuditor declares an option where the given function is called with a record for every
+// flag value that is set, including from environment variables and by SetFromMap, which allows
+// for auditing configuration reloads.
+func WithAuditor(auditor func(record AuditRecord)) FillerOption {
+	return func(opt *fillerOptions) {
+		opt.auditor = auditor
+	}
+}
+
+// WithAuditLogger declares an option like WithAuditor that logs each record at info level with
+// the given logger.
+func WithAuditLogger(logger *slog.Logger) FillerOption {
+	return WithAuditor(func(record AuditRecord) {
+		logger.LogAttrs(context.Background(), slog.LevelInfo, "configuration changed",
+			slog.String("source", string(record.Source)),
+			slog.String("field", record.Field),
+			slog.String("old", record.Old),
+			slog.String("new", record.New),
+		)
+	})
+}
+
+// appendLevel returns the given nesting levels followed by name, without sharing the backing
+// array of levels between siblings. An empty name, such as from an empty envPrefix tag, adds
+// no level.
+func appendLevel(levels []string, name string) []string {
+	if name == "" {
+		return levels
+	}
+	return append(slices.Clip(levels), name)
+}
+
+// renameLevels computes the flag name of a field from the names of its nesting levels. Without a
+// nested name separator, the renamers are given the levels joined by dashes, and otherwise each
+// level is renamed on its own and the results are joined by the separator.
+func (o *fillerOptions) renameLevels(levels []string) string {
+	if o.nestedSeparator == "" {
+		return o.renameLongName(strings.Join(levels, "-"))
+	}
+	renamed := make([]string, len(levels))
+	for i, level := range levels {
+		renamed[i] = o.renameLongName(level)
+	}
+	return strings.Join(renamed, o.nestedSeparator)
+}
+
+// renameEnvLevels is like renameLevels for environment variable names, where the prefix given to
+// WithEnv is only placed ahead of the first level.
+func (o *fillerOptions) renameEnvLevels(levels []string) string {
+	if o.envNestedSep == "" {
+		return applyRenamers(o.envRenamer, strings.Join(levels, "-"))
+	}
+	renamed := make([]string, len(levels))
+	for i, level := range levels {
+		if i == 0 {
+			renamed[i] = applyRenamers(o.envRenamer, level)
+		} else {
+			renamed[i] = applyRenamers(o.envLevelRenamer, level)
+		}
+	}
+	return strings.Join(renamed, o.envNestedSep)
+}
+
+func applyRenamers(renamers []Renamer, name string) string {
+	for _, renamer := range renamers {
+		name = renamer(name)
+	}
+	return name
+}
+
 func (o *fillerOptions) renameLongName(name string) string {
 	if len(o.fieldRenamer) == 0 {
 		return DefaultFieldRenamer(name)
@@ -73,7 +586,7 @@ func (o *fillerOptions) renameLongName(name string) string {
 
 func newFillerOptions(options ...FillerOption) *fillerOptions {
 	v := &fillerOptions{
-		valueSplitPattern: "[\n,]",
+		valueSplitter: defaultValueSplitter,
 	}
 	for _, opt := range options {
 		opt(v)
@@ -88,14 +601,57 @@ func PrefixRenamer(prefix string) Renamer {
 	}
 }
 
+var (
+	kebabNames          sync.Map
+	screamingSnakeNames sync.Map
+)
+
 // KebabRenamer converts a given name into kebab-case
 func KebabRenamer() Renamer {
-	return strcase.ToKebab
+	return cachedRenamer(&kebabNames, strcase.ToKebab)
+}
+
+// DotRenamer converts a given name into dot-separated namespaces where each nested struct level
+// is converted into kebab-case. For example, the nested field Remote.Auth.MaxTimeout becomes
+// remote.auth.max-timeout
+func DotRenamer() Renamer {
+	return func(name string) string {
+		parts := strings.Split(name, "-")
+		for i, part := range parts {
+			parts[i] = strcase.ToKebab(part)
+		}
+		return strings.Join(parts, ".")
+	}
+}
+
+// CamelCaseRenamer converts a given name into CamelCase, such as MaxTimeout
+func CamelCaseRenamer() Renamer {
+	return strcase.ToCamel
+}
+
+// LowerCamelRenamer converts a given name into lowerCamelCase, such as maxTimeout
+func LowerCamelRenamer() Renamer {
+	return strcase.ToLowerCamel
+}
+
+// FlatRenamer converts a given name into lowercase with all word separators removed, such as
+// multiwordname
+func FlatRenamer() Renamer {
+	return func(name string) string {
+		return strings.Map(func(r rune) rune {
+			switch r {
+			case '-', '_', '.', ' ':
+				return -1
+			default:
+				return unicode.ToLower(r)
+			}
+		}, name)
+	}
 }
 
 // ScreamingSnakeRenamer converts a given name into SCREAMING_SNAKE_CASE
 func ScreamingSnakeRenamer() Renamer {
-	return strcase.ToScreamingSnake
+	return cachedRenamer(&screamingSnakeNames, strcase.ToScreamingSnake)
 }
 
 // CompositeRenamer applies all of the given Renamers to a name
@@ -15,3 +15,40 @@ func ExampleCompositeRenamer() {
 	// Output:
 	// APP_SOME_FIELD_NAME
 }
+
+func ExampleDotRenamer() {
+	renamer := flagsfiller.DotRenamer()
+
+	renamed := renamer("Remote-Auth-MaxTimeout")
+	fmt.Println(renamed)
+	// Output:
+	// remote.auth.max-timeout
+}
+
+func ExampleLowerCamelRenamer() {
+	renamer := flagsfiller.LowerCamelRenamer()
+
+	fmt.Println(renamer("MaxTimeout"))
+	fmt.Println(renamer("Remote-MaxTimeout"))
+	// Output:
+	// maxTimeout
+	// remoteMaxTimeout
+}
+
+func ExampleCamelCaseRenamer() {
+	renamer := flagsfiller.CamelCaseRenamer()
+
+	fmt.Println(renamer("max-timeout"))
+	// Output:
+	// MaxTimeout
+}
+
+func ExampleFlatRenamer() {
+	renamer := flagsfiller.FlatRenamer()
+
+	fmt.Println(renamer("MultiWordName"))
+	fmt.Println(renamer("Remote-Auth_User.Name"))
+	// Output:
+	// multiwordname
+	// remoteauthusername
+}
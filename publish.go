@@ -0,0 +1,41 @@
+package flagsfiller
+
+import (
+	"reflect"
+)
+
+// EffectiveConfig returns the current values of the given config struct reference keyed by
+// field path, such as "Remote.Auth.Username". The values of fields declared with
+// `sensitive:"true"` are redacted.
+func EffectiveConfig(from interface{}) map[string]string {
+	values := make(map[string]string)
+	v := reflect.ValueOf(from)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return values
+	}
+	visitLeaves(v.Elem(), "", false, func(leaf leafValue) {
+		values[leaf.path] = leaf.display()
+	})
+	return values
+}
+
+// NonDefaultValues returns the current values of the fields filled by this FlagSetFiller that
+// differ from their defaults, keyed by field path, such as "Remote.Auth.Username". This reports
+// what an operator changed by arguments, environment variables, or other sources, which is more
+// concise than the effective config for startup logs and bug reports. The values of fields
+// declared with `sensitive:"true"` are redacted.
+func (f *FlagSetFiller) NonDefaultValues() map[string]string {
+	values := make(map[string]string)
+	for _, path := range f.paths {
+		field := f.fields[path]
+		value := formatValue(reflect.ValueOf(field.Value).Elem())
+		if value == f.fieldDefaults[path] {
+			continue
+		}
+		if isSensitive(field.StructField.Tag) {
+			value = redacted
+		}
+		values[path] = value
+	}
+	return values
+}
@@ -0,0 +1,59 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedConfig struct {
+	Host    string
+	Timeout time.Duration
+	Remote  struct {
+		Password string `sensitive:"true"`
+	}
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	config := publishedConfig{Host: "localhost", Timeout: time.Second}
+	config.Remote.Password = "hunter2"
+
+	assert.Equal(t, map[string]string{
+		"Host":            "localhost",
+		"Timeout":         "1s",
+		"Remote.Password": "*****",
+	}, flagsfiller.EffectiveConfig(&config))
+}
+
+func TestNonDefaultValues(t *testing.T) {
+	type Config struct {
+		Host    string            `default:"localhost"`
+		Port    int               `default:"8080"`
+		Timeout time.Duration     `default:"5s"`
+		Labels  map[string]string `default:"a=1,b=2"`
+		Remote  struct {
+			Password string `sensitive:"true"`
+		}
+	}
+
+	t.Setenv("NDV_PORT", "9090")
+
+	var config Config
+	filler := flagsfiller.New(flagsfiller.WithEnv("Ndv"))
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = flagset.Parse([]string{"--timeout", "5s", "--remote-password", "hunter2", "--labels", "c=3"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"Port":            "9090",
+		"Labels":          "a=1,b=2,c=3",
+		"Remote.Password": "*****",
+	}, filler.NonDefaultValues())
+}
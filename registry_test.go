@@ -0,0 +1,97 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type endpoint struct {
+	Host string
+	Port string
+}
+
+func parseEndpoint(s string) (interface{}, error) {
+	host, port, found := strings.Cut(s, ":")
+	if !found {
+		return nil, fmt.Errorf("%s is not host:port", s)
+	}
+	return endpoint{Host: host, Port: port}, nil
+}
+
+func TestRegisterType(t *testing.T) {
+	type Config struct {
+		Primary  endpoint `default:"localhost:80"`
+		Fallback *endpoint
+		Weights  map[string]endpoint
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+	filler.RegisterType(endpoint{}, parseEndpoint)
+
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, endpoint{Host: "localhost", Port: "80"}, config.Primary)
+
+	err = flagset.Parse([]string{"--primary", "example.com:443", "--fallback", "backup:8080",
+		"--weights", "a=one:1"})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint{Host: "example.com", Port: "443"}, config.Primary)
+	require.NotNil(t, config.Fallback)
+	assert.Equal(t, endpoint{Host: "backup", Port: "8080"}, *config.Fallback)
+	assert.Equal(t, map[string]endpoint{"a": {Host: "one", Port: "1"}}, config.Weights)
+
+	// other fillers walk into the struct as before
+	var other Config
+	otherFlags := flag.NewFlagSet("other", flag.ContinueOnError)
+	require.NoError(t, flagsfiller.New().Fill(otherFlags, &other))
+	assert.Nil(t, otherFlags.Lookup("primary"))
+	assert.NotNil(t, otherFlags.Lookup("primary-host"))
+}
+
+func TestRegisterTypeOverridesPackageType(t *testing.T) {
+	type Config struct {
+		Started time.Time `default:"1700000000"`
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+	filler.RegisterType(time.Time{}, func(s string) (interface{}, error) {
+		var seconds int64
+		_, err := fmt.Sscan(s, &seconds)
+		return time.Unix(seconds, 0).UTC(), err
+	})
+
+	err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), config.Started)
+}
+
+func TestRegisterTypeConcurrentFill(t *testing.T) {
+	type Config struct {
+		Primary endpoint `default:"localhost:80"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var config Config
+			filler := flagsfiller.New()
+			filler.RegisterType(endpoint{}, parseEndpoint)
+			assert.NoError(t, filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config))
+			assert.Equal(t, "localhost", config.Primary.Host)
+		}()
+	}
+	wg.Wait()
+}
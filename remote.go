@@ -0,0 +1,50 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+)
+
+// RemoteSource is a key-value backend, such as etcd, Consul, or AWS SSM, that the fields can be
+// set from with the WithRemoteSources option. Lookup is called with the path of each field, such
+// as "Remote.Timeout", and reports if the backend has a value for it.
+type RemoteSource interface {
+	Lookup(key string) (value string, found bool, err error)
+}
+
+// RemoteSourceFunc adapts a function to a RemoteSource
+type RemoteSourceFunc func(key string) (string, bool, error)
+
+// Lookup calls the function
+func (f RemoteSourceFunc) Lookup(key string) (string, bool, error) {
+	return f(key)
+}
+
+// applyRemoteSources sets the flag of the field at path from the remote sources that have a value
+// for it, in the order the sources were given
+func (f *FlagSetFiller) applyRemoteSources(flagSet *flag.FlagSet, path string, flagName string) error {
+	if len(f.options.remoteSources) == 0 {
+		return nil
+	}
+	f.source = SourceRemote
+	defer func() {
+		f.source = SourceArgs
+	}()
+	for _, source := range f.options.remoteSources {
+		val, found, err := source.Lookup(path)
+		if err != nil {
+			return fmt.Errorf("failed to look up %s in remote source: %w", path, err)
+		}
+		if !found {
+			continue
+		}
+		value := flagSet.Lookup(flagName).Value
+		err = value.Set(val)
+		if err != nil {
+			return fmt.Errorf("failed to set %s from remote source: %w", path, f.redactError(flagName, err, val))
+		}
+		endSource(value)
+		f.fieldSources[path] = SourceRemote
+	}
+	return nil
+}
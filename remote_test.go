@@ -0,0 +1,91 @@
+package flagsfiller_test
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSource is a RemoteSource backed by a map, standing in for a key-value backend
+type mapSource map[string]string
+
+func (s mapSource) Lookup(key string) (string, bool, error) {
+	value, found := s[key]
+	return value, found, nil
+}
+
+func TestWithRemoteSources(t *testing.T) {
+	path := writeConfigFile(t, "host: from-file\nport: 9090\n")
+	t.Setenv("REMOTE_PORT", "7070")
+
+	var config configFileConfig
+	filler := flagsfiller.New(
+		flagsfiller.WithConfigFile(path),
+		flagsfiller.WithEnv("Remote"),
+		flagsfiller.WithRemoteSources(
+			mapSource{"Host": "from-first", "Timeout": "10s"},
+			mapSource{"Host": "from-second", "Port": "6060", "Remote.MaxTimeout": "1m"},
+		),
+	)
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+	err = flagset.Parse([]string{"--timeout", "20s"})
+	require.NoError(t, err)
+
+	// the last source wins over the config file
+	assert.Equal(t, "from-second", config.Host)
+	// environment variables and arguments win over the sources
+	assert.Equal(t, 7070, config.Port)
+	assert.Equal(t, 20*time.Second, config.Timeout)
+	assert.Equal(t, time.Minute, config.Remote.MaxTimeout)
+
+	sources := filler.Sources(flagset)
+	assert.Equal(t, flagsfiller.SourceRemote, sources["Host"])
+	assert.Equal(t, flagsfiller.SourceEnv, sources["Port"])
+	assert.Equal(t, flagsfiller.SourceArgs, sources["Timeout"])
+	assert.Equal(t, flagsfiller.SourceRemote, sources["Remote.MaxTimeout"])
+}
+
+func TestWithRemoteSourcesDeferred(t *testing.T) {
+	var config configFileConfig
+	filler := flagsfiller.New(
+		flagsfiller.WithRemoteSources(mapSource{"Host": "example.com"}),
+		flagsfiller.WithDeferredSources(),
+	)
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", config.Host)
+
+	err = filler.ParseWithSources(&flagset, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", config.Host)
+}
+
+func TestWithRemoteSourcesErrors(t *testing.T) {
+	t.Run("lookup", func(t *testing.T) {
+		unavailable := errors.New("connection refused")
+		source := flagsfiller.RemoteSourceFunc(func(key string) (string, bool, error) {
+			return "", false, unavailable
+		})
+
+		var config configFileConfig
+		err := flagsfiller.New(flagsfiller.WithRemoteSources(source)).
+			Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to look up Host in remote source")
+		assert.ErrorIs(t, err, unavailable)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var config configFileConfig
+		err := flagsfiller.New(flagsfiller.WithRemoteSources(mapSource{"Port": "eighty"})).
+			Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "failed to set Port from remote source")
+	})
+}
@@ -0,0 +1,82 @@
+package flagsfiller
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// schemas caches the tag-derived details of struct types, keyed by reflect.Type, so that
+// repeatedly filling the same config type only inspects its fields once
+var schemas sync.Map
+
+// typeNames caches the results of getTypeName, keyed by reflect.Type
+var typeNames sync.Map
+
+type structSchema struct {
+	fields []fieldSchema
+}
+
+type fieldSchema struct {
+	reflect.StructField
+	// ignored is set for fields declared with an empty flag tag
+	ignored         bool
+	hasConverterTag bool
+	envPrefix       string
+	hasEnvPrefix    bool
+	// isCommand is set for fields declared with the command tag, which are not mapped to flags
+	isCommand bool
+}
+
+// schemaFor returns the cached schema of the given struct type, computing it on first use
+func schemaFor(t reflect.Type) *structSchema {
+	if cached, ok := schemas.Load(t); ok {
+		return cached.(*structSchema)
+	}
+
+	schema := &structSchema{fields: make([]fieldSchema, t.NumField())}
+	for i := range schema.fields {
+		field := t.Field(i)
+		flagTag, hasFlagTag := field.Tag.Lookup("flag")
+		_, hasConverterTag := converterName(field.Tag)
+		envPrefix, hasEnvPrefix := field.Tag.Lookup("envPrefix")
+		_, isCommand := field.Tag.Lookup("command")
+		schema.fields[i] = fieldSchema{
+			StructField:     field,
+			ignored:         hasFlagTag && flagTag == "",
+			hasConverterTag: hasConverterTag,
+			envPrefix:       envPrefix,
+			hasEnvPrefix:    hasEnvPrefix,
+			isCommand:       isCommand,
+		}
+	}
+
+	actual, _ := schemas.LoadOrStore(t, schema)
+	return actual.(*structSchema)
+}
+
+func getTypeName(t reflect.Type) string {
+	if cached, ok := typeNames.Load(t); ok {
+		return cached.(string)
+	}
+	name := t
+	if name.Kind() == reflect.Pointer {
+		name = name.Elem()
+	}
+	typeName := fmt.Sprint(name)
+	typeNames.Store(t, typeName)
+	return typeName
+}
+
+// cachedRenamer memoizes the results of a renamer that only depends on its input, such as the
+// strcase conversions. Changes to the strcase acronyms after names are cached are not reflected.
+func cachedRenamer(cache *sync.Map, renamer Renamer) Renamer {
+	return func(name string) string {
+		if cached, ok := cache.Load(name); ok {
+			return cached.(string)
+		}
+		renamed := renamer(name)
+		cache.Store(name, renamed)
+		return renamed
+	}
+}
@@ -0,0 +1,97 @@
+package flagsfiller
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// redactDefault shows the default of the given flag as redacted when the field is declared with
+// `sensitive:"true"` and the default is not the zero value
+func redactDefault(declared *flag.Flag, tag reflect.StructTag) {
+	if isSensitive(tag) && !isZeroDefault(declared) {
+		declared.DefValue = redacted
+	}
+}
+
+// redactedError is an error whose message has a sensitive value replaced by redacted
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError replaces the occurrences of value in the message of err, if any
+func redactError(err error, value string) error {
+	if err == nil || value == "" || !strings.Contains(err.Error(), value) {
+		return err
+	}
+	return &redactedError{err: err, message: strings.ReplaceAll(err.Error(), value, redacted)}
+}
+
+// redactError redacts the value from the error of setting the named flag when it is sensitive
+func (f *FlagSetFiller) redactError(flagName string, err error, value string) error {
+	if !f.sensitiveFlags[flagName] {
+		return err
+	}
+	return redactError(err, value)
+}
+
+// invalidValuePattern matches the error of flag.FlagSet for an invalid argument, capturing the
+// quoted argument and the flag name
+var invalidValuePattern = regexp.MustCompile(`^invalid (?:boolean )?value ("(?:[^"\\]|\\.)*") for (?:flag )?-([^:\s]+):`)
+
+// redactParseError redacts the argument quoted by the parse error of flag.FlagSet when it was
+// given for a sensitive flag
+func (f *FlagSetFiller) redactParseError(err error) error {
+	message, ok := f.redactParseMessage(err.Error())
+	if !ok {
+		return err
+	}
+	return &redactedError{err: err, message: message}
+}
+
+// redactParseMessage redacts the argument quoted by a parse error message of flag.FlagSet and
+// reports if it was given for a sensitive flag
+func (f *FlagSetFiller) redactParseMessage(message string) (string, bool) {
+	match := invalidValuePattern.FindStringSubmatch(message)
+	if match == nil {
+		return message, false
+	}
+	path, ok := f.flagPaths[match[2]]
+	if !ok || !f.sensitiveFlags[f.fieldFlags[path]] {
+		return message, false
+	}
+	message = strings.Replace(message, match[1], strconv.Quote(redacted), 1)
+	// the error of the flag's value may also include the argument
+	if value, err := strconv.Unquote(match[1]); err == nil && value != "" {
+		message = strings.ReplaceAll(message, value, redacted)
+	}
+	return message, true
+}
+
+// redactingWriter redacts the sensitive arguments quoted by the parse errors that flag.FlagSet
+// prints to its output
+type redactingWriter struct {
+	filler *FlagSetFiller
+	out    io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	message, ok := w.filler.redactParseMessage(string(p))
+	if !ok {
+		return w.out.Write(p)
+	}
+	_, err := io.WriteString(w.out, message)
+	return len(p), err
+}
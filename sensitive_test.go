@@ -0,0 +1,109 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitiveDefaultRedacted(t *testing.T) {
+	type Config struct {
+		Token    string `default:"s3cr3t" sensitive:"true" aliases:"t" usage:"API token"`
+		Password string `sensitive:"true"`
+		Host     string `default:"localhost"`
+	}
+
+	var config Config
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := flagsfiller.New().Fill(flagset, &config)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", config.Token)
+
+	var buf bytes.Buffer
+	flagset.SetOutput(&buf)
+	flagset.PrintDefaults()
+	assert.Equal(t, `  -host string
+    	 (default "localhost")
+  -password string
+    	
+  -t string
+    	API token (default "*****")
+  -token string
+    	API token (default "*****")
+`, buf.String())
+	assert.NotContains(t, buf.String(), "s3cr3t")
+}
+
+func TestSensitiveValueNotInErrors(t *testing.T) {
+	type Config struct {
+		Pin int16 `sensitive:"true"`
+	}
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("SENSITIVE_PIN", "12ab34")
+
+		var config Config
+		err := flagsfiller.New(flagsfiller.WithEnv("Sensitive")).
+			Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to set from environment variable SENSITIVE_PIN")
+		assert.Contains(t, err.Error(), "***** is not a valid int16")
+		assert.NotContains(t, err.Error(), "12ab34")
+	})
+
+	t.Run("default", func(t *testing.T) {
+		type Defaulted struct {
+			Pin int16 `sensitive:"true" default:"12ab34"`
+		}
+
+		var config Defaulted
+		err := flagsfiller.New().Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "***** is not a valid int16")
+		assert.NotContains(t, err.Error(), "12ab34")
+	})
+
+	t.Run("args", func(t *testing.T) {
+		var config Config
+		var output bytes.Buffer
+		_, err := flagsfiller.ParseArgs(&config, []string{"--pin", "12ab34"}, flagsfiller.WithOutput(&output))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid value "*****" for flag -pin`)
+		assert.NotContains(t, err.Error(), "12ab34")
+		assert.Contains(t, output.String(), `invalid value "*****" for flag -pin`)
+		assert.Contains(t, output.String(), "Usage of")
+		assert.NotContains(t, output.String(), "12ab34")
+	})
+
+	t.Run("args with sources", func(t *testing.T) {
+		var config Config
+		var output bytes.Buffer
+		flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+		flagset.SetOutput(&output)
+		filler := flagsfiller.New()
+		require.NoError(t, filler.Fill(flagset, &config))
+
+		err := filler.ParseWithSources(flagset, []string{"--pin=12ab34"})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "12ab34")
+		assert.Contains(t, output.String(), `invalid value "*****" for flag -pin`)
+		assert.NotContains(t, output.String(), "12ab34")
+		assert.Same(t, &output, flagset.Output())
+	})
+
+	t.Run("not sensitive", func(t *testing.T) {
+		type Plain struct {
+			Pin int16
+		}
+		t.Setenv("PLAIN_PIN", "12ab34")
+
+		var config Plain
+		err := flagsfiller.New(flagsfiller.WithEnv("Plain")).
+			Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.ErrorContains(t, err, "12ab34")
+	})
+}
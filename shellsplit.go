@@ -0,0 +1,84 @@
+package flagsfiller
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"unicode"
+)
+
+// ParseString is a convenience function like ParseArgs; however, the args are given as a single
+// string, such as "--host example.com --timeout 5s", which is split like a POSIX shell does.
+// Arguments can be quoted with single or double quotes and a backslash escapes the next character,
+// except within single quotes. This is useful for configs delivered as a single string, such as
+// an APP_OPTS environment variable, a job definition, or a test fixture.
+func ParseString(from interface{}, args string, options ...FillerOption) (*flag.FlagSet, error) {
+	split, err := splitArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return ParseArgs(from, split, options...)
+}
+
+// splitArgs splits the given string into arguments like a POSIX shell, but without any expansion
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	// inArg tracks if an argument was started, which allows for empty quoted arguments
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			// within double quotes, a backslash only escapes the characters that are special there
+			if quote == '"' && !strings.ContainsRune("\"\\$`\n", r) {
+				current.WriteRune('\\')
+			}
+			// an escaped newline continues the line
+			if r != '\n' {
+				current.WriteRune(r)
+				inArg = true
+			}
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("args end with an unescaped backslash")
+	}
+	if quote != 0 {
+		return nil, errors.New("args have an unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
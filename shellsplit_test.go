@@ -0,0 +1,69 @@
+package flagsfiller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseString(t *testing.T) {
+	type Config struct {
+		Host    string
+		Timeout time.Duration
+		Tags    []string
+		Verbose bool
+	}
+
+	var config Config
+	flagset, err := flagsfiller.ParseString(&config, "--host example.com  --timeout 5s -verbose extra")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", config.Host)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.True(t, config.Verbose)
+	assert.Equal(t, []string{"extra"}, flagset.Args())
+}
+
+func TestParseStringQuoting(t *testing.T) {
+	type Config struct {
+		Value string
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{name: "double quotes", args: `--value "hello world"`, want: "hello world"},
+		{name: "single quotes", args: `--value 'a "b" \c'`, want: `a "b" \c`},
+		{name: "escaped space", args: `--value hello\ world`, want: "hello world"},
+		{name: "escapes in double quotes", args: `--value "a \"b\" \c"`, want: `a "b" \c`},
+		{name: "adjacent quotes", args: `--value=a"b c"'d'`, want: "ab cd"},
+		{name: "empty quoted", args: `--value ""`, want: ""},
+		{name: "line continuation", args: "--value \\\n one", want: "one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Value: "unset"}
+			_, err := flagsfiller.ParseString(&config, tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config.Value)
+		})
+	}
+}
+
+func TestParseStringInvalid(t *testing.T) {
+	type Config struct {
+		Value string
+	}
+
+	var config Config
+	_, err := flagsfiller.ParseString(&config, `--value "unterminated`)
+	assert.ErrorContains(t, err, "unterminated quote")
+
+	_, err = flagsfiller.ParseString(&config, `--value trailing\`)
+	assert.ErrorContains(t, err, "unescaped backslash")
+}
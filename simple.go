@@ -11,7 +11,7 @@ import (
 // see time.go and net.go for implementation examples
 func RegisterSimpleType[T any](c ConvertFunc[T]) {
 	base := simpleType[T]{converter: c}
-	extendedTypes[getTypeName(reflect.TypeOf(*new(T)))] = base.Process
+	registerHandler(getTypeName(reflect.TypeOf(*new(T))), base.Process)
 }
 
 // ConvertFunc is a function convert string s into a specific type T, the tag is the struct field tag, as addtional input.
@@ -32,7 +32,8 @@ func (v *simpleType[T]) String() string {
 	if v.val == nil {
 		return fmt.Sprint(nil)
 	}
-	return fmt.Sprint(v.val)
+	// render like the user would give the value, such as by its String or MarshalText methods
+	return formatValue(reflect.ValueOf(v.val))
 }
 
 func (v *simpleType[T]) StrConverter(s string) (T, error) {
@@ -55,7 +56,7 @@ func (v *simpleType[T]) SetRef(t *T) {
 func (v *simpleType[T]) Process(tag reflect.StructTag, fieldRef interface{},
 	hasDefaultTag bool, tagDefault string,
 	flagSet *flag.FlagSet, renamed string,
-	usage string, aliases string) error {
+	usage string) error {
 	val := newSimpleType(v.converter, tag)
-	return processGeneral[T](fieldRef, &val, hasDefaultTag, tagDefault, flagSet, renamed, usage, aliases)
+	return processGeneral[T](fieldRef, &val, hasDefaultTag, tagDefault, flagSet, renamed, usage)
 }
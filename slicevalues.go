@@ -0,0 +1,161 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sliceVar is the flag.Value of slices of parsed elements, such as []int, which follows the same
+// semantics as strSliceVar, where each element is parsed after splitting the values
+type sliceVar[T any] struct {
+	ref      *[]T
+	parse    func(s string) (T, error)
+	override bool
+	parsing  valueParsing
+	// emptySlices indicates an empty value sets the field to an empty slice
+	emptySlices bool
+	// maxOccurs limits the number of times the flag can be given and the number of values given,
+	// when not zero
+	maxOccurs int
+	occurs    int
+	given     int
+	// listing indicates each value is an element of a list, between beginList and endList
+	listing bool
+}
+
+func (s *sliceVar[T]) beginList() {
+	*s.ref = []T{}
+	s.occurs++
+	s.listing = true
+}
+
+func (s *sliceVar[T]) endList() {
+	s.listing = false
+}
+
+func (s *sliceVar[T]) String() string {
+	if s.ref == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(*s.ref))
+	for _, element := range *s.ref {
+		parts = append(parts, fmt.Sprint(element))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *sliceVar[T]) Set(val string) error {
+	if s.listing {
+		return s.setElement(val)
+	}
+	elements, err := parseSlice(val, s.parsing, s.parse)
+	if err != nil {
+		return err
+	}
+
+	if s.maxOccurs > 0 {
+		s.occurs++
+		s.given += len(elements)
+		if s.occurs > s.maxOccurs {
+			return fmt.Errorf("can be given at most %d times", s.maxOccurs)
+		}
+		if s.given > s.maxOccurs {
+			return fmt.Errorf("can have at most %d values", s.maxOccurs)
+		}
+	}
+
+	if s.emptySlices && val == "" {
+		*s.ref = []T{}
+		return nil
+	}
+
+	if s.override {
+		*s.ref = elements
+		return nil
+	}
+
+	*s.ref = append(*s.ref, elements...)
+
+	return nil
+}
+
+// setElement parses val as one element of a list and appends it
+func (s *sliceVar[T]) setElement(val string) error {
+	element, err := s.parse(strings.TrimSpace(val))
+	if err != nil {
+		return err
+	}
+	if s.maxOccurs > 0 {
+		s.given++
+		if s.occurs > s.maxOccurs {
+			return fmt.Errorf("can be given at most %d times", s.maxOccurs)
+		}
+		if s.given > s.maxOccurs {
+			return fmt.Errorf("can have at most %d values", s.maxOccurs)
+		}
+	}
+	*s.ref = append(*s.ref, element)
+	return nil
+}
+
+// parseSlice splits val like parseStringSlice and parses each of the parts, which are trimmed
+// since surrounding spaces are never part of the elements
+func parseSlice[T any](val string, parsing valueParsing, parse func(s string) (T, error)) ([]T, error) {
+	parsing.trimSpace = true
+	parts := parseStringSlice(val, parsing)
+	elements := make([]T, 0, len(parts))
+	for _, part := range parts {
+		element, err := parse(part)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// declareSlice declares a sliceVar for the slice at ref, which is first set from the default tag,
+// if declared
+func declareSlice[T any](ref *[]T, parse func(s string) (T, error), hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string, usage string, parsing valueParsing, override bool, maxOccurs int,
+	emptySlices bool) error {
+	if hasDefaultTag {
+		elements, err := parseSlice(tagDefault, parsing, parse)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into %T: %w", *ref, err)
+		}
+		*ref = elements
+	} else if *ref == nil && emptySlices {
+		*ref = []T{}
+	}
+	flagSet.Var(&sliceVar[T]{
+		ref:         ref,
+		parse:       parse,
+		override:    override,
+		parsing:     parsing,
+		emptySlices: emptySlices,
+		maxOccurs:   maxOccurs,
+	}, renamed, usage)
+	return nil
+}
+
+// processParsedSlice declares the flag of the slice fields whose elements are parsed, which are
+// those of the types in parsedSliceTypes
+func (f *FlagSetFiller) processParsedSlice(fieldRef interface{}, hasDefaultTag bool, tagDefault string, flagSet *flag.FlagSet, renamed string, usage string, parsing valueParsing, override bool, maxOccurs int) error {
+	switch ref := fieldRef.(type) {
+	case *[]int:
+		return declareSlice(ref, strconv.Atoi,
+			hasDefaultTag, tagDefault, flagSet, renamed, usage, parsing, override, maxOccurs, f.options.emptySlices)
+	case *[]int64:
+		return declareSlice(ref, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		}, hasDefaultTag, tagDefault, flagSet, renamed, usage, parsing, override, maxOccurs, f.options.emptySlices)
+	case *[]time.Duration:
+		return declareSlice(ref, time.ParseDuration,
+			hasDefaultTag, tagDefault, flagSet, renamed, usage, parsing, override, maxOccurs, f.options.emptySlices)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package flagsfiller
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// SlogAttr returns a slog attribute with the given key whose value groups the fields of the
+// given config struct, or struct reference, by nested struct. The values of fields declared with
+// `sensitive:"true"` are redacted. This allows for logging the effective config with
+//
+//	logger.Info("starting", flagsfiller.SlogAttr("config", &config))
+func SlogAttr(key string, from interface{}) slog.Attr {
+	return slog.Attr{Key: key, Value: SlogValue(from)}
+}
+
+// SlogValue returns a slog group value of the fields of the given config struct, or struct
+// reference, like SlogAttr.
+func SlogValue(from interface{}) slog.Value {
+	v := reflect.ValueOf(from)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return slog.GroupValue()
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return slog.AnyValue(from)
+	}
+	return slog.GroupValue(slogAttrs(v, false)...)
+}
+
+// slogAttrs converts the fields of the struct value into attributes, following the same rules
+// as visitLeaves for which fields are included and descended into
+func slogAttrs(structVal reflect.Value, sensitive bool) []slog.Attr {
+	structType := structVal.Type()
+	var attrs []slog.Attr
+	for i := 0; i < structVal.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if flagTag, ok := field.Tag.Lookup("flag"); ok && flagTag == "" {
+			continue
+		}
+		fieldSensitive := sensitive || isSensitive(field.Tag)
+		fieldValue := structVal.Field(i)
+
+		nested := fieldValue
+		if nested.Kind() == reflect.Ptr || nested.Kind() == reflect.Interface {
+			if nested.IsNil() {
+				continue
+			}
+			if nested.Kind() == reflect.Interface {
+				nested = nested.Elem()
+			}
+			if nested.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+			}
+		}
+		if nested.Kind() == reflect.Struct && !isLeafStruct(nested.Type()) {
+			attrs = append(attrs, slog.Attr{
+				Key:   field.Name,
+				Value: slog.GroupValue(slogAttrs(nested, fieldSensitive)...),
+			})
+			continue
+		}
+
+		attrs = append(attrs, slog.Attr{Key: field.Name, Value: slogLeafValue(fieldValue, fieldSensitive)})
+	}
+	return attrs
+}
+
+func slogLeafValue(v reflect.Value, sensitive bool) slog.Value {
+	if sensitive {
+		return slog.StringValue(redacted)
+	}
+	switch v.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// retains types, such as time.Duration, that handlers render natively
+		return slog.AnyValue(v.Interface())
+	default:
+		return slog.StringValue(formatValue(v))
+	}
+}
@@ -0,0 +1,62 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogAttr(t *testing.T) {
+	type Config struct {
+		Host    string
+		Timeout time.Duration
+		Tags    []string
+		Addr    net.IP
+		Remote  struct {
+			Auth struct {
+				Username string
+				Password string `sensitive:"true"`
+			}
+			Retries int
+		}
+		Secrets struct {
+			Token string
+		} `sensitive:"true"`
+		Ignored  string `flag:""`
+		internal string
+	}
+
+	config := Config{
+		Host:     "localhost",
+		Timeout:  5 * time.Second,
+		Tags:     []string{"one", "two"},
+		Addr:     net.ParseIP("10.0.0.1"),
+		Ignored:  "ignored",
+		internal: "internal",
+	}
+	config.Remote.Auth.Username = "admin"
+	config.Remote.Auth.Password = "hunter2"
+	config.Remote.Retries = 3
+	config.Secrets.Token = "abc"
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("starting", flagsfiller.SlogAttr("config", &config))
+
+	assert.Equal(t, "level=INFO msg=starting config.Host=localhost config.Timeout=5s"+
+		" config.Tags=one,two config.Addr=10.0.0.1"+
+		" config.Remote.Auth.Username=admin config.Remote.Auth.Password=*****"+
+		" config.Remote.Retries=3 config.Secrets.Token=*****\n", buf.String())
+}
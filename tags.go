@@ -0,0 +1,182 @@
+package flagsfiller
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// knownTags are the struct tag keys processed by the filler
+var knownTags = map[string]bool{
+	"advanced":           true,
+	"aliases":            true,
+	"choices":            true,
+	"command":            true,
+	"complete":           true,
+	"convert":            true,
+	"converter":          true,
+	"default":            true,
+	"default-if":         true,
+	"deprecated":         true,
+	"deprecated-aliases": true,
+	"encoding":           true,
+	"enables":            true,
+	"env":                true,
+	"envfile":            true,
+	"envPrefix":          true,
+	"flag":               true,
+	"keep-empty":         true,
+	"layout":             true,
+	"max":                true,
+	"max-occurs":         true,
+	"min":                true,
+	"override-value":     true,
+	"required":           true,
+	"requires":           true,
+	"schemes":            true,
+	"sensitive":          true,
+	"trim":               true,
+	"type":               true,
+	"usage":              true,
+	"validate":           true,
+}
+
+// knownFieldTypes are the accepted values of the type tag
+var knownFieldTypes = map[string]bool{
+	"bytes":       true,
+	"duration":    true,
+	"stringSlice": true,
+	"stringMap":   true,
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// validateTags checks the tags of the given field and reports any problems with the field's path.
+// Keys that are not processed by the filler are rejected unless included in allowed.
+func validateTags(path string, field reflect.StructField, allowed map[string]bool) error {
+	pairs, err := parseTag(string(field.Tag))
+	if err != nil {
+		return fmt.Errorf("field %s has malformed tag: %w", path, err)
+	}
+
+	var errs []error
+	for _, pair := range pairs {
+		key, value := pair[0], pair[1]
+		if !knownTags[key] {
+			if !allowed[key] {
+				errs = append(errs, fmt.Errorf("field %s has unknown tag %q", path, key))
+			}
+			continue
+		}
+
+		switch key {
+		case "override-value", "sensitive", "trim", "keep-empty", "advanced", "required":
+			if _, err := strconv.ParseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("field %s has invalid %s tag %q: expected true or false",
+					path, key, value))
+			}
+		case "max-occurs":
+			if count, err := strconv.Atoi(value); err != nil || count < 1 {
+				errs = append(errs, fmt.Errorf("field %s has invalid max-occurs tag %q: expected a positive number",
+					path, value))
+			}
+		case "complete":
+			if _, exists := completeMacros[value]; !exists {
+				errs = append(errs, fmt.Errorf("field %s has invalid complete tag %q: expected file or dir",
+					path, value))
+			}
+		case "encoding":
+			if field.Type != byteSliceType {
+				errs = append(errs, fmt.Errorf("field %s has encoding tag, which only applies to []byte, but field is %s",
+					path, field.Type))
+			} else if _, known := binaryEncodings[value]; !known {
+				errs = append(errs, fmt.Errorf("field %s has unknown encoding tag %q: expected base64 or hex",
+					path, value))
+			}
+		case "type":
+			if !knownFieldTypes[value] {
+				errs = append(errs, fmt.Errorf("field %s has unknown type tag %q", path, value))
+			}
+		case "layout":
+			if err := validateLayout(field.Type, value); err != nil {
+				errs = append(errs, fmt.Errorf("field %s has invalid layout tag %q: %w", path, value, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateLayout(t reflect.Type, layout string) error {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != timeType {
+		return fmt.Errorf("only applies to time.Time, but field is %s", t)
+	}
+	if layout == "" {
+		return nil
+	}
+	// a layout without any recognized elements formats back to itself
+	reference := time.Date(2023, time.November, 24, 10, 30, 45, 0, time.UTC)
+	formatted := reference.Format(layout)
+	if formatted == layout {
+		return errors.New("contains no time elements")
+	}
+	if _, err := time.Parse(layout, formatted); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseTag splits a struct tag into its key and value pairs, following the conventions
+// of reflect.StructTag, but reports malformed content rather than ignoring it.
+func parseTag(tag string) ([][2]string, error) {
+	var pairs [][2]string
+	for tag != "" {
+		// skip leading space
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return nil, fmt.Errorf("expected key at %q", tag)
+		}
+		if i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, fmt.Errorf("expected key:\"value\" at %q", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// scan quoted string to find value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, fmt.Errorf("unterminated value for %s", key)
+		}
+		quoted := tag[:i+1]
+		tag = tag[i+1:]
+
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+	return pairs, nil
+}
@@ -0,0 +1,132 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStrictTags(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		type Config struct {
+			Host    string        `default:"localhost" usage:"the host" json:"host"`
+			Timeout time.Duration `default:"5s" env:"TIMEOUT" aliases:"t"`
+			Tags    []string      `override-value:"true"`
+			When    time.Time     `layout:"2006-01-02"`
+			Secret  string        `sensitive:"true"`
+			Ignored string        `flag:""`
+		}
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithStrictTags("json"))
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		type Config struct {
+			Remote struct {
+				Timeout time.Duration `defult:"5s"`
+			}
+		}
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithStrictTags())
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `field Remote.Timeout has unknown tag "defult"`)
+	})
+
+	t.Run("foreign key not allowed", func(t *testing.T) {
+		type Config struct {
+			Host string `json:"host"`
+		}
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithStrictTags())
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		// built dynamically since go vet rejects malformed tags in source
+		configType := reflect.StructOf([]reflect.StructField{
+			{Name: "Timeout", Type: reflect.TypeOf(time.Duration(0)), Tag: `default:5s`},
+		})
+
+		config := reflect.New(configType).Interface()
+		filler := flagsfiller.New(flagsfiller.WithStrictTags())
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field Timeout has malformed tag")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		type Config struct {
+			Tags []string `override-value:"yes please" type:"strings"`
+		}
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithStrictTags())
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field Tags has invalid override-value tag")
+		assert.Contains(t, err.Error(), `field Tags has unknown type tag "strings"`)
+	})
+
+	t.Run("bad layout", func(t *testing.T) {
+		type Config struct {
+			When time.Time `layout:"YYYY-MM-DD"`
+		}
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithStrictTags())
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field When has invalid layout tag")
+
+		type NotTime struct {
+			Count int `layout:"2006"`
+		}
+		var notTime NotTime
+		err = filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &notTime)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only applies to time.Time")
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		type Config struct {
+			Key []byte `encoding:"base32"`
+		}
+
+		var config Config
+		filler := flagsfiller.New(flagsfiller.WithStrictTags())
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `field Key has unknown encoding tag "base32"`)
+
+		type NotBytes struct {
+			Key string `encoding:"hex"`
+		}
+		var notBytes NotBytes
+		err = filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &notBytes)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only applies to []byte")
+	})
+
+	t.Run("not strict by default", func(t *testing.T) {
+		type Config struct {
+			Timeout time.Duration `defult:"5s"`
+		}
+
+		var config Config
+		filler := flagsfiller.New()
+		err := filler.Fill(flag.NewFlagSet("test", flag.ContinueOnError), &config)
+		require.NoError(t, err)
+	})
+}
@@ -2,6 +2,7 @@ package flagsfiller
 
 import (
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -15,9 +16,43 @@ func init() {
 var DefaultTimeLayout = "2006-01-02 15:04:05"
 
 func timeConverter(s string, tag reflect.StructTag) (time.Time, error) {
+	if t, ok, err := relativeTime(s); ok {
+		return t, err
+	}
 	layout, _ := tag.Lookup("layout")
 	if layout == "" {
 		layout = DefaultTimeLayout
 	}
 	return time.Parse(layout, s)
 }
+
+// relativeTime evaluates the keywords "now" and "startofday", optionally followed by a
+// signed duration such as "now-24h", at the time it is called. The returned bool reports if
+// the value started with a keyword.
+func relativeTime(s string) (time.Time, bool, error) {
+	var base time.Time
+	var offset string
+	switch {
+	case strings.HasPrefix(s, "now"):
+		base = time.Now()
+		offset = s[len("now"):]
+	case strings.HasPrefix(s, "startofday"):
+		now := time.Now()
+		base = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		offset = s[len("startofday"):]
+	default:
+		return time.Time{}, false, nil
+	}
+
+	if offset == "" {
+		return base, true, nil
+	}
+	if offset[0] != '+' && offset[0] != '-' {
+		return time.Time{}, false, nil
+	}
+	d, err := time.ParseDuration(offset)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	return base.Add(d), true, nil
+}
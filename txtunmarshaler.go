@@ -6,25 +6,29 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
-	"strings"
 )
 
-// RegisterTextUnmarshaler use is optional, since flagsfiller will automatically register the types implement encoding.TextUnmarshaler it encounters
+// RegisterTextUnmarshaler use is optional, since flagsfiller will automatically handle the types implement encoding.TextUnmarshaler it encounters
 func RegisterTextUnmarshaler(in any) {
-	base := textUnmarshalerType{}
-	extendedTypes[getTypeName(reflect.TypeOf(in).Elem())] = base.process
+	registerHandler(getTypeName(reflect.TypeOf(in).Elem()), processTextUnmarshaler)
 }
 
 type textUnmarshalerType struct {
 	val encoding.TextUnmarshaler
 }
 
-// String implements flag.Value interface
+// String implements flag.Value interface, where the value is rendered by MarshalText, if
+// implemented, since that is the form parsed by UnmarshalText, or else like formatValue
 func (tv *textUnmarshalerType) String() string {
 	if tv.val == nil {
-		return fmt.Sprint(nil)
+		return ""
 	}
-	return fmt.Sprint(tv.val)
+	if marshaler, ok := tv.val.(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return formatValue(reflect.ValueOf(tv.val))
 }
 
 // Set implements flag.Value interface
@@ -32,15 +36,17 @@ func (tv *textUnmarshalerType) Set(s string) error {
 	return tv.val.UnmarshalText([]byte(s))
 }
 
-func (tv *textUnmarshalerType) process(tag reflect.StructTag, fieldRef interface{},
+// processTextUnmarshaler declares a flag with a new value instance for each field, so any number
+// of fields and flag sets can use the same type
+func processTextUnmarshaler(tag reflect.StructTag, fieldRef interface{},
 	hasDefaultTag bool, tagDefault string,
 	flagSet *flag.FlagSet, renamed string,
-	usage string, aliases string) error {
+	usage string) error {
 	v, ok := fieldRef.(encoding.TextUnmarshaler)
 	if !ok {
 		return fmt.Errorf("can't cast %v into encoding.TextUnmarshaler", fieldRef)
 	}
-	newval := textUnmarshalerType{
+	newval := &textUnmarshalerType{
 		val: v,
 	}
 	if hasDefaultTag {
@@ -49,12 +55,7 @@ func (tv *textUnmarshalerType) process(tag reflect.StructTag, fieldRef interface
 			return fmt.Errorf("failed to parse default value into %v: %w", reflect.TypeOf(fieldRef), err)
 		}
 	}
-	flagSet.Var(&newval, renamed, usage)
-	if aliases != "" {
-		for _, alias := range strings.Split(aliases, ",") {
-			flagSet.Var(&newval, alias, usage)
-		}
-	}
+	flagSet.Var(newval, renamed, usage)
 	return nil
 
 }
@@ -0,0 +1,69 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+func init() {
+	registerHandler(getTypeName(reflect.TypeOf(url.URL{})), processURL)
+}
+
+// urlVar is the flag.Value of url.URL and *url.URL fields
+type urlVar struct {
+	val *url.URL
+	tag reflect.StructTag
+}
+
+func (v *urlVar) String() string {
+	if v.val == nil {
+		return ""
+	}
+	return v.val.String()
+}
+
+func (v *urlVar) Set(s string) error {
+	parsed, err := parseURL(s, v.tag)
+	if err != nil {
+		return err
+	}
+	*v.val = parsed
+	return nil
+}
+
+// parseURL parses s by url.Parse, where the tag "schemes" optionally restricts the accepted
+// schemes to a comma-separated list, such as "http,https"
+func parseURL(s string, tag reflect.StructTag) (url.URL, error) {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return url.URL{}, err
+	}
+	if schemes := tag.Get("schemes"); schemes != "" {
+		allowed := strings.Split(schemes, ",")
+		if !slices.ContainsFunc(allowed, func(scheme string) bool {
+			return strings.EqualFold(strings.TrimSpace(scheme), parsed.Scheme)
+		}) {
+			return url.URL{}, fmt.Errorf("%s does not have one of the schemes %s", s, schemes)
+		}
+	}
+	return *parsed, nil
+}
+
+func processURL(tag reflect.StructTag, fieldRef interface{},
+	hasDefaultTag bool, tagDefault string,
+	flagSet *flag.FlagSet, renamed string,
+	usage string) error {
+	value := &urlVar{val: fieldRef.(*url.URL), tag: tag}
+	if hasDefaultTag {
+		err := value.Set(tagDefault)
+		if err != nil {
+			return fmt.Errorf("failed to parse default into url.URL: %w", err)
+		}
+	}
+	flagSet.Var(value, renamed, usage)
+	return nil
+}
@@ -0,0 +1,75 @@
+package flagsfiller
+
+import (
+	"flag"
+	"fmt"
+	"text/template"
+)
+
+// FlagInfo describes a flag declared by a field for rendering the usage, such as with the
+// WithUsageTemplate option
+type FlagInfo struct {
+	// Name is the name of the flag, such as "remote-host"
+	Name string
+	// Aliases are the other names of the flag, where deprecated aliases are omitted
+	Aliases []string
+	// Env are the names of the environment variables of the field
+	Env []string
+	// Default is the default value, which is empty for zero values and sensitive fields
+	Default string
+	// Usage is the usage declared by the field
+	Usage string
+	// Type is the name of the flag's argument, such as "duration" or a placeholder declared by
+	// the usage, which is empty for bool flags
+	Type string
+	// Group is the heading of the nested struct declaring the field, such as "Remote Auth", which
+	// is empty for top-level fields
+	Group string
+	// Advanced is true for flags declared with the advanced tag
+	Advanced bool
+}
+
+// UsageData is given to the template of the WithUsageTemplate option
+type UsageData struct {
+	// Name is the name of the flag set, which is typically the program name
+	Name string
+	// Flags describe the flags declared by the fields, in declaration order
+	Flags []FlagInfo
+}
+
+// FlagInfos describes the flags declared in flagSet by the fields of this FlagSetFiller, in
+// declaration order
+func (f *FlagSetFiller) FlagInfos(flagSet *flag.FlagSet) []FlagInfo {
+	aliases := f.flagAliases(flagSet)
+	result := make([]FlagInfo, 0, len(f.paths))
+	for _, field := range f.declaredFields(flagSet) {
+		typeName, _ := flag.UnquoteUsage(field.flag)
+		info := FlagInfo{
+			Name:     field.flag.Name,
+			Aliases:  aliases[field.Path],
+			Env:      field.envNames,
+			Usage:    field.usage,
+			Type:     typeName,
+			Group:    usageSection(field.Path),
+			Advanced: f.advancedFlags[field.flag.Name],
+		}
+		if !isZeroDefault(field.flag) && !field.sensitive {
+			info.Default = field.flag.DefValue
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// templateUsage returns a usage function of flagSet that executes the given template
+func (f *FlagSetFiller) templateUsage(flagSet *flag.FlagSet, tmpl *template.Template) func() {
+	return func() {
+		err := tmpl.Execute(flagSet.Output(), UsageData{
+			Name:  flagSet.Name(),
+			Flags: f.FlagInfos(flagSet),
+		})
+		if err != nil {
+			_, _ = fmt.Fprintf(flagSet.Output(), "failed to render usage: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package flagsfiller_test
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUsageTemplate(t *testing.T) {
+	type Config struct {
+		Verbose bool `aliases:"v" usage:"enables verbose output"`
+		Remote  struct {
+			Host    string        `default:"localhost" usage:"the [address] to access"`
+			Timeout time.Duration `default:"5s"`
+			Token   string        `default:"secret" sensitive:"true"`
+		}
+		Debug bool `advanced:"true"`
+	}
+
+	tmpl := template.Must(template.New("usage").Parse(`Usage: {{.Name}} [flags]
+{{range .Flags}}{{if not .Advanced}}  --{{.Name}}{{range .Aliases}}, -{{.}}{{end}}{{if .Type}} {{.Type}}{{end}}{{if .Group}} [{{.Group}}]{{end}}
+    {{.Usage}}{{if .Default}} (default {{.Default}}){{end}}{{range .Env}} ${{.}}{{end}}
+{{end}}{{end}}
+See https://example.com for more.
+`))
+
+	var config Config
+	var output bytes.Buffer
+	_, err := flagsfiller.ParseArgs(&config, []string{"--help"},
+		flagsfiller.WithEnv("App"), flagsfiller.WithUsageTemplate(tmpl), flagsfiller.WithOutput(&output))
+	require.ErrorIs(t, err, flag.ErrHelp)
+
+	assert.Contains(t, output.String(), `  --verbose, -v
+    enables verbose output $APP_VERBOSE
+  --remote-host address [Remote]
+    the address to access (default localhost) $APP_REMOTE_HOST
+  --remote-timeout duration [Remote]
+     (default 5s) $APP_REMOTE_TIMEOUT
+  --remote-token string [Remote]
+     $APP_REMOTE_TOKEN
+
+See https://example.com for more.
+`)
+	assert.NotContains(t, output.String(), "--debug")
+}
+
+func TestFlagInfos(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080" usage:"the port" aliases:"p" env:"PORT"`
+		Auth struct {
+			User string
+		}
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+	flagset := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := filler.Fill(flagset, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, []flagsfiller.FlagInfo{
+		{Name: "port", Aliases: []string{"p"}, Env: []string{"PORT"}, Default: "8080", Usage: "the port", Type: "int"},
+		{Name: "auth-user", Type: "string", Group: "Auth"},
+	}, filler.FlagInfos(flagset))
+}
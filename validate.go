@@ -0,0 +1,124 @@
+package flagsfiller
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// requirement is declared by the requires tag of the field at path, where requires holds the
+// names or paths of the fields that must also be set
+type requirement struct {
+	path     string
+	requires []string
+	// resolved are the paths of the required fields
+	resolved []string
+}
+
+// resolveFieldPath resolves the name of a field referenced by a tag of the field at path, which is
+// either the name of a sibling field or a full field path
+func (f *FlagSetFiller) resolveFieldPath(path string, name string) (string, bool) {
+	if dot := strings.LastIndex(path, "."); dot >= 0 {
+		if _, exists := f.fields[path[:dot+1]+name]; exists {
+			return path[:dot+1] + name, true
+		}
+	}
+	_, exists := f.fields[name]
+	return name, exists
+}
+
+// resolveRequirements resolves the fields named by requires tags, which are either the names of
+// sibling fields or full field paths, and reports the ones that did not declare a flag
+func (f *FlagSetFiller) resolveRequirements() error {
+	var errs []error
+	for i := range f.requirements {
+		req := &f.requirements[i]
+		req.resolved = req.resolved[:0]
+		for _, name := range req.requires {
+			resolved, exists := f.resolveFieldPath(req.path, name)
+			if !exists {
+				errs = append(errs, fmt.Errorf("field %s requires unknown field %s", req.path, name))
+				continue
+			}
+			req.resolved = append(req.resolved, resolved)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setPaths returns the paths of the fields that were set by command-line arguments, environment
+// variables, config files, or SetFromMap
+func (f *FlagSetFiller) setPaths(flagSet *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool, len(f.fieldSources))
+	for path, source := range f.Sources(flagSet) {
+		if source.explicit() {
+			set[path] = true
+		}
+	}
+	return set
+}
+
+// Validate checks the constraints declared by the fields' tags against the flags that were set,
+// which is called by ParseWithSources after parsing. A field declared with `required:"true"` must
+// be set by an argument, environment variable, or config file. A field declared with
+// `requires:"TlsKey"` can only be set when its sibling field TlsKey is also set, where full field
+// paths, such as "Remote.Auth.Password", and comma-separated lists are also accepted. Likewise, a
+// bool field declared with `enables:"Metrics"` gates the fields of its sibling nested struct
+// Metrics, which can only be set when the bool field is true. The validators of the fields that
+// were not set are also run, since a zero value is only checked when set, such that
+// `validate:"nonempty"` rejects a field that was never given. All the violations are reported in
+// the returned error.
+func (f *FlagSetFiller) Validate(flagSet *flag.FlagSet) error {
+	set := f.setPaths(flagSet)
+
+	var errs []error
+	var missing []string
+	for _, path := range f.requiredPaths {
+		if !set[path] {
+			missing = append(missing, "-"+f.fieldFlags[path])
+		}
+	}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("required flags are not set: %s", strings.Join(missing, ", ")))
+	}
+	for _, req := range f.requirements {
+		if !set[req.path] {
+			continue
+		}
+		for _, required := range req.resolved {
+			if !set[required] {
+				errs = append(errs, fmt.Errorf("flag -%s requires -%s to also be set",
+					f.fieldFlags[req.path], f.fieldFlags[required]))
+			}
+		}
+	}
+	errs = append(errs, f.validateGates(set)...)
+	errs = append(errs, f.validateUnset(set)...)
+	return errors.Join(errs...)
+}
+
+// validateUnset runs the validators of the fields declared with the validate tag that were not set
+// by any source, whose values were only checked when declared by a default
+func (f *FlagSetFiller) validateUnset(set map[string]bool) []error {
+	var errs []error
+	for _, path := range f.validatedPaths {
+		field, exists := f.fields[path]
+		if set[path] || !exists {
+			continue
+		}
+		check, err := validatorsCheck(path, field.StructField)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		value := reflect.ValueOf(field.Value).Elem()
+		err = check(value)
+		if err != nil {
+			flagName := f.fieldFlags[path]
+			errs = append(errs, fmt.Errorf("flag -%s: %w", flagName, f.redactError(flagName, err, formatValue(value))))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,126 @@
+package flagsfiller_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/itzg/go-flagsfiller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequires(t *testing.T) {
+	type Config struct {
+		Tls struct {
+			Cert string `requires:"Key"`
+			Key  string
+		}
+		User     string `requires:"Password,Tls.Cert"`
+		Password string
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr []string
+	}{
+		{name: "none", args: nil},
+		{name: "both", args: []string{"--tls-cert", "c", "--tls-key", "k"}},
+		{name: "dependency only", args: []string{"--tls-key", "k"}},
+		{name: "missing", args: []string{"--tls-cert", "c"},
+			wantErr: []string{"flag -tls-cert requires -tls-key to also be set"}},
+		{name: "missing several", args: []string{"--user", "u"},
+			wantErr: []string{
+				"flag -user requires -password to also be set",
+				"flag -user requires -tls-cert to also be set",
+			}},
+		{name: "from env", args: []string{"--user", "u", "--tls-cert", "c", "--tls-key", "k"},
+			env: map[string]string{"REQ_PASSWORD": "p"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var config Config
+			_, err := flagsfiller.ParseArgs(&config, tt.args, flagsfiller.WithEnv("Req"),
+				flagsfiller.WithOutput(io.Discard))
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestRequiresSetFromMap(t *testing.T) {
+	type Config struct {
+		User     string `requires:"Password"`
+		Password string
+	}
+
+	var config Config
+	filler := flagsfiller.New()
+	var flagset flag.FlagSet
+	err := filler.Fill(&flagset, &config)
+	require.NoError(t, err)
+
+	err = filler.SetFromMap(&flagset, map[string]string{"user": "u"})
+	require.NoError(t, err)
+	assert.EqualError(t, filler.Validate(&flagset), "flag -user requires -password to also be set")
+
+	err = filler.SetFromMap(&flagset, map[string]string{"password": "p"})
+	require.NoError(t, err)
+	assert.NoError(t, filler.Validate(&flagset))
+}
+
+func TestRequiresUnknownField(t *testing.T) {
+	type Config struct {
+		User string `requires:"Pasword"`
+	}
+
+	var config Config
+	var flagset flag.FlagSet
+	err := flagsfiller.New().Fill(&flagset, &config)
+	assert.EqualError(t, err, "field User requires unknown field Pasword")
+}
+
+func TestRequired(t *testing.T) {
+	type Config struct {
+		Host   string `required:"true" usage:"the host to access"`
+		Port   int    `required:"true"`
+		Remote struct {
+			Token string `required:"true"`
+		}
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--port", "80"}, flagsfiller.WithOutput(io.Discard))
+		assert.EqualError(t, err, "required flags are not set: -host, -remote-token")
+	})
+
+	t.Run("set by args and env", func(t *testing.T) {
+		t.Setenv("REQ_REMOTE_TOKEN", "secret")
+		var config Config
+		_, err := flagsfiller.ParseArgs(&config, []string{"--host", "example.com", "--port", "80"},
+			flagsfiller.WithEnv("Req"))
+		require.NoError(t, err)
+		assert.Equal(t, "secret", config.Remote.Token)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		var config Config
+		var flagset flag.FlagSet
+		err := flagsfiller.New().Fill(&flagset, &config)
+		require.NoError(t, err)
+		assert.Equal(t, "the host to access (required)", flagset.Lookup("host").Usage)
+	})
+}
@@ -0,0 +1,79 @@
+package flagsfiller
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// validatorFunc checks the value of a field declared with the validate tag, where the value is
+// the field's typed value, such as a string or time.Duration
+type validatorFunc func(value any, field reflect.StructField) error
+
+// validators are registered by RegisterValidator, keyed by name
+var validators sync.Map
+
+func init() {
+	RegisterValidator("nonempty", validateNonEmpty)
+	RegisterValidator("hostport", validateHostPort)
+}
+
+// RegisterValidator registers a validator by name, which can then be attached to fields by
+// including the name in the comma-separated list of the validate tag, such as
+// `validate:"hostport,nonempty"`. The validators are run in order whenever the field's value is
+// set from arguments, environment variables, or defaults, and the first error rejects the value.
+// Validate also runs them for the fields that were not set.
+// The built-in validators are "nonempty", which rejects zero values such as an empty string, and
+// "hostport", which requires a string of the form host:port.
+// Like RegisterSimpleType, it should be called in init().
+func RegisterValidator(name string, fn func(value any, field reflect.StructField) error) {
+	validators.Store(name, validatorFunc(fn))
+}
+
+// validatorsCheck returns a check of the validators named by the validate tag of the field, or
+// nil when not declared
+func validatorsCheck(path string, structField reflect.StructField) (func(field reflect.Value) error, error) {
+	names := structField.Tag.Get("validate")
+	if names == "" {
+		return nil, nil
+	}
+	var fns []validatorFunc
+	for _, name := range strings.Split(names, ",") {
+		fn, exists := validators.Load(strings.TrimSpace(name))
+		if !exists {
+			return nil, fmt.Errorf("field %s has unknown validator %q", path, name)
+		}
+		fns = append(fns, fn.(validatorFunc))
+	}
+	return func(field reflect.Value) error {
+		for _, fn := range fns {
+			err := fn(field.Interface(), structField)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func validateNonEmpty(value any, _ reflect.StructField) error {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+func validateHostPort(value any, _ reflect.StructField) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("hostport only applies to strings, but was %T", value)
+	}
+	if _, port, err := net.SplitHostPort(s); err != nil || port == "" {
+		return fmt.Errorf("must be a host:port, but was %q", s)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package flagsfiller
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces the values of fields declared with the sensitive tag
+const redacted = "*****"
+
+// leafValue is a field value located by visitLeaves
+type leafValue struct {
+	path      string
+	field     reflect.StructField
+	value     reflect.Value
+	sensitive bool
+}
+
+// visitLeaves walks the exported fields of the given struct value, descending into nested structs
+// the same way Fill does, and calls visit for each field that would be mapped to a flag
+func visitLeaves(structVal reflect.Value, pathPrefix string, sensitive bool, visit func(leaf leafValue)) {
+	if pathPrefix != "" {
+		pathPrefix += "."
+	}
+	structType := structVal.Type()
+	for i := 0; i < structVal.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if flagTag, ok := field.Tag.Lookup("flag"); ok && flagTag == "" {
+			continue
+		}
+		if _, isCommand := field.Tag.Lookup("command"); isCommand {
+			continue
+		}
+		path := pathPrefix + field.Name
+		fieldSensitive := sensitive || isSensitive(field.Tag)
+		fieldValue := structVal.Field(i)
+
+		nested := fieldValue
+		if nested.Kind() == reflect.Ptr || nested.Kind() == reflect.Interface {
+			if nested.IsNil() {
+				continue
+			}
+			if nested.Kind() == reflect.Interface {
+				nested = nested.Elem()
+			}
+			if nested.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+			}
+		}
+		if nested.Kind() == reflect.Struct && !isLeafStruct(nested.Type()) {
+			visitLeaves(nested, path, fieldSensitive, visit)
+			continue
+		}
+
+		visit(leafValue{
+			path:      path,
+			field:     field,
+			value:     fieldValue,
+			sensitive: fieldSensitive,
+		})
+	}
+}
+
+// isLeafStruct determines if a struct type is handled as a single value, such as time.Time,
+// rather than being walked into
+func isLeafStruct(t reflect.Type) bool {
+	return handlerFor(reflect.PointerTo(t)) != nil
+}
+
+func isSensitive(tag reflect.StructTag) bool {
+	sensitive, _ := strconv.ParseBool(tag.Get("sensitive"))
+	return sensitive
+}
+
+// formatValue renders a field's value in the same form that is accepted by its flag
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+	}
+	if v.CanInterface() {
+		switch value := v.Interface().(type) {
+		case fmt.Stringer:
+			return value.String()
+		case encoding.TextMarshaler:
+			text, err := value.MarshalText()
+			if err == nil {
+				return string(text)
+			}
+		}
+	}
+	if v.Kind() == reflect.Ptr {
+		return formatValue(v.Elem())
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, formatValue(v.Index(i)))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			parts = append(parts, formatValue(iter.Key())+"="+formatValue(iter.Value()))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,116 @@
+package flagsfiller
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// valueTree holds the nested key-values decoded from a structured file, such as YAML
+type valueTree map[string]interface{}
+
+func loadValueTree(fsys fs.FS, path string) (valueTree, error) {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValueTree(content)
+}
+
+// decodeValueTree decodes YAML, or JSON, content
+func decodeValueTree(content []byte) (valueTree, error) {
+	// decode into a plain map so that nested mappings are also plain maps
+	var tree map[string]interface{}
+	err := yaml.Unmarshal(content, &tree)
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// decodeTOMLValueTree decodes TOML content, where tables become nested mappings
+func decodeTOMLValueTree(content []byte) (valueTree, error) {
+	var tree map[string]interface{}
+	err := toml.Unmarshal(content, &tree)
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// lookup locates the value at the given dot-separated field path and renders it in the same
+// form as a default tag
+func (t valueTree) lookup(fieldPath string) (string, bool) {
+	return t.lookupSegments(strings.Split(fieldPath, "."))
+}
+
+// lookupSegments locates the value under the given nested keys and renders it in the same form
+// as a default tag
+func (t valueTree) lookupSegments(segments []string) (string, bool) {
+	value, exists := t.locate(segments)
+	if !exists {
+		return "", false
+	}
+	return renderTreeValue(value), true
+}
+
+// locate returns the decoded value under the given nested keys
+func (t valueTree) locate(segments []string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(t)
+	for _, segment := range segments {
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		current = nil
+		for key, value := range node {
+			if normalizeKey(key) == normalizeKey(segment) {
+				current = value
+				break
+			}
+		}
+		if current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// renderTreeValue renders a decoded value in the same form as a default tag, where the entries of
+// mappings and the elements of lists are joined by commas
+func renderTreeValue(current interface{}) string {
+	switch value := current.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		entries := make([]string, 0, len(keys))
+		for _, k := range keys {
+			entries = append(entries, fmt.Sprintf("%s=%v", k, value[k]))
+		}
+		return strings.Join(entries, ",")
+	case []interface{}:
+		entries := make([]string, 0, len(value))
+		for _, v := range value {
+			entries = append(entries, renderTreeValue(v))
+		}
+		return strings.Join(entries, ",")
+	case time.Time:
+		// timestamps are decoded from YAML and TOML, but the field expects the default layout
+		return value.Format(DefaultTimeLayout)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// normalizeKey allows keys to match field names regardless of case, dashes, and underscores
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(key))
+}